@@ -2,11 +2,14 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,13 +19,28 @@ import (
 
 	"workflow-code-test/api/pkg/cache"
 	"workflow-code-test/api/pkg/db"
+	"workflow-code-test/api/pkg/redact"
+	"workflow-code-test/api/pkg/secrets"
 	"workflow-code-test/api/services/workflow"
 )
 
 // Config holds all configuration for the application
 type Config struct {
 	DatabaseURL     string
+	DatabaseReadURL string
 	RedisURL        string
+	SecretPrefix    string
+	TemplateEnv     []string
+	RedactKeys      []string
+	RedactInputPII  bool
+	WorkflowLimits  workflow.WorkflowLimits
+	MaxCacheEntry   int
+	CacheAfterReads int
+	MaxExecutions   int
+	MaxRequestTime  time.Duration
+	DuplicateOutput workflow.DuplicateOutputPolicy
+	ForwardHeaders  []string
+	SignatureHeader string
 	ServerPort      string
 	FrontendURL     string
 	LogLevel        slog.Level
@@ -34,7 +52,9 @@ type App struct {
 	Config          *Config
 	Logger          *slog.Logger
 	DBPool          *pgxpool.Pool
+	ReadDBPool      *pgxpool.Pool
 	Cache           cache.Cache
+	Executions      *workflow.ExecutionMetrics
 	Router          *mux.Router
 	Server          *http.Server
 	WorkflowService *workflow.Service
@@ -47,9 +67,100 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("DATABASE_URL is not set")
 	}
 
+	// Read replica URL is optional - workflow reads use the primary if not set
+	dbReadURL := os.Getenv("DATABASE_READ_URL")
+
 	// Redis URL is optional - cache will be disabled if not set
 	redisURL := os.Getenv("REDIS_URL")
 
+	// Secrets referenced as {{secret.NAME}} are read from env vars named <prefix>NAME
+	secretPrefix, ok := os.LookupEnv("SECRET_ENV_PREFIX")
+	if !ok {
+		secretPrefix = "WORKFLOW_SECRET_"
+	}
+
+	// Env vars templates may reference as {{env.NAME}}; none unless configured
+	var templateEnv []string
+	if names := os.Getenv("TEMPLATE_ENV_ALLOWLIST"); names != "" {
+		templateEnv = strings.Split(names, ",")
+	}
+
+	// Key-name patterns redacted from logs and step output
+	redactKeys := redact.DefaultPatterns
+	if keys := os.Getenv("REDACT_KEYS"); keys != "" {
+		redactKeys = strings.Split(keys, ",")
+	}
+
+	// Whether personal details are also masked in the stored input returned with an execution
+	redactInputPII := false
+	if value := os.Getenv("REDACT_INPUT_PII"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("REDACT_INPUT_PII must be true or false")
+		}
+		redactInputPII = parsed
+	}
+
+	// Workflow size and traversal breadth limits
+	maxNodes, err := intFromEnv("MAX_WORKFLOW_NODES", workflow.DefaultMaxWorkflowNodes)
+	if err != nil {
+		return nil, err
+	}
+	maxEdges, err := intFromEnv("MAX_WORKFLOW_EDGES", workflow.DefaultMaxWorkflowEdges)
+	if err != nil {
+		return nil, err
+	}
+	maxQueued, err := intFromEnv("MAX_TRAVERSAL_QUEUE", workflow.DefaultMaxQueuedNodes)
+	if err != nil {
+		return nil, err
+	}
+	maxEnqueued, err := intFromEnv("MAX_TRAVERSAL_ENQUEUED", workflow.DefaultMaxEnqueuedNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Workflows encoding larger than this are not cached
+	maxCacheEntry, err := intFromEnv("MAX_CACHE_ENTRY_BYTES", workflow.DefaultMaxCacheEntryBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Database reads within the cache TTL before a workflow is cached; cached on first read unless configured
+	cacheAfterReads, err := intFromEnv("CACHE_AFTER_READS", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concurrent executions allowed per workflow; unlimited unless configured
+	maxExecutions, err := intFromEnv("MAX_CONCURRENT_EXECUTIONS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Longest X-Request-Timeout a client may set on an execution
+	maxRequestTimeoutMs, err := intFromEnv("MAX_REQUEST_TIMEOUT_MS", int(workflow.DefaultMaxRequestTimeout/time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+
+	// What happens when two nodes write the same output variable; overwrite unless configured
+	duplicateOutput, err := workflow.ParseDuplicateOutputPolicy(os.Getenv("DUPLICATE_OUTPUT_POLICY"))
+	if err != nil {
+		return nil, fmt.Errorf("DUPLICATE_OUTPUT_POLICY: %w", err)
+	}
+
+	// Request headers copied into executeVars; none unless configured
+	var forwardHeaders []string
+	if headers := os.Getenv("FORWARD_HEADERS"); headers != "" {
+		forwardHeaders = strings.Split(headers, ",")
+	}
+
+	// Header carrying the HMAC signature of webhook-triggered executions
+	signatureHeader := os.Getenv("WEBHOOK_SIGNATURE_HEADER")
+	if signatureHeader == "" {
+		signatureHeader = workflow.DefaultWebhookSignatureHeader
+	}
+
 	// Set defaults that can be overridden by env vars
 	serverPort := os.Getenv("SERVER_PORT")
 	if serverPort == "" {
@@ -77,7 +188,25 @@ func NewConfig() (*Config, error) {
 
 	return &Config{
 		DatabaseURL:     dbURL,
+		DatabaseReadURL: dbReadURL,
 		RedisURL:        redisURL,
+		SecretPrefix:    secretPrefix,
+		TemplateEnv:     templateEnv,
+		RedactKeys:      redactKeys,
+		RedactInputPII:  redactInputPII,
+		WorkflowLimits: workflow.WorkflowLimits{
+			MaxNodes:    maxNodes,
+			MaxEdges:    maxEdges,
+			MaxQueued:   maxQueued,
+			MaxEnqueued: maxEnqueued,
+		},
+		MaxCacheEntry:   maxCacheEntry,
+		CacheAfterReads: cacheAfterReads,
+		MaxExecutions:   maxExecutions,
+		MaxRequestTime:  time.Duration(maxRequestTimeoutMs) * time.Millisecond,
+		DuplicateOutput: duplicateOutput,
+		ForwardHeaders:  forwardHeaders,
+		SignatureHeader: signatureHeader,
 		ServerPort:      serverPort,
 		FrontendURL:     frontendURL,
 		LogLevel:        logLevel,
@@ -85,12 +214,27 @@ func NewConfig() (*Config, error) {
 	}, nil
 }
 
-// SetupLogger configures the application logger
-func SetupLogger(level slog.Level) *slog.Logger {
+// intFromEnv reads a positive integer env var, returning def when it is unset
+func intFromEnv(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return parsed, nil
+}
+
+// SetupLogger configures the application logger, masking sensitive values in every log line
+// and keeping the lines written during each stored execution
+func SetupLogger(level slog.Level, redactor *redact.Redactor) *slog.Logger {
 	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: level,
 	})
-	logger := slog.New(logHandler)
+	// Execution-scoped records are collected inside the redactor so stored logs are masked too
+	logger := slog.New(redact.NewHandler(workflow.NewExecutionLogHandler(logHandler), redactor))
 	slog.SetDefault(logger)
 	return logger
 }
@@ -111,12 +255,12 @@ func SetupRouter() *mux.Router {
 }
 
 // SetupServices initializes all application services
-func SetupServices(pool *pgxpool.Pool, cacheClient cache.Cache, router *mux.Router) (*workflow.Service, error) {
+func SetupServices(pool *pgxpool.Pool, cacheClient cache.Cache, router *mux.Router, opts ...workflow.Option) (*workflow.Service, error) {
 	// Setup API subrouter
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
 
 	// Initialize workflow service
-	workflowService, err := workflow.NewService(pool, cacheClient)
+	workflowService, err := workflow.NewService(pool, cacheClient, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workflow service: %w", err)
 	}
@@ -127,6 +271,27 @@ func SetupServices(pool *pgxpool.Pool, cacheClient cache.Cache, router *mux.Rout
 	return workflowService, nil
 }
 
+// SetupMetrics exposes database pool statistics, the active cache backend and execution counts
+// at /api/v1/metrics. readPool may be nil when no read replica is configured, and cacheClient
+// when caching is disabled.
+func SetupMetrics(router *mux.Router, pool *pgxpool.Pool, readPool *pgxpool.Pool, cacheClient cache.Cache, executions *workflow.ExecutionMetrics) {
+	router.HandleFunc("/api/v1/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		metrics := map[string]any{
+			"database":   db.NewPoolStats(pool),
+			"cache":      cache.Describe(cacheClient),
+			"executions": executions.Counts(),
+		}
+		if readPool != nil {
+			metrics["readReplica"] = db.NewPoolStats(readPool)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(metrics); err != nil {
+			slog.Error("Failed to encode metrics", "error", err)
+		}
+	}).Methods("GET")
+}
+
 // SetupServer creates and configures the HTTP server
 func SetupServer(config *Config, router *mux.Router) *http.Server {
 	// Setup CORS
@@ -153,7 +318,8 @@ func Build(ctx context.Context) (*App, error) {
 	}
 
 	// Setup logger
-	logger := SetupLogger(config.LogLevel)
+	redactor := redact.New(config.RedactKeys)
+	logger := SetupLogger(config.LogLevel, redactor)
 	logger.Info("Starting application", "port", config.ServerPort)
 
 	// Setup database
@@ -163,6 +329,18 @@ func Build(ctx context.Context) (*App, error) {
 		return nil, err
 	}
 
+	// Setup read replica (optional)
+	var readPool *pgxpool.Pool
+	if config.DatabaseReadURL != "" {
+		readPool, err = SetupDatabase(ctx, config.DatabaseReadURL)
+		if err != nil {
+			logger.Error("Failed to connect to read replica", "error", err)
+			pool.Close()
+			return nil, err
+		}
+		logger.Info("Read replica connected successfully")
+	}
+
 	// Setup cache (optional)
 	var cacheClient cache.Cache
 	if config.RedisURL == "" {
@@ -179,17 +357,44 @@ func Build(ctx context.Context) (*App, error) {
 	// Setup router
 	router := SetupRouter()
 
+	// Setup secret store (env-backed by default)
+	secretStore := secrets.NewEnvSecretStore(config.SecretPrefix)
+
+	// Count finished executions for the metrics endpoint
+	executions := workflow.NewExecutionMetrics()
+
 	// Setup services
-	workflowService, err := SetupServices(pool, cacheClient, router)
+	workflowService, err := SetupServices(pool, cacheClient, router,
+		workflow.WithSecretStore(secretStore),
+		workflow.WithTemplateEnv(secrets.NewEnvAllowList(config.TemplateEnv)),
+		workflow.WithRedactor(redactor),
+		workflow.WithInputPIIRedaction(config.RedactInputPII),
+		workflow.WithWorkflowLimits(config.WorkflowLimits),
+		workflow.WithMaxCacheEntryBytes(config.MaxCacheEntry),
+		workflow.WithCacheAfterReads(config.CacheAfterReads),
+		workflow.WithMaxConcurrentExecutions(config.MaxExecutions),
+		workflow.WithMaxRequestTimeout(config.MaxRequestTime),
+		workflow.WithDuplicateOutputPolicy(config.DuplicateOutput),
+		workflow.WithForwardedHeaders(config.ForwardHeaders),
+		workflow.WithWebhookSignatureHeader(config.SignatureHeader),
+		workflow.WithReadReplica(readPool),
+		workflow.WithExecutionHooks(executions.Hook),
+	)
 	if err != nil {
 		logger.Error("Failed to setup services", "error", err)
 		pool.Close()
+		if readPool != nil {
+			readPool.Close()
+		}
 		if err := cacheClient.Close(); err != nil {
 			logger.Error("Failed to close cache", "error", err)
 		}
 		return nil, err
 	}
 
+	// Expose pool statistics, the cache backend and execution counts
+	SetupMetrics(router, pool, readPool, cacheClient, executions)
+
 	// Setup server
 	server := SetupServer(config, router)
 
@@ -197,7 +402,9 @@ func Build(ctx context.Context) (*App, error) {
 		Config:          config,
 		Logger:          logger,
 		DBPool:          pool,
+		ReadDBPool:      readPool,
 		Cache:           cacheClient,
+		Executions:      executions,
 		Router:          router,
 		Server:          server,
 		WorkflowService: workflowService,
@@ -251,8 +458,11 @@ func (app *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// Close database connection
+	// Close database connections
 	app.DBPool.Close()
+	if app.ReadDBPool != nil {
+		app.ReadDBPool.Close()
+	}
 
 	app.Logger.Info("Application shutdown complete")
 	return nil
@@ -268,4 +478,7 @@ func (app *App) Close() {
 	if app.DBPool != nil {
 		app.DBPool.Close()
 	}
+	if app.ReadDBPool != nil {
+		app.ReadDBPool.Close()
+	}
 }
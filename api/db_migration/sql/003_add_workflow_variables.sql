@@ -0,0 +1,6 @@
+-- Workflow variables
+-- Version: 1.1.0
+-- Description: Adds per-workflow variable defaults that seed execution variables
+
+ALTER TABLE workflows
+    ADD COLUMN IF NOT EXISTS variables JSONB DEFAULT '{}'; -- {"threshold": 25, "unit": "celsius"}
@@ -0,0 +1,18 @@
+-- Workflow executions
+-- Version: 1.2.0
+-- Description: Persists execution input and results so runs can be inspected and replayed
+
+-- Table: workflow_executions
+-- Stores every workflow execution with the input it ran with
+CREATE TABLE IF NOT EXISTS workflow_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL, -- Overall status: 'completed', 'failed'
+    input JSONB NOT NULL DEFAULT '{}', -- The WorkflowExecutionInput the run received
+    result JSONB NOT NULL DEFAULT '{}', -- The WorkflowExecutionResult returned to the caller
+    replay_of UUID REFERENCES workflow_executions(id) ON DELETE SET NULL, -- Original execution when this run is a replay
+    executed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_workflow_executions_workflow_id ON workflow_executions(workflow_id, executed_at DESC);
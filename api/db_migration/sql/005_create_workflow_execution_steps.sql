@@ -0,0 +1,25 @@
+-- Workflow execution steps
+-- Version: 1.3.0
+-- Description: Stores execution steps as rows so large executions can be filtered and paginated
+
+-- Table: workflow_execution_steps
+-- Stores each step of a persisted execution in execution order
+CREATE TABLE IF NOT EXISTS workflow_execution_steps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    execution_id UUID NOT NULL REFERENCES workflow_executions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL, -- Zero-based order in which the step ran
+    node_id VARCHAR(100) NOT NULL,
+    node_type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL, -- Step status: 'completed', 'failed', 'skipped'
+    label VARCHAR(255),
+    description TEXT,
+    error TEXT,
+    output JSONB DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(execution_id, position)
+);
+
+CREATE INDEX idx_workflow_execution_steps_filters ON workflow_execution_steps(execution_id, status, node_type);
+
+-- Steps now live in workflow_execution_steps, so workflow_executions.result only keeps the summary
+COMMENT ON COLUMN workflow_executions.result IS 'WorkflowExecutionResult summary with an empty steps array; steps are stored in workflow_execution_steps';
@@ -0,0 +1,9 @@
+-- Execution labels
+-- Version: 1.4.0
+-- Description: Adds key/value labels to executions so history can be filtered by them
+
+ALTER TABLE workflow_executions
+    ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'; -- e.g. {"trigger": "cron", "user": "alice"}
+
+-- Containment queries (labels @> '{"trigger":"cron"}') use the GIN index
+CREATE INDEX IF NOT EXISTS idx_workflow_executions_labels ON workflow_executions USING GIN (labels jsonb_path_ops);
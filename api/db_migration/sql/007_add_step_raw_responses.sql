@@ -0,0 +1,6 @@
+-- Raw integration responses
+-- Version: 1.5.0
+-- Description: Stores the raw response body of integration steps that opt in with persistResponse, for audit
+
+ALTER TABLE workflow_execution_steps
+    ADD COLUMN IF NOT EXISTS raw_response TEXT; -- Response body as returned, or redacted when it held sensitive keys; NULL unless persisted
@@ -0,0 +1,17 @@
+-- Workflow execution logs
+-- Version: 1.6.0
+-- Description: Stores the log entries written during an execution, apart from its step results
+
+-- Table: workflow_execution_logs
+-- Stores each log entry of a persisted execution in the order it was written
+CREATE TABLE IF NOT EXISTS workflow_execution_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    execution_id UUID NOT NULL REFERENCES workflow_executions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL, -- Zero-based order in which the entry was written
+    level VARCHAR(10) NOT NULL, -- 'DEBUG', 'INFO', 'WARN' or 'ERROR'
+    node_id VARCHAR(100), -- NULL when the entry does not name a node
+    message TEXT NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}', -- Remaining log attributes, already redacted
+    logged_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(execution_id, position)
+);
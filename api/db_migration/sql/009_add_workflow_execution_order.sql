@@ -0,0 +1,6 @@
+-- Workflow execution order
+-- Version: 1.7.0
+-- Description: Adds an optional explicit node sequence that replaces edge traversal
+
+ALTER TABLE workflows
+    ADD COLUMN IF NOT EXISTS execution_order JSONB; -- ["start", "form", "weather-api", "condition", "email", "end"]
@@ -0,0 +1,6 @@
+-- Workflow cacheability
+-- Version: 1.8.0
+-- Description: Adds an optional flag forcing a workflow's definition in or out of the cache
+
+ALTER TABLE workflows
+    ADD COLUMN IF NOT EXISTS cacheable BOOLEAN; -- NULL leaves the decision to the read-frequency heuristic
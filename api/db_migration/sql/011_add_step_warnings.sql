@@ -0,0 +1,6 @@
+-- Step warnings
+-- Version: 1.9.0
+-- Description: Stores the warnings a step raised without failing, such as a missing input field
+
+ALTER TABLE workflow_execution_steps
+    ADD COLUMN IF NOT EXISTS warnings JSONB; -- ["Expected input field not found in executeVars: field=city"]; NULL when the step raised none
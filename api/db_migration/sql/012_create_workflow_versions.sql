@@ -0,0 +1,13 @@
+-- Workflow versions
+-- Version: 1.10.0
+-- Description: Keeps a snapshot of a workflow's definition after each change so versions can be compared
+
+-- Table: workflow_versions
+-- Stores each version of a workflow as the definition returned by the API
+CREATE TABLE IF NOT EXISTS workflow_versions (
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL, -- 1 is the definition before the first change, each change adds the next
+    definition JSONB NOT NULL, -- The Workflow as returned by GET /workflows/{id}
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, version)
+);
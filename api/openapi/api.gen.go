@@ -27,6 +27,34 @@ const (
 	GreaterThanOrEqual ConditionOperator = "greater_than_or_equal"
 	LessThan           ConditionOperator = "less_than"
 	LessThanOrEqual    ConditionOperator = "less_than_or_equal"
+	NotEquals          ConditionOperator = "not_equals"
+	Truthy             ConditionOperator = "truthy"
+)
+
+// Defines values for ExecuteWorkflowParamsNaming.
+const (
+	ExecuteWorkflowParamsNamingCamelCase ExecuteWorkflowParamsNaming = "camelCase"
+	ExecuteWorkflowParamsNamingSnakeCase ExecuteWorkflowParamsNaming = "snake_case"
+)
+
+// Defines values for ExecuteWorkflowParamsSource.
+const (
+	Inline ExecuteWorkflowParamsSource = "inline"
+	Stored ExecuteWorkflowParamsSource = "stored"
+)
+
+// Defines values for GetExecutionParamsNaming.
+const (
+	GetExecutionParamsNamingCamelCase GetExecutionParamsNaming = "camelCase"
+	GetExecutionParamsNamingSnakeCase GetExecutionParamsNaming = "snake_case"
+)
+
+// Defines values for ExecutionLogEntryLevel.
+const (
+	DEBUG ExecutionLogEntryLevel = "DEBUG"
+	ERROR ExecutionLogEntryLevel = "ERROR"
+	INFO  ExecutionLogEntryLevel = "INFO"
+	WARN  ExecutionLogEntryLevel = "WARN"
 )
 
 // Defines values for ExecutionStepStatus.
@@ -36,8 +64,16 @@ const (
 	ExecutionStepStatusSkipped   ExecutionStepStatus = "skipped"
 )
 
+// Defines values for GetExecutionParamsStatus.
+const (
+	GetExecutionParamsStatusCompleted GetExecutionParamsStatus = "completed"
+	GetExecutionParamsStatusFailed    GetExecutionParamsStatus = "failed"
+	GetExecutionParamsStatusSkipped   GetExecutionParamsStatus = "skipped"
+)
+
 // Defines values for WorkflowExecutionResultStatus.
 const (
+	WorkflowExecutionResultStatusCancelled WorkflowExecutionResultStatus = "cancelled"
 	WorkflowExecutionResultStatusCompleted WorkflowExecutionResultStatus = "completed"
 	WorkflowExecutionResultStatusFailed    WorkflowExecutionResultStatus = "failed"
 	WorkflowExecutionResultStatusPartial   WorkflowExecutionResultStatus = "partial"
@@ -45,32 +81,110 @@ const (
 
 // Defines values for WorkflowNodeType.
 const (
+	WorkflowNodeTypeAggregate   WorkflowNodeType = "aggregate"
+	WorkflowNodeTypeComment     WorkflowNodeType = "comment"
 	WorkflowNodeTypeCondition   WorkflowNodeType = "condition"
 	WorkflowNodeTypeEmail       WorkflowNodeType = "email"
 	WorkflowNodeTypeEnd         WorkflowNodeType = "end"
 	WorkflowNodeTypeForm        WorkflowNodeType = "form"
 	WorkflowNodeTypeIntegration WorkflowNodeType = "integration"
+	WorkflowNodeTypeLoop        WorkflowNodeType = "loop"
+	WorkflowNodeTypeNote        WorkflowNodeType = "note"
+	WorkflowNodeTypeSplit       WorkflowNodeType = "split"
 	WorkflowNodeTypeStart       WorkflowNodeType = "start"
+	WorkflowNodeTypeStop        WorkflowNodeType = "stop"
 )
 
 // Condition Condition parameters for workflow execution
 type Condition struct {
-	// Operator Comparison operator for condition evaluation
+	// Operator Comparison operator for condition evaluation. truthy checks the field itself and ignores the threshold.
 	Operator ConditionOperator `json:"operator"`
 
-	// Threshold Threshold value for comparison
+	// Threshold Threshold value for comparison; ignored by the truthy operator
 	Threshold float32 `json:"threshold"`
 }
 
-// ConditionOperator Comparison operator for condition evaluation
+// ConditionOperator Comparison operator for condition evaluation. truthy checks the field itself and ignores the threshold.
 type ConditionOperator string
 
+// EndpointHealth defines model for EndpointHealth.
+type EndpointHealth struct {
+	// Error Why the endpoint could not be probed or was unreachable
+	Error *string `json:"error,omitempty"`
+
+	// NodeId Integration node using the endpoint
+	NodeId string `json:"nodeId"`
+
+	// Reachable Whether the host answered the probe with any HTTP response
+	Reachable bool `json:"reachable"`
+
+	// StatusCode HTTP status code of the probe response
+	StatusCode *int `json:"statusCode,omitempty"`
+
+	// Url URL that was probed, the scheme and host of the node's apiEndpoint
+	Url string `json:"url"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	// Error Error message
 	Error string `json:"error"`
 }
 
+// ExecutionList defines model for ExecutionList.
+type ExecutionList struct {
+	// Executions Executions on this page, newest first
+	Executions []ExecutionSummary `json:"executions"`
+
+	// Total Total number of executions matching the filters
+	Total int `json:"total"`
+}
+
+// ExecutionLogEntry defines model for ExecutionLogEntry.
+type ExecutionLogEntry struct {
+	// Attributes Remaining structured attributes of the entry, redacted like the service logs
+	Attributes *map[string]interface{} `json:"attributes,omitempty"`
+
+	// Level Log level
+	Level ExecutionLogEntryLevel `json:"level"`
+
+	// Message Log message
+	Message string `json:"message"`
+
+	// NodeId ID of the node the entry was logged for, when it names one
+	NodeId *string `json:"nodeId,omitempty"`
+
+	// Timestamp When the entry was logged
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExecutionLogEntryLevel Log level
+type ExecutionLogEntryLevel string
+
+// ExecutionLogList defines model for ExecutionLogList.
+type ExecutionLogList struct {
+	// ExecutionId Identifier of the persisted execution
+	ExecutionId openapi_types.UUID `json:"executionId"`
+
+	// Logs Log entries in the order they were written
+	Logs []ExecutionLogEntry `json:"logs"`
+}
+
+// ExecutionOptions Run-time overrides for a single execution; they take precedence over node metadata
+type ExecutionOptions struct {
+	// DryRun Skip external side effects such as sending email or calling APIs
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// EndpointOverrides Integration API endpoints to use instead of the stored apiEndpoint, keyed by node ID
+	EndpointOverrides *map[string]string `json:"endpointOverrides,omitempty"`
+
+	// Retries Set to false to attempt every email once, ignoring the nodes' retry settings
+	Retries *bool `json:"retries,omitempty"`
+
+	// TimeoutMs Timeout in milliseconds for each integration API call, overriding the nodes' timeoutMs
+	TimeoutMs *int `json:"timeoutMs,omitempty"`
+}
+
 // ExecutionStep defines model for ExecutionStep.
 type ExecutionStep struct {
 	// Description Description of what was executed
@@ -88,16 +202,49 @@ type ExecutionStep struct {
 	// Output Output data from this step
 	Output *map[string]interface{} `json:"output,omitempty"`
 
+	// RawResponse Raw response body of an integration step whose node sets persistResponse, redacted when it holds sensitive keys
+	RawResponse *string `json:"rawResponse,omitempty"`
+
 	// Status Execution status of this step
 	Status ExecutionStepStatus `json:"status"`
 
 	// Type Type of the node
 	Type string `json:"type"`
+
+	// Warnings Soft problems met while the node ran that did not fail the step, such as an expected input field or output variable that was missing
+	Warnings *[]string `json:"warnings,omitempty"`
 }
 
 // ExecutionStepStatus Execution status of this step
 type ExecutionStepStatus string
 
+// ExecutionSummary defines model for ExecutionSummary.
+type ExecutionSummary struct {
+	// ExecutedAt Timestamp when the workflow was executed
+	ExecutedAt time.Time `json:"executedAt"`
+
+	// ExecutionId Identifier of the persisted execution
+	ExecutionId openapi_types.UUID `json:"executionId"`
+
+	// Labels Labels the execution was run with
+	Labels *map[string]string `json:"labels,omitempty"`
+
+	// ReplayOf Identifier of the original execution when this run is a replay
+	ReplayOf *openapi_types.UUID `json:"replayOf,omitempty"`
+
+	// Status Overall execution status
+	Status string `json:"status"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Dot-path of the input field, e.g. condition.threshold or formData.email
+	Field string `json:"field"`
+
+	// Message What is wrong with the field
+	Message string `json:"message"`
+}
+
 // NodeData defines model for NodeData.
 type NodeData struct {
 	// Description Description of what this node does
@@ -110,6 +257,17 @@ type NodeData struct {
 	Metadata *map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// NodeExecutionInput Input for running a single node in isolation
+type NodeExecutionInput struct {
+	Condition *Condition `json:"condition,omitempty"`
+
+	// DryRun Skip external side effects such as sending email or calling APIs
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// ExecuteVars Variables available to the node, as if set by earlier steps
+	ExecuteVars *map[string]interface{} `json:"executeVars,omitempty"`
+}
+
 // Position defines model for Position.
 type Position struct {
 	// X X coordinate
@@ -119,14 +277,47 @@ type Position struct {
 	Y *float32 `json:"y,omitempty"`
 }
 
+// TraversedEdge defines model for TraversedEdge.
+type TraversedEdge struct {
+	// Id Identifier of the edge
+	Id string `json:"id"`
+
+	// ResolvedLabel Edge label with placeholders replaced by the variables when the edge was taken
+	ResolvedLabel *string `json:"resolvedLabel,omitempty"`
+
+	// Source Node the edge leaves
+	Source string `json:"source"`
+
+	// SourceHandle Source handle the edge leaves from, for conditional nodes
+	SourceHandle *string `json:"sourceHandle,omitempty"`
+
+	// Target Node the edge enters
+	Target string `json:"target"`
+}
+
+// ValidationError defines model for ValidationError.
+type ValidationError struct {
+	// Error Error message summarising every field error
+	Error string `json:"error"`
+
+	// Fields One entry per input field that can't be run as given
+	Fields []FieldError `json:"fields"`
+}
+
 // Workflow defines model for Workflow.
 type Workflow struct {
+	// Cacheable Whether the definition is cached when read. True always caches it and false never does; when unset it is cached once it is read often enough.
+	Cacheable *bool `json:"cacheable,omitempty"`
+
 	// Description Description of the workflow
 	Description *string `json:"description,omitempty"`
 
 	// Edges List of edges connecting the nodes
 	Edges *[]WorkflowEdge `json:"edges,omitempty"`
 
+	// ExecutionOrder Node IDs to run in this exact sequence instead of following edges breadth-first. Must begin with the start node, and each node must have an edge from the one before it.
+	ExecutionOrder *[]string `json:"executionOrder,omitempty"`
+
 	// Id Unique identifier for the workflow
 	Id openapi_types.UUID `json:"id"`
 
@@ -135,6 +326,31 @@ type Workflow struct {
 
 	// Nodes List of nodes in the workflow
 	Nodes *[]WorkflowNode `json:"nodes,omitempty"`
+
+	// Variables Default variables seeded before execution; form input takes precedence
+	Variables *map[string]interface{} `json:"variables,omitempty"`
+}
+
+// WorkflowDefinitionExecutionInput An unsaved workflow definition together with its execution input
+type WorkflowDefinitionExecutionInput struct {
+	// Input Input data for workflow execution
+	Input    *WorkflowExecutionInput `json:"input,omitempty"`
+	Workflow Workflow                `json:"workflow"`
+}
+
+// WorkflowDiff defines model for WorkflowDiff.
+type WorkflowDiff struct {
+	Edges WorkflowEdgeDiff `json:"edges"`
+
+	// From Version compared from
+	From  int              `json:"from"`
+	Nodes WorkflowNodeDiff `json:"nodes"`
+
+	// To Version compared to
+	To int `json:"to"`
+
+	// WorkflowId Identifier of the compared workflow
+	WorkflowId openapi_types.UUID `json:"workflowId"`
 }
 
 // WorkflowEdge defines model for WorkflowEdge.
@@ -154,7 +370,7 @@ type WorkflowEdge struct {
 	// Source Source node ID
 	Source string `json:"source"`
 
-	// SourceHandle Source handle identifier (for conditional nodes)
+	// SourceHandle Source handle identifier (for conditional nodes). A condition's `error` handle is followed when the condition itself fails.
 	SourceHandle *string `json:"sourceHandle,omitempty"`
 
 	// Style CSS style properties for the edge
@@ -167,13 +383,40 @@ type WorkflowEdge struct {
 	Type *string `json:"type,omitempty"`
 }
 
+// WorkflowEdgeChange defines model for WorkflowEdgeChange.
+type WorkflowEdgeChange struct {
+	After  WorkflowEdge `json:"after"`
+	Before WorkflowEdge `json:"before"`
+
+	// Id ID of the changed edge
+	Id string `json:"id"`
+}
+
+// WorkflowEdgeDiff defines model for WorkflowEdgeDiff.
+type WorkflowEdgeDiff struct {
+	// Added Edges only in the to version, ordered by ID
+	Added []WorkflowEdge `json:"added"`
+
+	// Changed Edges in both versions whose definition differs, ordered by ID
+	Changed []WorkflowEdgeChange `json:"changed"`
+
+	// Removed Edges only in the from version, ordered by ID
+	Removed []WorkflowEdge `json:"removed"`
+}
+
 // WorkflowExecutionInput Input data for workflow execution
 type WorkflowExecutionInput struct {
 	// Condition Condition parameters for workflow execution
 	Condition *Condition `json:"condition,omitempty"`
 
+	// ExecutionOptions Run-time overrides for a single execution; they take precedence over node metadata
+	ExecutionOptions *ExecutionOptions `json:"executionOptions,omitempty"`
+
 	// FormData Form data from user input - flexible map to support different workflows
 	FormData *map[string]interface{} `json:"formData,omitempty"`
+
+	// Labels Labels stored with the execution for filtering history
+	Labels *map[string]string `json:"labels,omitempty"`
 }
 
 // WorkflowExecutionResult defines model for WorkflowExecutionResult.
@@ -181,16 +424,64 @@ type WorkflowExecutionResult struct {
 	// ExecutedAt Timestamp when the workflow was executed
 	ExecutedAt time.Time `json:"executedAt"`
 
-	// Status Overall execution status
+	// ExecutionId Identifier of the persisted execution, used to fetch or replay it
+	ExecutionId *openapi_types.UUID `json:"executionId,omitempty"`
+
+	// Input Input data for workflow execution
+	Input *WorkflowExecutionInput `json:"input,omitempty"`
+
+	// Labels Labels the execution was run with
+	Labels *map[string]string `json:"labels,omitempty"`
+
+	// ReplayOf Identifier of the original execution when this run is a replay
+	ReplayOf *openapi_types.UUID `json:"replayOf,omitempty"`
+
+	// Result Variables selected by the end node's resultVariables; the workflow's return value
+	Result *map[string]interface{} `json:"result,omitempty"`
+
+	// Status Overall execution status. `cancelled` means the client went away or the request timed out before the execution finished.
 	Status WorkflowExecutionResultStatus `json:"status"`
 
 	// Steps Execution details for each step
 	Steps []ExecutionStep `json:"steps"`
+
+	// TotalSteps Total number of steps matching the step filters; set when fetching a stored execution
+	TotalSteps *int `json:"totalSteps,omitempty"`
+
+	// TraversedEdges Edges followed during the execution, in the order they were taken
+	TraversedEdges *[]TraversedEdge `json:"traversedEdges,omitempty"`
 }
 
-// WorkflowExecutionResultStatus Overall execution status
+// WorkflowExecutionResultStatus Overall execution status. `cancelled` means the client went away or the request timed out before the execution finished.
 type WorkflowExecutionResultStatus string
 
+// WorkflowHealth defines model for WorkflowHealth.
+type WorkflowHealth struct {
+	// Endpoints Reachability of the integration endpoints, present when probed
+	Endpoints *[]EndpointHealth `json:"endpoints,omitempty"`
+
+	// Error Why the workflow could not be loaded or failed validation
+	Error *string `json:"error,omitempty"`
+
+	// Id Identifier of the workflow
+	Id string `json:"id"`
+
+	// Name Name of the workflow
+	Name *string `json:"name,omitempty"`
+
+	// Valid Whether the workflow loads and passes graph validation
+	Valid bool `json:"valid"`
+}
+
+// WorkflowHealthList defines model for WorkflowHealthList.
+type WorkflowHealthList struct {
+	// CheckedAt When the workflows were checked; cached results keep their original time
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Workflows Health of each stored workflow, ordered by ID
+	Workflows []WorkflowHealth `json:"workflows"`
+}
+
 // WorkflowNode defines model for WorkflowNode.
 type WorkflowNode struct {
 	Data *NodeData `json:"data,omitempty"`
@@ -206,32 +497,233 @@ type WorkflowNode struct {
 // WorkflowNodeType Type of the node
 type WorkflowNodeType string
 
+// WorkflowNodeChange defines model for WorkflowNodeChange.
+type WorkflowNodeChange struct {
+	After  WorkflowNode `json:"after"`
+	Before WorkflowNode `json:"before"`
+
+	// Id ID of the changed node
+	Id string `json:"id"`
+}
+
+// WorkflowNodeDiff defines model for WorkflowNodeDiff.
+type WorkflowNodeDiff struct {
+	// Added Nodes only in the to version, ordered by ID
+	Added []WorkflowNode `json:"added"`
+
+	// Changed Nodes in both versions whose definition differs, ordered by ID
+	Changed []WorkflowNodeChange `json:"changed"`
+
+	// Removed Nodes only in the from version, ordered by ID
+	Removed []WorkflowNode `json:"removed"`
+}
+
+// GetWorkflowStatusesParams defines parameters for GetWorkflowStatuses.
+type GetWorkflowStatusesParams struct {
+	// Probe Also probe each integration endpoint's host with a HEAD request. Defaults to false.
+	Probe *bool `form:"probe,omitempty" json:"probe,omitempty"`
+}
+
+// GetWorkflowDiffParams defines parameters for GetWorkflowDiff.
+type GetWorkflowDiffParams struct {
+	// From Version to compare from
+	From int `form:"from" json:"from"`
+
+	// To Version to compare to
+	To int `form:"to" json:"to"`
+}
+
+// ExecuteWorkflowParams defines parameters for ExecuteWorkflow.
+type ExecuteWorkflowParams struct {
+	// Include Comma-separated top-level sections to return (steps, summary). Defaults to all sections.
+	Include *string `form:"include,omitempty" json:"include,omitempty"`
+
+	// Fields Comma-separated step output keys to keep. Defaults to the full output.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Naming Key naming of the result. `snake_case` renames keys such as `executedAt` to `executed_at`; the keys of variables, labels and form data are kept as they are. Defaults to `camelCase`.
+	Naming *ExecuteWorkflowParamsNaming `form:"naming,omitempty" json:"naming,omitempty"`
+
+	// Source Where the workflow definition comes from. `inline` runs the definition posted as a WorkflowDefinitionExecutionInput body instead of loading the stored workflow, and persists nothing. Defaults to `stored`.
+	Source *ExecuteWorkflowParamsSource `form:"source,omitempty" json:"source,omitempty"`
+
+	// XRequestTimeout Milliseconds the client will wait for the execution. Longer values are capped at the server's maximum.
+	XRequestTimeout *int `json:"X-Request-Timeout,omitempty"`
+}
+
+// ExecuteWorkflowParamsNaming defines parameters for ExecuteWorkflow.
+type ExecuteWorkflowParamsNaming string
+
+// ExecuteWorkflowParamsSource defines parameters for ExecuteWorkflow.
+type ExecuteWorkflowParamsSource string
+
+// ListExecutionsParams defines parameters for ListExecutions.
+type ListExecutionsParams struct {
+	// Label Only return executions carrying this label, given as key:value. Repeat to require several labels.
+	Label *[]string `form:"label,omitempty" json:"label,omitempty"`
+
+	// Limit Maximum number of executions to return. Defaults to 50.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset Number of matching executions to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// GetExecutionParams defines parameters for GetExecution.
+type GetExecutionParams struct {
+	// Include Comma-separated top-level sections to return (steps, summary). Defaults to all sections.
+	Include *string `form:"include,omitempty" json:"include,omitempty"`
+
+	// Fields Comma-separated step output keys to keep. Defaults to the full output.
+	Fields *string `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Naming Key naming of the result. `snake_case` renames keys such as `executedAt` to `executed_at`; the keys of variables, labels and form data are kept as they are. Defaults to `camelCase`.
+	Naming *GetExecutionParamsNaming `form:"naming,omitempty" json:"naming,omitempty"`
+
+	// Status Only return steps with this status
+	Status *GetExecutionParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// NodeType Only return steps of this node type
+	NodeType *string `form:"nodeType,omitempty" json:"nodeType,omitempty"`
+
+	// Limit Maximum number of steps to return. Defaults to all matching steps.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset Number of matching steps to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// GetExecutionParamsNaming defines parameters for GetExecution.
+type GetExecutionParamsNaming string
+
+// GetExecutionParamsStatus defines parameters for GetExecution.
+type GetExecutionParamsStatus string
+
+// ExecuteWorkflowDefinitionJSONRequestBody defines body for ExecuteWorkflowDefinition for application/json ContentType.
+type ExecuteWorkflowDefinitionJSONRequestBody = WorkflowDefinitionExecutionInput
+
 // ExecuteWorkflowJSONRequestBody defines body for ExecuteWorkflow for application/json ContentType.
 type ExecuteWorkflowJSONRequestBody = WorkflowExecutionInput
 
+// PatchWorkflowNodeJSONRequestBody defines body for PatchWorkflowNode for application/json ContentType.
+type PatchWorkflowNodeJSONRequestBody = NodeData
+
+// ExecuteWorkflowNodeJSONRequestBody defines body for ExecuteWorkflowNode for application/json ContentType.
+type ExecuteWorkflowNodeJSONRequestBody = NodeExecutionInput
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Get the demo workflow
+	// (GET /workflow/demo)
+	GetDemoWorkflow(w http.ResponseWriter, r *http.Request)
+	// Execute an unsaved workflow
+	// (POST /workflow/execute)
+	ExecuteWorkflowDefinition(w http.ResponseWriter, r *http.Request)
+	// Check the health of all workflows
+	// (GET /workflow/status)
+	GetWorkflowStatuses(w http.ResponseWriter, r *http.Request, params GetWorkflowStatusesParams)
 	// Get workflow by ID
 	// (GET /workflow/{id})
 	GetWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Diff two workflow versions
+	// (GET /workflow/{id}/diff)
+	GetWorkflowDiff(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params GetWorkflowDiffParams)
 	// Execute a workflow
 	// (POST /workflow/{id}/execute)
-	ExecuteWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	ExecuteWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params ExecuteWorkflowParams)
+	// List stored executions
+	// (GET /workflow/{id}/executions)
+	ListExecutions(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params ListExecutionsParams)
+	// Get a stored execution
+	// (GET /workflow/{id}/executions/{executionId})
+	GetExecution(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID, params GetExecutionParams)
+	// Get an execution's logs
+	// (GET /workflow/{id}/executions/{executionId}/logs)
+	GetExecutionLogs(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID)
+	// Replay a stored execution
+	// (POST /workflow/{id}/executions/{executionId}/replay)
+	ReplayExecution(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID)
+	// Partially update a node
+	// (PATCH /workflow/{id}/nodes/{nodeId})
+	PatchWorkflowNode(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, nodeId string)
+	// Execute a single node
+	// (POST /workflow/{id}/nodes/{nodeId}/execute)
+	ExecuteWorkflowNode(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, nodeId string)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 
 type Unimplemented struct{}
 
+// Get the demo workflow
+// (GET /workflow/demo)
+func (_ Unimplemented) GetDemoWorkflow(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Execute an unsaved workflow
+// (POST /workflow/execute)
+func (_ Unimplemented) ExecuteWorkflowDefinition(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Check the health of all workflows
+// (GET /workflow/status)
+func (_ Unimplemented) GetWorkflowStatuses(w http.ResponseWriter, r *http.Request, params GetWorkflowStatusesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Get workflow by ID
 // (GET /workflow/{id})
 func (_ Unimplemented) GetWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Diff two workflow versions
+// (GET /workflow/{id}/diff)
+func (_ Unimplemented) GetWorkflowDiff(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params GetWorkflowDiffParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Execute a workflow
 // (POST /workflow/{id}/execute)
-func (_ Unimplemented) ExecuteWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+func (_ Unimplemented) ExecuteWorkflow(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params ExecuteWorkflowParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List stored executions
+// (GET /workflow/{id}/executions)
+func (_ Unimplemented) ListExecutions(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params ListExecutionsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get a stored execution
+// (GET /workflow/{id}/executions/{executionId})
+func (_ Unimplemented) GetExecution(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID, params GetExecutionParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get an execution's logs
+// (GET /workflow/{id}/executions/{executionId}/logs)
+func (_ Unimplemented) GetExecutionLogs(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Replay a stored execution
+// (POST /workflow/{id}/executions/{executionId}/replay)
+func (_ Unimplemented) ReplayExecution(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, executionId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Partially update a node
+// (PATCH /workflow/{id}/nodes/{nodeId})
+func (_ Unimplemented) PatchWorkflowNode(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, nodeId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Execute a single node
+// (POST /workflow/{id}/nodes/{nodeId}/execute)
+func (_ Unimplemented) ExecuteWorkflowNode(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, nodeId string) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -244,6 +736,61 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// GetDemoWorkflow operation middleware
+func (siw *ServerInterfaceWrapper) GetDemoWorkflow(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDemoWorkflow(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ExecuteWorkflowDefinition operation middleware
+func (siw *ServerInterfaceWrapper) ExecuteWorkflowDefinition(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExecuteWorkflowDefinition(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetWorkflowStatuses operation middleware
+func (siw *ServerInterfaceWrapper) GetWorkflowStatuses(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetWorkflowStatusesParams
+
+	// ------------- Optional query parameter "probe" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "probe", r.URL.Query(), &params.Probe)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "probe", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetWorkflowStatuses(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetWorkflow operation middleware
 func (siw *ServerInterfaceWrapper) GetWorkflow(w http.ResponseWriter, r *http.Request) {
 
@@ -269,8 +816,8 @@ func (siw *ServerInterfaceWrapper) GetWorkflow(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
-// ExecuteWorkflow operation middleware
-func (siw *ServerInterfaceWrapper) ExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
+// GetWorkflowDiff operation middleware
+func (siw *ServerInterfaceWrapper) GetWorkflowDiff(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
@@ -283,8 +830,41 @@ func (siw *ServerInterfaceWrapper) ExecuteWorkflow(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetWorkflowDiffParams
+
+	// ------------- Required query parameter "from" -------------
+
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "from"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "to"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ExecuteWorkflow(w, r, id)
+		siw.Handler.GetWorkflowDiff(w, r, id, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -294,16 +874,378 @@ func (siw *ServerInterfaceWrapper) ExecuteWorkflow(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r)
 }
 
-type UnescapedCookieParamError struct {
-	ParamName string
-	Err       error
-}
+// ExecuteWorkflow operation middleware
+func (siw *ServerInterfaceWrapper) ExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 
-func (e *UnescapedCookieParamError) Error() string {
-	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
-}
+	var err error
 
-func (e *UnescapedCookieParamError) Unwrap() error {
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExecuteWorkflowParams
+
+	// ------------- Optional query parameter "include" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include", r.URL.Query(), &params.Include)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fields", r.URL.Query(), &params.Fields)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "fields", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "naming" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "naming", r.URL.Query(), &params.Naming)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "naming", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "source" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "source", r.URL.Query(), &params.Source)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "source", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Request-Timeout" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Request-Timeout")]; found {
+		var XRequestTimeout int
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Request-Timeout", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Request-Timeout", valueList[0], &XRequestTimeout, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Request-Timeout", Err: err})
+			return
+		}
+
+		params.XRequestTimeout = &XRequestTimeout
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExecuteWorkflow(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListExecutions operation middleware
+func (siw *ServerInterfaceWrapper) ListExecutions(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListExecutionsParams
+
+	// ------------- Optional query parameter "label" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "label", r.URL.Query(), &params.Label)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "label", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListExecutions(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetExecution operation middleware
+func (siw *ServerInterfaceWrapper) GetExecution(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "executionId" -------------
+	var executionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "executionId", chi.URLParam(r, "executionId"), &executionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "executionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetExecutionParams
+
+	// ------------- Optional query parameter "include" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include", r.URL.Query(), &params.Include)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fields", r.URL.Query(), &params.Fields)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "fields", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "naming" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "naming", r.URL.Query(), &params.Naming)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "naming", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "nodeType" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "nodeType", r.URL.Query(), &params.NodeType)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "nodeType", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetExecution(w, r, id, executionId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetExecutionLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetExecutionLogs(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "executionId" -------------
+	var executionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "executionId", chi.URLParam(r, "executionId"), &executionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "executionId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetExecutionLogs(w, r, id, executionId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ReplayExecution operation middleware
+func (siw *ServerInterfaceWrapper) ReplayExecution(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "executionId" -------------
+	var executionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "executionId", chi.URLParam(r, "executionId"), &executionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "executionId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReplayExecution(w, r, id, executionId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PatchWorkflowNode operation middleware
+func (siw *ServerInterfaceWrapper) PatchWorkflowNode(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "nodeId" -------------
+	var nodeId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "nodeId", chi.URLParam(r, "nodeId"), &nodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "nodeId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchWorkflowNode(w, r, id, nodeId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ExecuteWorkflowNode operation middleware
+func (siw *ServerInterfaceWrapper) ExecuteWorkflowNode(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "nodeId" -------------
+	var nodeId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "nodeId", chi.URLParam(r, "nodeId"), &nodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "nodeId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExecuteWorkflowNode(w, r, id, nodeId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
 	return e.Err
 }
 
@@ -407,12 +1349,42 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/demo", wrapper.GetDemoWorkflow)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/workflow/execute", wrapper.ExecuteWorkflowDefinition)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/status", wrapper.GetWorkflowStatuses)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/workflow/{id}", wrapper.GetWorkflow)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/{id}/diff", wrapper.GetWorkflowDiff)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/workflow/{id}/execute", wrapper.ExecuteWorkflow)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/{id}/executions", wrapper.ListExecutions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/{id}/executions/{executionId}", wrapper.GetExecution)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/workflow/{id}/executions/{executionId}/logs", wrapper.GetExecutionLogs)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/workflow/{id}/executions/{executionId}/replay", wrapper.ReplayExecution)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/workflow/{id}/nodes/{nodeId}", wrapper.PatchWorkflowNode)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/workflow/{id}/nodes/{nodeId}/execute", wrapper.ExecuteWorkflowNode)
+	})
 
 	return r
 }
@@ -420,35 +1392,111 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYXW8jtxX9KwTbhxaQrLFXcrd6irNOWwOLxIgTuG1gLKjhHQ2zHHKWvGOvaui/FyTn",
-	"eyitnLr7lDdphnN5P849PJfPNNVFqRUotHT9TG2aQ8H8z3dacYFCK/eHg02NKMPf7hUpmWEFIBhLMm3I",
-	"kzYfM6mfCHyGtPKrZ7Q0ugSDArxZ95uhNjGrRcmMsFqRZpE3mra7wSOTFavNgqoKuv6Fbg0wBPMBc+Ye",
-	"S7C2+Q2fKiYtnQ3WfNDmg3/RX9w9fJhR+MyKUgJdj23jrnRPLRqhtnQ/o5gbsLmWfBrNT80r4pyGOpIm",
-	"Qtrb5WI1o5k2BUO6ppnUDLutVFVswND9fkYNfKqEAe5ibpPYd+Gh/UpvfoUUnYPfGRNSPSwCNI+HPvvV",
-	"pABr2Rb6LtL7prBKI8l0pfg0HSMfwx5Rpxpw3CGUU+cGPo1dvO7+EZ2Rp5wheWK2Bhzwgde3RqdgLUm1",
-	"lJAicMIZMjInihUwI1AwIWdE6rTB1KTApySKiIxgDsQilCRjQgKPmZJsAzISkLClZDviX7uQnCml+TD/",
-	"P1sw5EaVFcZMu+U3EQzeXDcGm/RMLTvkxWzqCt1u62fKeGg/Jm97dUJTwWy03w/+m5DkzOiCYC6sz0t/",
-	"y2eaCtzRNb3bcQU798oVgq4pkyKFb+qFZ6l2jrlS0TW9cq98y43RZJFhZSM1alBGwoqQip4/NX+4ppQQ",
-	"oNNWz34UZQl8yAb9lVMq8A8mLLAr4WBR46kftVFd23pZG26sr77XHK4ZsldoKZ8otzXhGuzA629hKxR5",
-	"AoY5GJLmkH5sef83496xYzRHd8hMFPMFION1sKcj9KpdSRoD471HaY1B7lbb9mQcJvrzNNB/klRrw4Vi",
-	"OAhtfn6ZfJn4Z3Q3NfmvAybfJMlJR8kkoIbe/zfkuCT2kNAV8V0ASQOZ5jy3hClOLChOmASDNsrAfBvx",
-	"hL4XFt2e/rUzqSBFobZtJZ0xgVD4b/9oIKNr+odFp3YWtdRZNLF/x7d9gmHGsJ37LyLE+rMSnyoggoNC",
-	"kQkwLYii8a9WCbxdJskcLv66mS/P+XLO/nJ+OV8uLy9Xq+UySZKE9ipXVSLKMYELx858zwo4mv77OvFX",
-	"Lsnk/kivhsQdTLZ/TYQab/WiPDuWmuZ5xHoiLmcGtZpglSlRMEfPkwDuc/AZ8Ech3wKxua4kJxsg7Ue9",
-	"jAXmqHffaC2BqZdDwW00qAOcv4Af33te5IElgROt4kZvlEDBpPgPHDR+h7tw7p7Ok+/u7oh1n5EuxYPA",
-	"Am/T2HmsK5NGYHrnn4dD5eZ6EIM9RPLB1j+Y4vKwxdy/7lfgT4OxgckA3D8P9nRRR7f8PyQrliZkZgsY",
-	"UQz+eTRNh6TacekxQYwttMa8VkHHxYenobqgrctHG7MRXUGrThWp6gTiafNi2h9Dj/FLN6/uA5Vev1gd",
-	"/E2boqdeKye6hfd4TjIJn8VGAilYSVATW5WlNki4yDIwoLANxp4mdp+ElN9s3Z+h0r0X0vVVNydPptBu",
-	"6LxY7U8SLJPy/Ai2khgZDOs54SqGTFGARVaU5CmH4RlweAS7SC6W8+R8fr766Xy5fpOsL5Znb1eX/+4f",
-	"d5whzFEUB/oxLu9/eATDpOyQU8v8Lyn7khnHly9Q9q5Tjs4XHJAJGVoeWJo3E8ZJx+JwGP7SudirT5ua",
-	"xsNjfekP3am4qzvkmH/tUPHi82+i5Q/SfNnT08d8aXX3i+atGg3N7uDvLmouFQpha5rxv+Oapk0fTvA/",
-	"xpl+ybQgbq1QmZ46fnV74xNXMMW2Tsc6aVxjW20H3IICh3cyV7c3dEYfwdhg6/wsOUv8DF+CYqWga/rm",
-	"LDl749GPuS/9orG4eBZ8755ET6MfAY2ARyCs63UOmVDhQm6zIwItqcYYaPnLnQScrunfAXu6s7s1pOtf",
-	"phdnMDUY0bfCLXbxdNTpU9/VIlB8AE8gt9cV5PsHt5sttbKhny6SpD6yEFS4OylLKcL10uJXGxDeOXSK",
-	"XA6gGQmfKk3B2qySckdMXSLeJWc/o8tk+WquhEvEiB+RW8H9jK5eMQsHt75RCMZJOwvmEQyBeuGM2qoo",
-	"mNkF0HWo3eyCnEK2dahrfbf0wX017IdFzbOeMrXFQ+w/6IsngbkHaWn0o+DAa+3gWXbcEPX3r9oUTpY0",
-	"jv+W/jgJ7p8qsPit5rtXR/pIO0aL/iX1uP8KHTkWUccao713tb2WDf35VZrkkUkxwOHv1OCpYdq+h6jB",
-	"febtxNryvU6ZJBweQeqycENAWEtntDJO5OeI5XqxkG5dri2u3yZvk4U7k/cP+/8GAAD//yxlmy99GwAA",
+	"H4sIAAAAAAAC/+w9/XPbNpb/CoZ3M9mdoWTZsbOpMzu32Ti79VyaZOy02btepoHJJwkbCmAB0I4u4//9",
+	"5uGLoAhKVD6cdK+/tLFIAA8P7/sD/JAVYlULDlyr7PRDpoolrKj55xPBS6aZ4PhHCaqQrLZ/to9ITSVd",
+	"gQapyFxIciPku3klbgi8h6Ixb+dZLUUNUjMw0+K/qRYyNeuqppIpwYl/yUxahNXgmlYNxX9OiZaNXq5J",
+	"sYTinSJ6CWTOoCoJ0wqqOaG8JGzBhQT7UC8lqKWoymmWZ8CbVXb6c7aQQDXIX/SSIqAVKOX/Db82tFJZ",
+	"nnGhfwl/xAN+EdI+iEfGP1oIszd5Bu/pqq4gO91cUa9r/FVpyfgiu82zAGYfPa/8I4JYAIcaj7JHbrcl",
+	"uVrbDVv8BHRHUByd5NlcyBXV2Wk2rwTVLSi8WV2BzG5v80zCrw2TUCKmomlaEN+EUeLqn1Bo3MBTXtaC",
+	"cf090EovcRfd4wcpU2f/emmhBjecFKKpSsKFJldAaimuoCRIYFSRhkugxZJeVZBCIRclnCfwd841LKSh",
+	"HoLvkEYxvuisGiMpuwGqlyAntGapZVoYEpsBHGmmXgqlCeXqBvBs9NJthtwwvSSUr8n3r169JBJULbiK",
+	"9nMlRAWU40pKU92oJ6JMLGVG2xdIgZsS82iRaNr28GezsAhDlOBp51kjq/7sP148I3pJtUG7PYTcTG/E",
+	"BBgmMxt0qyJa7ylCa/Y0hdGl1rU6PTigNZuKGvgEJYeYFmLVR/AG/blDtXDGyE/SoKexUaRn3iYrUIou",
+	"OpjKXntphmQ4Fw0vd8Jp10gC5SXiM6Z0Ajj/WCUgDM+I4EQvmSI1XUBOONyA0mTOpEJMMw0rM/zfJcyz",
+	"0+zfDlrhfuAk+0GY7LJZrahcZ7cBWColtX8LTRPk8Ap/JlZC4JG3MJMV1cXSs9OcVagRsj6dbWKr3bRf",
+	"dDvqxOIp13LdRx/VWrKrRru/SqswaPUyekvLBvKNLV3AijKOgCstm0I3yKXtZJ6wAVfNiYSSFhpKUrF3",
+	"YB4okNesAFKJhYpp54Pj2uz0ZHbfcVegf2im7kWk/QMnaA4u1yWH+DxaDFRwDYkDeSYWxD5qddrZ07/+",
+	"+Pcsz86f/+1FlmevH188z/Ls6cXFi4uuKnJPeoLNc0JytRSbPH55TiToRnJAic0nR+/fxzIpJ1quPW1w",
+	"eK9jeTtefJ/FUqY9FSObKrFYQIkqMSc3S+CEacLpCg+Qwz5SXbMVKE1XdVKq8+SyWaRNS6phgpPslBT+",
+	"3FqMtmvvYoIdIiSJvhK4ZnNmOdeoCJCKKaTm2FgLO2kaVqYwZCg9SRuIFwaKMIsmIUurA9cElR+5kUxr",
+	"4HvLqcD0PUE1JE2MojBwbkXki3pA3l403JwhEdcgJSvBGreUoMFQQYuvR3Z7mr5DjQsFlMALO8xS6Qo0",
+	"LammPSu4lOuLxlnWc9pUOjud00r15NPlO1YTeK9BcloRxUogMJ9DoRVRTbEkVBEFvETuQklWESFJQasK",
+	"f3j88lwlDQrPfi/89oZl5oc+AQxbVSgI/NyKaEEaBYRxpYGWnuqUNkZqZCLk5B2srd1qcHZ+1hWkMce2",
+	"MlRpumB80RGk14cHcyGhoEr/R0U1000Jf/5QUX37P81sdvSgEnzhfxT8NilpJRgi7hPFJWjckjkl/AfV",
+	"Gla1JnANcu2RzwvIrSEe5J0oQd0jOO2aKNCa8UX6VJDiRKN/SKz9yj5CzlqxqmIK0CeyVImWEGEbp4Ak",
+	"kHvy3QClXSdC88kMzcIVfc9WqEbuz2b2B8btD4dJXT7MW5ca6r6E6uxq48/srP0LieXG252W26CMwc1e",
+	"SlGAQv1SVWBUMrIZmRipn9vjyEklCurkWo+Mx1iDhHmihZrMKasgLRLpVUo7nzFVV3RNzONYeXV28qMC",
+	"Sc553XykNvTo6c+Mojw1p2g0rraXnfTCjLFInkuxslYo4qXLrAXTa2QWa8rkmTmI7DSjFSvgLxGzZnmG",
+	"R4XmAz5K8yK9ufAeTF9I05vg35ArUa4RIZR3WMGc281SKGc0KNDK6z0/c2TVecsBnVsjVxXT7BpQPqkU",
+	"Ir2JN2iveytIzLv4cqYaar0KLGkH6lLvWF1D2bXV4jd7YNgfejJjXcMg0Q2Rxg2V3AiovvgTc21cwApW",
+	"CvUauVmyCsL0RFJufcWSWb8ddxS4Jw/qinIC72vLswzJ3sVthCSWMMk1lQx9u9b1XDGFqjfews/Z09Qs",
+	"wVMjjHvW+IlKdWqf/9nQ55vIBBnA5oCJEdxQ81aggK1mhvezBuw1KB/rtMQ3tqAlSkRjiK1tCMUxtmd+",
+	"V8YhirpPMSeemQkiwYZchBuWDTcxkyyps1HOvpiP2ZiQbMHQloqmtxhmdhGmCCV2xjE7HpIBaFvRKl7G",
+	"vTmSrbfathHlbKXBvyHND0RDDD8klJbQk5rqpcdXxFw5geli2oZjpyESSGykdnVGNZ1akd/dZm/EXo7n",
+	"a5QDTJEbKfjCRs5CvLezUAsQHqZDH2k4hmfbmDJTLji6E+t+BQ9ZCsfPRQm4789g7RgaNMK0FNCllL/C",
+	"gnHiTGEb9A4C4aNNEjQfk+rhUlOp00fk/Jm9jIfH4c3gEG2uvYHW2wFEB6FqDaY+v1tqFRJZ2YR1gtNm",
+	"8MqQv0VFk5mJIk54bHNN28zIbf6VPblWw+13JD85HasIvaassupWhBPJERw2Jwo0+mVAZcVAGk2utht8",
+	"AYk/gPZLeyPwhlXVXxb4h4/5wsowZSMxMP1wenI7ihJeChXOqXuE7/sk8Q9SCCFLxqnuEPnk8MFsdxok",
+	"z9b9Kf9rYMr7s9moxEpvQ68kvQapoHxaLqC/KzZKZUO5EZeD43TeQonqGspnaQmBEDjxYARtXdECUKSC",
+	"VFYtFm2O6TpQUbBSEAqjsDEowrtxwgqkZc5ALX11KhpZJFTA8xDzM/ABvQaV1jHDs35PeZlK11yap2Rp",
+	"Hm8uYrydvJuHpJX1pbu6RzZJw0tTuQC9a0fAXcQ8Oj6nR7erKGOVOKyFxVJ66idasdIIvk9IjxBljFlm",
+	"Mmc28mHtbjs4Bp/xa1wxMoCMKXEa2Q9eI5+2unnVKJPxE9w4L3G6NCchy5oTm5HNSZudzUkyORuNCr8R",
+	"IQctgNwq/ZRFx33otwbZcTqMn1JQfs+AjkYkVWTBrscHOyNDbWeU02HagZk6ap+36p9xQYsl7E5aljBn",
+	"nNlDU8QMct6xBFpOySvZAKHVDV27p4owbbKBNjzGkTSMEfPIDms4ahKmo+kEL8D9Im18UAMnwEWzWE5j",
+	"SrI6pK/79jGvYv8pnjt7Yi0pb1d50lRmM6iPCUWplXT/kXFToXBmE6LmMU7JodCdGNxYqvDnaLRCIk0X",
+	"WOuFLEEOiJjzMxOKNa6Nc3PgPS00UfBrY8LVUYR2LqpK3BjmNtBf4dHo5cRkGKfkB8udxhT1VrjSVGpv",
+	"NPDSxiNt8BvfXtJrMD4/SjkXObLsfQVzIZEEpl2/Xjnj08UougmbWMwHR4OX+7j0eVKf/sjZrw0Q1qpV",
+	"b6Em6ebkZAYPj2ezCRx9dzU5PiyPJ/RPhw8mx8cPHpycHB9j+HSM+2hjYL2DoyvYSravHcFalfp6iyNg",
+	"CW6QSM1jn7GJltqLPpHOUngO5sF+tumZtaMj60IBlFB6ionSL4hfJ4rR3lBRFqZrpkZ1NVj90nCGp1JA",
+	"pVijsqTZuaFltwnasyAvdzkoj40spNcoT93gWNpqsbBS2LAX02pTffacFubXGSVIutBhwC9SFWNm6GEm",
+	"TLAVP2w+TxgcXn6OlYFmGtTQUqz6qP0JpEI82ZoozAZLsYqp4DBV9xL4YyyheyC0GAGCFh3/IAWAR+C4",
+	"uFyYOeLVHUJm4MBMIMmhyIAZTFpzLNuOM+2lUM5WVEO53bTA2YlamuquKyBh0AiFv5/Y7rtDh3sESox3",
+	"REobLjHWSnrSc2RcWrH/hcHJL/W6gv0k4JPLS6JwGGlR3NmY9dBSsdAhB8o5OYkMa1C4n+Q2RSfwh6Sz",
+	"9Mcpedz+ek+Rt8aSfRsmUM768KamJXf3vi/rxKSCmo5yvNQXwHsK40MO3ivzexLjQ+mX7ZmcHvGplRB6",
+	"6ZJKn8lNjHn8yZLyJKfPNcixMtMbr1Z57zuKbc19FgbAclzoI4URB1TutrQLIWk9RssyJfWeGgta8Grt",
+	"7SstyLXVELmtjrGRlPOzfQ2uIYfA4WMIFsbJldBLD4RyqdHI/ijZfA5SfQbwHO0kgJSwEtfjEGb8hS+J",
+	"sg2asCfZgthidCtljApKh6j3iBr5j4tEQ6KwaVSFlX//1toSZ3sH+P+GZnhbG9CoECCZkHkF7xmGl1e0",
+	"Rg5QTV0LqR2pAdcBIWpcKUEviuzqCF6zCjVi21/QK7aPfYBkleVnyl66GqfgIrc2PJ6/rY5FH3vJ8M11",
+	"d9tasgWahqdZIQ1pIDZ9BUU2LkDeo8wLUCYz8YVS0AH87Gh2dDyZHU4OT14dHp/en50eHU8fnjz47zvM",
+	"U+dIf6Wp1QJdLImQLp1LWLcc/buro/lhcR8mD+lxOTme/+lq8l3xACZH5SE9ufoTPJx/N8qH/0QX7P9f",
+	"zlwGavyYhJUCV/F1FVpGfOOBnTi8+qhDtPeUq1K2/TNJ43nPZP6UvC0oL6CqoHxLVkC5PYaiYkaw4X/o",
+	"DV0TZ0KirgGlTQleSUSjfUhjQ0owztQSOq1KYR0TBEvUDdVUoieyR92QzeZtqV4qQaO53ZYZOlNzzz4D",
+	"HDTUZHCZhmGz08CA2m0ysDV5ttPgkUlUGpqcg3uHejEcq9m++63j1JsaMkqCZ1I2obIzEjgDFc8+BTYK",
+	"X90c4Lha524FiD/RbbbKYFuWL9tNFNrZXhtWMb1uq0LaIrswNCe1BAXcnYTtFxpNLt2+sVS0e3vnWNBN",
+	"nc6xStDSdo5ZRiHXIQ2WFOWjlM6nRITvPgJsdrw9KBNwh+iyuY+aKgWKLCStl0mkhcBMyq+ya+4mxHQj",
+	"gylvSRslrzdNEWVZzQ155LNLVhMo8g6gxveZbLWYMz3G2SNhnUTvndmC8cytcBRxYO4T/ZUhPtjAdouq",
+	"GNRtiH/u+gi7KPdFPdtAC5VOe8fiegVGgyGnOirt2AZLKAHZq/7V6VK/OvCyzTNFIm0gz6TqyliQdLGQ",
+	"sLC1H5UQtW0SBquYV8CtSBZ1VxUPbDnFPeaVXWf4WYIzPnOzX3DGjxoXnOkd/tYWrE+M0oQI/dgozXNR",
+	"bgQdPluUZigtNhileS7KO4zSRES0T5Smj7DPGKVJo+wTojS3xj+bJ3I12CaD8mlFuekksslra9NhlWkU",
+	"lNBMd/uBH788RyVnt5ydZofT2XSGcIsauO1Tuj+dTe8bs1wvzfYP/IwHJawMPMmo8YV1URCvVw2r9IRx",
+	"ggMi35vpJboOWjStNYzy+4oqmJJzrcj5mbWV3d0HLn2qDAOGiaYhWOK87ezvoM9gJSJjwjdYmB0czWYu",
+	"OKWBW/etritmW2wO/qms1LZHukc+sefPXjZFAUrNm6paE9uPde165zuYQISffEaYXP3NbbLHzVZtgsTC",
+	"FnAv5pnytf2IuwSEeaYpdlP8HGhHZW9wYEsNzpI3AkuoBEW4wi2ICBQITaaPcQLTEFExDlPyXGhDIFHR",
+	"jWwjJv3zty4b9LPamWVAUPqvolx/diIYTJ8nzuJ1YtsR50Z58lZkaNnA7R0Q82a4bRv4oW1LRdSOFH18",
+	"NxRti/NSRCR87LZ0tt7x0dFng2izCjEJm1+c1FSiE3LVdMvrXCMC5aTh77i44VGeMFQSmoZd1zjU9gKY",
+	"ghFb1v8tSY+nnq375SFjZEgbtxrQKSbifuM8vpS34v291tNDrsqJqG14rlrnYTzTKh0AIFQCCVdzTMmF",
+	"c8LwZyeAUOPenxHXwJpUQX6Xl2ZTpiShvWUoO/25p8grJdx9J71eWA/aPWXvKbG3rpDvnz4+88G4KXH1",
+	"Riq0906NO5CdZr82YMLzLsNgFsnyiCB6vvCbOxAzkeu8S3s69zCyZu5awtiDmVd08U0xnK37NBf0BC8e",
+	"I7wds28X231g5e02Q84YLgOq+mpt+KjZ9Jm3ccQuTni1hP6EiSiSoW00TFvSZmVPY8Z0/rkLH++ET/aw",
+	"LWOT8uso4PMzu/bxl187ca3Rt2ZJB7R4L3IULx6UzvNPMqS9Xg6IvhFe+wUXG7m/XRTtyYop3VZsm59s",
+	"SbTxPnPinE/TpeUCHVegb8CGJ1dT4msBD02/YbeoPpQ+K3t3k1mE1OiyPbJKzPzbgqnae3McvFvVpol+",
+	"fDVBsbMWcahkUgtf2+jrNlMa2D0aIagOd11eMQIQLQbA0GIcEPd3AHEXUtDWq+6yE0LBKnZ1Oa74msIQ",
+	"+aoLx10KRrfyNyog8USNFLvZgHe00bI76PC0H2cI5Sy1FNesDBcvuPuNtoYSPotAQtb0gN+JbHoiVis6",
+	"UYCga2QOUU/MtV3ov9g2IS18UcEfTPI1dx1y6z92/QpatYOG3AvGi6opIUuKEpvbzd3sHwM9TuCv2MAb",
+	"TRAsTI91ATUB3aaq3JtDsLruszSoUVtv3ukHHgH1f8KacLpCp90dv03lTclbxek7+KWgCt4SCfZ6N7MR",
+	"3z/9ts2Kv8W9hL9/ofqtLQYx74t521uS21Jqq+HnoY4Nxf87qDVOaxL6VEIXUW8LuoLqCUIzhCS7jy6S",
+	"QjmHG4xPw76yNyMw9HoJErqMEZkWhVi5ptUpeWsDgW+JbHjPBnGxQqoIJbsCcfZ+nahPDPPEbSHGRt7T",
+	"pI9tlFERbmOQG7izYwYRFwqV+4izI8043NsojP0Q35kV1+cw9Pgo022Nt9/2lDwTfAHS1gr5EEZdI8Z0",
+	"uPkR5D1F3KVZYS9LoCXIdjP/mFzYSMPEXeOV5prDETduvfmycdjd0dfdpay/x1lTls1GQHV2eAfGDFwt",
+	"hXhHFFtwI4pDMNQEeC1c9lrDroqn5MYPhUKC/sp+6b9u9Pn46Lsvj9RXsZ6glQRarsnSajUvutJX+rob",
+	"VL626YurH98NnqCtfPR3mJlKTMMXLtndl+YDkfy9AviRUe4r95MxDNO2S+OS1kQBtOpeDB0H8V3BpE3P",
+	"+x6yruWOS7QXTv+GIgkveLX2xnhExgWV/vZhpuyec3s5A9o+72B9alQ8ZitqoNpa9AYoosDU/dpBruus",
+	"rkz9lIU2Zbt4rCZU/M++uP/UlPbv1bPuutkMWrKEibONk4OT0rXCTmZD9lfFVqxrpoSbQY92XwvaqxgJ",
+	"MIUC3i5weOXhACRiPlewAYpffHbHEZXuDe67QirVJld+zXBK7m/8MuxvygCw6IV6k+2biW0YAbdZuB3H",
+	"NSLRtF2GHnyIWklGpWkSotQ5n934cL7Rpt9ek2cugqmlKJsCSrzZglyGS0Rb35LxaAhT4f7RZFj3adwk",
+	"9huRxFtBicvxE7B0Lzr81wrmfHMhm9+jMZ8cjYmtDkM0XiaY635dX0YKnPAwAc6Oe4E/Aih/A7H9dgKO",
+	"H0CSKOGVfZyK7HWLpHcHXnpWiYVmwCBBvgomgnnzIwyUk89ioAQ4fyO2yR6hkYEUeCeE83XsFCFbKIzR",
+	"EkUq4navO4tIBGx+w6nydJ/bp1lLB/4jH9tNJhThVfThD/eFD3czeB8wIinPnSCvqdS2cpxpezG679nZ",
+	"agU9s9/Z+d0SGg/U3bhE7qM0HyFv7LeTvhWh87tsiWVLFEm5p/xHrj5VuLhG8cHc8wVMzB2aPflxTzmn",
+	"iS4o464+p2hk5xaJKMfVkyQXZuV/XZfKGlauDf83KlI+Jslj97yR5HkU+StEgrlspHDVVP0bDb4tq6eT",
+	"3eWCVCEZaVpBvroFdMdJmVdtmtkKgO5lu1ukgU/WRKkXwjThhmgM+SvC2g+M/J6V+RhVYcXqJ1mipsry",
+	"4IP9voqJ1pkKyNTFvlBPViAXJmJn77xw30ygmhLb90cY16KFBx9PyXMwsT37RhumCF8koBKImbjEuAQm",
+	"SPB/lJeEEo7hFfvZXVv4qQjFx/2Q3UsEu9PO+NtSMn0YDHKjDNh2mMIXcsYUk2/vBP5C1Q5tG3uf2l/2",
+	"CEoLSxSWpKJ6F9fWfPddZrZHtg87/k6auqRfvdrBsK5XY+aPuNOofxHHnRcYCPd5xq5Om92R4LcMhaJH",
+	"lMhpJhlLuTAZBUfxj9wXAwMGvymB79ikWjt6Qwlp2WFcprsr7XdXo140nAjuMBere7xMrmJQthHktj3a",
+	"3qfjq9TMRSadltjxjbC/i/HtYnzoSxVfUIDvLlWLvnDDS1cCoTo3kqAt9UXr1TauwBoQ2T4t8ohQLxsN",
+	"dCYzWAtpO7rbO69c4uBrl7B9dXn97TXvxp+aGpKFONJMlRIiz0RBK1JiVlLUK+DaLZvFXxA/PTio8L2l",
+	"UPr04ezhDL+nn92+uf2/AQAmVuWWzIMAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
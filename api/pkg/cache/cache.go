@@ -34,3 +34,39 @@ type ErrCacheMiss struct {
 func (e ErrCacheMiss) Error() string {
 	return "cache miss for key: " + e.Key
 }
+
+// Cache backends reported by Describe
+const (
+	BackendRedis    = "redis"
+	BackendDisabled = "disabled"
+	BackendUnknown  = "unknown"
+)
+
+// Info describes the cache backend in use. Pool is only set for Redis.
+type Info struct {
+	Backend string     `json:"backend"`
+	Pool    *PoolStats `json:"pool,omitempty"`
+}
+
+// PoolStats is a point-in-time snapshot of a Redis connection pool
+type PoolStats struct {
+	TotalConns uint32 `json:"totalConns"`
+	IdleConns  uint32 `json:"idleConns"`
+	StaleConns uint32 `json:"staleConns"`
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+}
+
+// Describe reports which backend c is, with pool statistics for Redis. A nil cache is disabled.
+func Describe(c Cache) Info {
+	switch backend := c.(type) {
+	case nil:
+		return Info{Backend: BackendDisabled}
+	case *RedisCache:
+		stats := backend.PoolStats()
+		return Info{Backend: BackendRedis, Pool: &stats}
+	default:
+		return Info{Backend: BackendUnknown}
+	}
+}
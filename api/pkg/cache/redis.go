@@ -3,12 +3,24 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Get and Set retry a dropped or refused connection once after a short pause, so a brief Redis
+// hiccup doesn't surface as a cache error. Both are kept small because they sit in the hot path,
+// and the client's own retries are turned off so these are the only ones.
+const (
+	redisAttempts   = 2
+	redisRetryDelay = 20 * time.Millisecond
+)
+
 // RedisCache implements Cache interface using Redis
 type RedisCache struct {
 	client *redis.Client
@@ -16,10 +28,9 @@ type RedisCache struct {
 
 // NewRedisCache creates a new Redis cache instance
 func NewRedisCache(redisURL string) (*RedisCache, error) {
-	// Parse Redis URL
-	opts, err := redis.ParseURL(redisURL)
+	opts, err := redisOptions(redisURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+		return nil, err
 	}
 
 	// Create Redis client
@@ -36,9 +47,26 @@ func NewRedisCache(redisURL string) (*RedisCache, error) {
 	}, nil
 }
 
+// redisOptions parses a Redis URL into client options. go-redis retries failed commands
+// 3 times by default, which on top of retryTransient would allow up to 8 attempts per
+// operation, so its retries are disabled.
+func redisOptions(redisURL string) (*redis.Options, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	opts.MaxRetries = -1
+	return opts, nil
+}
+
 // Get retrieves a value from the cache and unmarshals it into dest
 func (r *RedisCache) Get(ctx context.Context, key string, dest any) error {
-	val, err := r.client.Get(ctx, key).Bytes()
+	var val []byte
+	err := retryTransient(ctx, func() error {
+		var err error
+		val, err = r.client.Get(ctx, key).Bytes()
+		return err
+	})
 	if err == redis.Nil {
 		return ErrCacheMiss{Key: key}
 	}
@@ -62,7 +90,9 @@ func (r *RedisCache) Set(ctx context.Context, key string, value any, expiration
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
 
-	err = r.client.Set(ctx, key, data, expiration).Err()
+	err = retryTransient(ctx, func() error {
+		return r.client.Set(ctx, key, data, expiration).Err()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set key %s: %w", key, err)
 	}
@@ -96,3 +126,50 @@ func (r *RedisCache) Close() error {
 func (r *RedisCache) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
+
+// PoolStats snapshots the connection counts of the Redis client's pool
+func (r *RedisCache) PoolStats() PoolStats {
+	stats := r.client.PoolStats()
+	return PoolStats{
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+	}
+}
+
+// retryTransient runs op, retrying it while it fails with a transient connection error.
+// redis.Nil is a legitimate miss and is returned straight away, as are server errors.
+func retryTransient(ctx context.Context, op func() error) error {
+	var err error
+	for attempt := 1; attempt <= redisAttempts; attempt++ {
+		err = op()
+		if err == nil || attempt == redisAttempts || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(redisRetryDelay):
+		}
+	}
+	return err
+}
+
+// isTransient reports whether err is a dropped or refused connection that may succeed when retried.
+// Timeouts are not retried: a slow Redis would only be waited on twice.
+func isTransient(err error) bool {
+	if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, redis.ErrPoolTimeout) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"cache_miss":         {err: redis.Nil, expected: false},
+		"server_error":       {err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), expected: false},
+		"context_cancelled":  {err: context.Canceled, expected: false},
+		"deadline_exceeded":  {err: context.DeadlineExceeded, expected: false},
+		"connection_closed":  {err: io.EOF, expected: true},
+		"connection_reset":   {err: fmt.Errorf("read: %w", syscall.ECONNRESET), expected: true},
+		"connection_refused": {err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, expected: true},
+		"pool_timeout":       {err: redis.ErrPoolTimeout, expected: false},
+		"read_timeout":       {err: &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, expected: false},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isTransient(tc.err))
+		})
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		errs []error
+
+		expectedCalls int
+		expectedError error
+	}{
+		"success_is_not_retried": {
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		"miss_is_not_retried": {
+			errs:          []error{redis.Nil, nil},
+			expectedCalls: 1,
+			expectedError: redis.Nil,
+		},
+		"blip_is_retried": {
+			errs:          []error{io.EOF, nil},
+			expectedCalls: 2,
+		},
+		"retries_are_bounded": {
+			errs:          []error{io.EOF, io.EOF, nil},
+			expectedCalls: redisAttempts,
+			expectedError: io.EOF,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			err := retryTransient(context.Background(), func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestRedisOptionsDisablesClientRetries(t *testing.T) {
+	opts, err := redisOptions("redis://localhost:6379/0")
+	require.NoError(t, err)
+
+	// retryTransient is the only retry, so one operation makes at most redisAttempts attempts
+	assert.Equal(t, -1, opts.MaxRetries)
+}
+
+func TestDescribe(t *testing.T) {
+	// Redis clients connect lazily, so no server is needed to read their pool stats
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		cache Cache
+
+		expected Info
+	}{
+		"disabled": {
+			cache:    nil,
+			expected: Info{Backend: BackendDisabled},
+		},
+		"redis": {
+			cache:    &RedisCache{client: client},
+			expected: Info{Backend: BackendRedis, Pool: &PoolStats{}},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Describe(tc.cache))
+		})
+	}
+}
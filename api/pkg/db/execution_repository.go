@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+)
+
+type ExecutionDB interface {
+	CreateExecution(ctx context.Context, execution *Execution) error
+	GetExecutionByID(ctx context.Context, workflowID string, executionID string) (*Execution, error)
+	ListExecutionSteps(ctx context.Context, executionID string, filter StepFilter) ([]ExecutionStep, int, error)
+	ListExecutionLogs(ctx context.Context, executionID string) ([]ExecutionLog, error)
+	ListExecutions(ctx context.Context, workflowID string, filter ExecutionFilter) ([]Execution, int, error)
+}
+
+// ErrExecutionNotFound is returned, wrapped with the ID, when an execution does not exist
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// Execution is a persisted workflow execution
+type Execution struct {
+	ID         string
+	WorkflowID string
+	Status     string
+	Input      []byte
+	Result     []byte
+	ReplayOf   null.String
+	Labels     []byte
+	ExecutedAt time.Time
+	Steps      []ExecutionStep
+	Logs       []ExecutionLog
+}
+
+// ExecutionStep is a persisted step of an execution
+type ExecutionStep struct {
+	Position    int
+	NodeID      string
+	NodeType    string
+	Status      string
+	Label       null.String
+	Description null.String
+	Error       null.String
+	Output      []byte
+	RawResponse null.String
+	Warnings    []byte
+}
+
+// ExecutionLog is a persisted log entry written during an execution
+type ExecutionLog struct {
+	Position   int
+	Level      string
+	NodeID     null.String
+	Message    string
+	Attributes []byte
+	LoggedAt   time.Time
+}
+
+// StepFilter narrows and paginates the steps returned for an execution.
+// Empty fields do not filter; a zero Limit returns every matching step.
+type StepFilter struct {
+	Status   string
+	NodeType string
+	Limit    int
+	Offset   int
+}
+
+// ExecutionFilter narrows and paginates the executions listed for a workflow.
+// Labels must all be present on an execution; a zero Limit returns every match.
+type ExecutionFilter struct {
+	Labels map[string]string
+	Limit  int
+	Offset int
+}
+
+// ExecutionRepository handles database operations for workflow executions
+type ExecutionRepository struct {
+	db *sql.DB
+}
+
+// NewExecutionRepository creates a new execution repository
+func NewExecutionRepository(db *sql.DB) *ExecutionRepository {
+	return &ExecutionRepository{
+		db: db,
+	}
+}
+
+// CreateExecution stores an execution with its input, result and steps
+func (r *ExecutionRepository) CreateExecution(ctx context.Context, execution *Execution) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create execution: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	labels := execution.Labels
+	if labels == nil {
+		labels = []byte(`{}`)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO workflow_executions (id, workflow_id, status, input, result, replay_of, labels, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		execution.ID, execution.WorkflowID, execution.Status,
+		execution.Input, execution.Result, execution.ReplayOf, labels, execution.ExecutedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	for _, step := range execution.Steps {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO workflow_execution_steps (execution_id, position, node_id, node_type, status, label, description, error, output, raw_response, warnings)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			execution.ID, step.Position, step.NodeID, step.NodeType, step.Status,
+			step.Label, step.Description, step.Error, step.Output, step.RawResponse, step.Warnings,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create execution step: %w", err)
+		}
+	}
+
+	for _, entry := range execution.Logs {
+		attributes := entry.Attributes
+		if attributes == nil {
+			attributes = []byte(`{}`)
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO workflow_execution_logs (execution_id, position, level, node_id, message, attributes, logged_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			execution.ID, entry.Position, entry.Level, entry.NodeID, entry.Message, attributes, entry.LoggedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create execution log: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	return nil
+}
+
+// GetExecutionByID retrieves an execution belonging to the given workflow
+func (r *ExecutionRepository) GetExecutionByID(ctx context.Context, workflowID string, executionID string) (*Execution, error) {
+	execution := &Execution{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, workflow_id, status, input, result, replay_of, labels, executed_at
+		FROM workflow_executions
+		WHERE id = $1 AND workflow_id = $2`,
+		executionID, workflowID,
+	).Scan(
+		&execution.ID, &execution.WorkflowID, &execution.Status,
+		&execution.Input, &execution.Result, &execution.ReplayOf, &execution.Labels, &execution.ExecutedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrExecutionNotFound, executionID)
+		}
+		return nil, fmt.Errorf("failed to fetch execution: %w", err)
+	}
+
+	return execution, nil
+}
+
+// ListExecutionSteps returns a page of an execution's steps in execution order,
+// together with the total number of steps matching the filter
+func (r *ExecutionRepository) ListExecutionSteps(ctx context.Context, executionID string, filter StepFilter) ([]ExecutionStep, int, error) {
+	// Build the shared WHERE clause
+	conditions := []string{"execution_id = $1"}
+	args := []any{executionID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.NodeType != "" {
+		args = append(args, filter.NodeType)
+		conditions = append(conditions, fmt.Sprintf("node_type = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	// Count all matching steps so clients can paginate
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM workflow_execution_steps WHERE `+where,
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count execution steps: %w", err)
+	}
+
+	query := `SELECT position, node_id, node_type, status, label, description, error, output, raw_response, warnings
+		FROM workflow_execution_steps
+		WHERE ` + where + `
+		ORDER BY position`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch execution steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := []ExecutionStep{}
+	for rows.Next() {
+		var step ExecutionStep
+		if err := rows.Scan(
+			&step.Position, &step.NodeID, &step.NodeType, &step.Status,
+			&step.Label, &step.Description, &step.Error, &step.Output, &step.RawResponse, &step.Warnings,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to read execution step: %w", err)
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch execution steps: %w", err)
+	}
+
+	return steps, total, nil
+}
+
+// ListExecutionLogs returns every log entry of an execution in the order it was written
+func (r *ExecutionRepository) ListExecutionLogs(ctx context.Context, executionID string) ([]ExecutionLog, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT position, level, node_id, message, attributes, logged_at
+		FROM workflow_execution_logs
+		WHERE execution_id = $1
+		ORDER BY position`,
+		executionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch execution logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []ExecutionLog{}
+	for rows.Next() {
+		var entry ExecutionLog
+		if err := rows.Scan(
+			&entry.Position, &entry.Level, &entry.NodeID, &entry.Message, &entry.Attributes, &entry.LoggedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read execution log: %w", err)
+		}
+		logs = append(logs, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch execution logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// ListExecutions returns a page of a workflow's executions, newest first, together with
+// the total number matching the filter. Input and result are not loaded.
+func (r *ExecutionRepository) ListExecutions(ctx context.Context, workflowID string, filter ExecutionFilter) ([]Execution, int, error) {
+	// Build the shared WHERE clause
+	conditions := []string{"workflow_id = $1"}
+	args := []any{workflowID}
+	if len(filter.Labels) > 0 {
+		labels, err := json.Marshal(filter.Labels)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode label filter: %w", err)
+		}
+		args = append(args, labels)
+		conditions = append(conditions, fmt.Sprintf("labels @> $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	// Count all matching executions so clients can paginate
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM workflow_executions WHERE `+where,
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count executions: %w", err)
+	}
+
+	query := `SELECT id, workflow_id, status, replay_of, labels, executed_at
+		FROM workflow_executions
+		WHERE ` + where + `
+		ORDER BY executed_at DESC, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch executions: %w", err)
+	}
+	defer rows.Close()
+
+	executions := []Execution{}
+	for rows.Next() {
+		var execution Execution
+		if err := rows.Scan(
+			&execution.ID, &execution.WorkflowID, &execution.Status,
+			&execution.ReplayOf, &execution.Labels, &execution.ExecutedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to read execution: %w", err)
+		}
+		executions = append(executions, execution)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch executions: %w", err)
+	}
+
+	return executions, total, nil
+}
@@ -0,0 +1,601 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateExecution(t *testing.T) {
+	executedAt := time.Now()
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		execution *Execution
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		errorContains string
+	}{
+		"success": {
+			execution: &Execution{
+				ID:         "exec-1",
+				WorkflowID: "workflow-1",
+				Status:     "completed",
+				Input:      []byte(`{}`),
+				Result:     []byte(`{"status":"completed"}`),
+				ExecutedAt: executedAt,
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WithArgs("exec-1", "workflow-1", "completed", []byte(`{}`), []byte(`{"status":"completed"}`), null.String{}, []byte(`{}`), executedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+
+		"success_with_replay_reference": {
+			execution: &Execution{
+				ID:         "exec-2",
+				WorkflowID: "workflow-1",
+				Status:     "completed",
+				Input:      []byte(`{}`),
+				Result:     []byte(`{}`),
+				ReplayOf:   null.StringFrom("exec-1"),
+				Labels:     []byte(`{"trigger":"cron"}`),
+				ExecutedAt: executedAt,
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WithArgs("exec-2", "workflow-1", "completed", []byte(`{}`), []byte(`{}`), null.StringFrom("exec-1"), []byte(`{"trigger":"cron"}`), executedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+
+		"success_with_steps": {
+			execution: &Execution{
+				ID:         "exec-3",
+				WorkflowID: "workflow-1",
+				Status:     "failed",
+				Input:      []byte(`{}`),
+				Result:     []byte(`{}`),
+				ExecutedAt: executedAt,
+				Steps: []ExecutionStep{
+					{Position: 0, NodeID: "start", NodeType: "start", Status: "completed", Output: []byte(`{}`)},
+					{Position: 1, NodeID: "weather-api", NodeType: "integration", Status: "failed", Error: null.StringFrom("timeout"), Output: []byte(`{}`), RawResponse: null.StringFrom(`{"error":"upstream timeout"}`)},
+				},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_steps`).
+					WithArgs("exec-3", 0, "start", "start", "completed", null.String{}, null.String{}, null.String{}, []byte(`{}`), null.String{}, []byte(nil)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_steps`).
+					WithArgs("exec-3", 1, "weather-api", "integration", "failed", null.String{}, null.String{}, null.StringFrom("timeout"), []byte(`{}`), null.StringFrom(`{"error":"upstream timeout"}`), []byte(nil)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+
+		"success_with_logs": {
+			execution: &Execution{
+				ID:         "exec-6",
+				WorkflowID: "workflow-1",
+				Status:     "completed",
+				Input:      []byte(`{}`),
+				Result:     []byte(`{}`),
+				ExecutedAt: executedAt,
+				Steps:      []ExecutionStep{{Position: 0, NodeID: "start", NodeType: "start", Status: "completed", Output: []byte(`{}`)}},
+				Logs: []ExecutionLog{
+					{Position: 0, Level: "WARN", NodeID: null.StringFrom("weather-api"), Message: "Retrying", Attributes: []byte(`{"attempt":1}`), LoggedAt: executedAt},
+					{Position: 1, Level: "INFO", Message: "Done", LoggedAt: executedAt},
+				},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_steps`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_logs`).
+					WithArgs("exec-6", 0, "WARN", null.StringFrom("weather-api"), "Retrying", []byte(`{"attempt":1}`), executedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_logs`).
+					WithArgs("exec-6", 1, "INFO", null.String{}, "Done", []byte(`{}`), executedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+
+		"log_insert_error_rolls_back": {
+			execution: &Execution{
+				ID:         "exec-7",
+				ExecutedAt: executedAt,
+				Logs:       []ExecutionLog{{Level: "INFO", Message: "Done", LoggedAt: executedAt}},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_logs`).
+					WillReturnError(errors.New("constraint violation"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to create execution log",
+		},
+
+		"step_insert_error_rolls_back": {
+			execution: &Execution{
+				ID:         "exec-4",
+				ExecutedAt: executedAt,
+				Steps:      []ExecutionStep{{NodeID: "start"}},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_execution_steps`).
+					WillReturnError(errors.New("constraint violation"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to create execution step",
+		},
+
+		"database_error": {
+			execution: &Execution{ID: "exec-5", ExecutedAt: executedAt},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO workflow_executions`).
+					WillReturnError(errors.New("database connection lost"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to create execution",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewExecutionRepository(db)
+
+			// Execute the function
+			err = repo.CreateExecution(context.Background(), tc.execution)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGetExecutionByID(t *testing.T) {
+	executedAt := time.Now()
+	columns := []string{"id", "workflow_id", "status", "input", "result", "replay_of", "labels", "executed_at"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID  string
+		executionID string
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedExecution *Execution
+		errorContains     string
+	}{
+		"success": {
+			workflowID:  "workflow-1",
+			executionID: "exec-1",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).AddRow(
+					"exec-1", "workflow-1", "completed", []byte(`{"formData":{}}`), []byte(`{}`), "exec-0", []byte(`{"trigger":"cron"}`), executedAt,
+				)
+				mock.ExpectQuery(`SELECT .* FROM workflow_executions WHERE id = \$1 AND workflow_id = \$2`).
+					WithArgs("exec-1", "workflow-1").
+					WillReturnRows(rows)
+			},
+			expectedExecution: &Execution{
+				ID:         "exec-1",
+				WorkflowID: "workflow-1",
+				Status:     "completed",
+				Input:      []byte(`{"formData":{}}`),
+				Result:     []byte(`{}`),
+				ReplayOf:   null.StringFrom("exec-0"),
+				Labels:     []byte(`{"trigger":"cron"}`),
+				ExecutedAt: executedAt,
+			},
+		},
+
+		"execution_not_found": {
+			workflowID:  "workflow-1",
+			executionID: "missing",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM workflow_executions`).
+					WithArgs("missing", "workflow-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			errorContains: "execution not found: missing",
+		},
+
+		"database_error": {
+			workflowID:  "workflow-1",
+			executionID: "exec-1",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM workflow_executions`).
+					WithArgs("exec-1", "workflow-1").
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to fetch execution",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewExecutionRepository(db)
+
+			// Execute the function
+			execution, err := repo.GetExecutionByID(context.Background(), tc.workflowID, tc.executionID)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Nil(t, execution)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedExecution, execution)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListExecutionSteps(t *testing.T) {
+	columns := []string{"position", "node_id", "node_type", "status", "label", "description", "error", "output", "raw_response", "warnings"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		filter StepFilter
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedSteps []ExecutionStep
+		expectedTotal int
+		errorContains string
+	}{
+		"all_steps": {
+			filter: StepFilter{},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workflow_execution_steps WHERE execution_id = \$1$`).
+					WithArgs("exec-1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(`SELECT position, .* WHERE execution_id = \$1\s+ORDER BY position$`).
+					WithArgs("exec-1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(0, "start", "start", "completed", nil, nil, nil, []byte(`{}`), nil, nil).
+						AddRow(1, "form", "form", "completed", "Form", nil, nil, []byte(`{"name":"Alice"}`), nil, []byte(`["Expected input field not found in executeVars: field=email"]`)))
+			},
+			expectedSteps: []ExecutionStep{
+				{Position: 0, NodeID: "start", NodeType: "start", Status: "completed", Output: []byte(`{}`)},
+				{Position: 1, NodeID: "form", NodeType: "form", Status: "completed", Label: null.StringFrom("Form"), Output: []byte(`{"name":"Alice"}`), Warnings: []byte(`["Expected input field not found in executeVars: field=email"]`)},
+			},
+			expectedTotal: 2,
+		},
+
+		"filtered_and_paginated": {
+			filter: StepFilter{Status: "failed", NodeType: "integration", Limit: 10, Offset: 20},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workflow_execution_steps WHERE execution_id = \$1 AND status = \$2 AND node_type = \$3$`).
+					WithArgs("exec-1", "failed", "integration").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery(`WHERE execution_id = \$1 AND status = \$2 AND node_type = \$3\s+ORDER BY position LIMIT \$4 OFFSET \$5$`).
+					WithArgs("exec-1", "failed", "integration", 10, 20).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(40, "weather-api", "integration", "failed", nil, nil, "timeout", []byte(`{}`), `{"error":"upstream timeout"}`, nil))
+			},
+			expectedSteps: []ExecutionStep{
+				{Position: 40, NodeID: "weather-api", NodeType: "integration", Status: "failed", Error: null.StringFrom("timeout"), Output: []byte(`{}`), RawResponse: null.StringFrom(`{"error":"upstream timeout"}`)},
+			},
+			expectedTotal: 21,
+		},
+
+		"no_matching_steps": {
+			filter: StepFilter{Status: "skipped"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT`).
+					WithArgs("exec-1", "skipped").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(`SELECT position`).
+					WithArgs("exec-1", "skipped").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedSteps: []ExecutionStep{},
+			expectedTotal: 0,
+		},
+
+		"count_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT`).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to count execution steps",
+		},
+
+		"query_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT position`).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to fetch execution steps",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewExecutionRepository(db)
+
+			// Execute the function
+			steps, total, err := repo.ListExecutionSteps(context.Background(), "exec-1", tc.filter)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Nil(t, steps)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSteps, steps)
+				assert.Equal(t, tc.expectedTotal, total)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListExecutions(t *testing.T) {
+	executedAt := time.Now()
+	columns := []string{"id", "workflow_id", "status", "replay_of", "labels", "executed_at"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		filter ExecutionFilter
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedExecutions []Execution
+		expectedTotal      int
+		errorContains      string
+	}{
+		"all_executions": {
+			filter: ExecutionFilter{},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workflow_executions WHERE workflow_id = \$1$`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT id, .* WHERE workflow_id = \$1\s+ORDER BY executed_at DESC, id$`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("exec-1", "workflow-1", "completed", nil, []byte(`{}`), executedAt))
+			},
+			expectedExecutions: []Execution{
+				{ID: "exec-1", WorkflowID: "workflow-1", Status: "completed", Labels: []byte(`{}`), ExecutedAt: executedAt},
+			},
+			expectedTotal: 1,
+		},
+
+		"filtered_by_labels_and_paginated": {
+			filter: ExecutionFilter{Labels: map[string]string{"trigger": "cron"}, Limit: 10, Offset: 20},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM workflow_executions WHERE workflow_id = \$1 AND labels @> \$2$`).
+					WithArgs("workflow-1", []byte(`{"trigger":"cron"}`)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery(`WHERE workflow_id = \$1 AND labels @> \$2\s+ORDER BY executed_at DESC, id LIMIT \$3 OFFSET \$4$`).
+					WithArgs("workflow-1", []byte(`{"trigger":"cron"}`), 10, 20).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("exec-2", "workflow-1", "failed", "exec-1", []byte(`{"trigger":"cron"}`), executedAt))
+			},
+			expectedExecutions: []Execution{
+				{ID: "exec-2", WorkflowID: "workflow-1", Status: "failed", ReplayOf: null.StringFrom("exec-1"), Labels: []byte(`{"trigger":"cron"}`), ExecutedAt: executedAt},
+			},
+			expectedTotal: 21,
+		},
+
+		"count_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT`).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to count executions",
+		},
+
+		"query_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`SELECT id`).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to fetch executions",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewExecutionRepository(db)
+
+			// Execute the function
+			executions, total, err := repo.ListExecutions(context.Background(), "workflow-1", tc.filter)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedExecutions, executions)
+				assert.Equal(t, tc.expectedTotal, total)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListExecutionLogs(t *testing.T) {
+	columns := []string{"position", "level", "node_id", "message", "attributes", "logged_at"}
+	loggedAt := time.Now()
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedLogs  []ExecutionLog
+		errorContains string
+	}{
+		"all_logs": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT position, .* FROM workflow_execution_logs\s+WHERE execution_id = \$1\s+ORDER BY position$`).
+					WithArgs("exec-1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(0, "DEBUG", nil, "Ignoring input for reserved variable", []byte(`{"variable":"_workflowId"}`), loggedAt).
+						AddRow(1, "ERROR", "weather-api", "Failed to call API", []byte(`{}`), loggedAt))
+			},
+			expectedLogs: []ExecutionLog{
+				{Position: 0, Level: "DEBUG", Message: "Ignoring input for reserved variable", Attributes: []byte(`{"variable":"_workflowId"}`), LoggedAt: loggedAt},
+				{Position: 1, Level: "ERROR", NodeID: null.StringFrom("weather-api"), Message: "Failed to call API", Attributes: []byte(`{}`), LoggedAt: loggedAt},
+			},
+		},
+
+		"no_logs": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT position`).
+					WithArgs("exec-1").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedLogs: []ExecutionLog{},
+		},
+
+		"query_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT position`).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to fetch execution logs",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewExecutionRepository(db)
+
+			// Execute the function
+			logs, err := repo.ListExecutionLogs(context.Background(), "exec-1")
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Nil(t, logs)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedLogs, logs)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	db "workflow-code-test/api/pkg/db"
 	models "workflow-code-test/api/pkg/db/models"
 
+	null "github.com/aarondl/null/v8"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -49,3 +51,32 @@ func (mr *MockWorkFlowDBMockRecorder) GetWorkflowByID(ctx, workflowID interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflowByID", reflect.TypeOf((*MockWorkFlowDB)(nil).GetWorkflowByID), ctx, workflowID)
 }
+
+// ListWorkflowIDs mocks base method.
+func (m *MockWorkFlowDB) ListWorkflowIDs(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkflowIDs", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkflowIDs indicates an expected call of ListWorkflowIDs.
+func (mr *MockWorkFlowDBMockRecorder) ListWorkflowIDs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkflowIDs", reflect.TypeOf((*MockWorkFlowDB)(nil).ListWorkflowIDs), ctx)
+}
+
+// UpdateNodeData mocks base method.
+func (m *MockWorkFlowDB) UpdateNodeData(ctx context.Context, workflowID, nodeID string, data []byte, expectedUpdatedAt null.Time, version *db.NodeVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNodeData", ctx, workflowID, nodeID, data, expectedUpdatedAt, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNodeData indicates an expected call of UpdateNodeData.
+func (mr *MockWorkFlowDBMockRecorder) UpdateNodeData(ctx, workflowID, nodeID, data, expectedUpdatedAt, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeData", reflect.TypeOf((*MockWorkFlowDB)(nil).UpdateNodeData), ctx, workflowID, nodeID, data, expectedUpdatedAt, version)
+}
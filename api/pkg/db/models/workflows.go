@@ -24,58 +24,79 @@ import (
 
 // Workflow is an object representing the database table.
 type Workflow struct {
-	ID          string      `boil:"id" json:"id" toml:"id" yaml:"id"`
-	Name        string      `boil:"name" json:"name" toml:"name" yaml:"name"`
-	Description null.String `boil:"description" json:"description,omitempty" toml:"description" yaml:"description,omitempty"`
-	CreatedAt   null.Time   `boil:"created_at" json:"created_at,omitempty" toml:"created_at" yaml:"created_at,omitempty"`
-	UpdatedAt   null.Time   `boil:"updated_at" json:"updated_at,omitempty" toml:"updated_at" yaml:"updated_at,omitempty"`
+	ID             string      `boil:"id" json:"id" toml:"id" yaml:"id"`
+	Name           string      `boil:"name" json:"name" toml:"name" yaml:"name"`
+	Description    null.String `boil:"description" json:"description,omitempty" toml:"description" yaml:"description,omitempty"`
+	CreatedAt      null.Time   `boil:"created_at" json:"created_at,omitempty" toml:"created_at" yaml:"created_at,omitempty"`
+	UpdatedAt      null.Time   `boil:"updated_at" json:"updated_at,omitempty" toml:"updated_at" yaml:"updated_at,omitempty"`
+	Variables      null.JSON   `boil:"variables" json:"variables,omitempty" toml:"variables" yaml:"variables,omitempty"`
+	ExecutionOrder null.JSON   `boil:"execution_order" json:"execution_order,omitempty" toml:"execution_order" yaml:"execution_order,omitempty"`
+	Cacheable      null.Bool   `boil:"cacheable" json:"cacheable,omitempty" toml:"cacheable" yaml:"cacheable,omitempty"`
 
 	R *workflowR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L workflowL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var WorkflowColumns = struct {
-	ID          string
-	Name        string
-	Description string
-	CreatedAt   string
-	UpdatedAt   string
+	ID             string
+	Name           string
+	Description    string
+	CreatedAt      string
+	UpdatedAt      string
+	Variables      string
+	ExecutionOrder string
+	Cacheable      string
 }{
-	ID:          "id",
-	Name:        "name",
-	Description: "description",
-	CreatedAt:   "created_at",
-	UpdatedAt:   "updated_at",
+	ID:             "id",
+	Name:           "name",
+	Description:    "description",
+	CreatedAt:      "created_at",
+	UpdatedAt:      "updated_at",
+	Variables:      "variables",
+	ExecutionOrder: "execution_order",
+	Cacheable:      "cacheable",
 }
 
 var WorkflowTableColumns = struct {
-	ID          string
-	Name        string
-	Description string
-	CreatedAt   string
-	UpdatedAt   string
+	ID             string
+	Name           string
+	Description    string
+	CreatedAt      string
+	UpdatedAt      string
+	Variables      string
+	ExecutionOrder string
+	Cacheable      string
 }{
-	ID:          "workflows.id",
-	Name:        "workflows.name",
-	Description: "workflows.description",
-	CreatedAt:   "workflows.created_at",
-	UpdatedAt:   "workflows.updated_at",
+	ID:             "workflows.id",
+	Name:           "workflows.name",
+	Description:    "workflows.description",
+	CreatedAt:      "workflows.created_at",
+	UpdatedAt:      "workflows.updated_at",
+	Variables:      "workflows.variables",
+	ExecutionOrder: "workflows.execution_order",
+	Cacheable:      "workflows.cacheable",
 }
 
 // Generated where
 
 var WorkflowWhere = struct {
-	ID          whereHelperstring
-	Name        whereHelperstring
-	Description whereHelpernull_String
-	CreatedAt   whereHelpernull_Time
-	UpdatedAt   whereHelpernull_Time
+	ID             whereHelperstring
+	Name           whereHelperstring
+	Description    whereHelpernull_String
+	CreatedAt      whereHelpernull_Time
+	UpdatedAt      whereHelpernull_Time
+	Variables      whereHelpernull_JSON
+	ExecutionOrder whereHelpernull_JSON
+	Cacheable      whereHelpernull_Bool
 }{
-	ID:          whereHelperstring{field: "\"workflows\".\"id\""},
-	Name:        whereHelperstring{field: "\"workflows\".\"name\""},
-	Description: whereHelpernull_String{field: "\"workflows\".\"description\""},
-	CreatedAt:   whereHelpernull_Time{field: "\"workflows\".\"created_at\""},
-	UpdatedAt:   whereHelpernull_Time{field: "\"workflows\".\"updated_at\""},
+	ID:             whereHelperstring{field: "\"workflows\".\"id\""},
+	Name:           whereHelperstring{field: "\"workflows\".\"name\""},
+	Description:    whereHelpernull_String{field: "\"workflows\".\"description\""},
+	CreatedAt:      whereHelpernull_Time{field: "\"workflows\".\"created_at\""},
+	UpdatedAt:      whereHelpernull_Time{field: "\"workflows\".\"updated_at\""},
+	Variables:      whereHelpernull_JSON{field: "\"workflows\".\"variables\""},
+	ExecutionOrder: whereHelpernull_JSON{field: "\"workflows\".\"execution_order\""},
+	Cacheable:      whereHelpernull_Bool{field: "\"workflows\".\"cacheable\""},
 }
 
 // WorkflowRels is where relationship names are stored.
@@ -134,9 +155,9 @@ func (r *workflowR) GetWorkflowNodes() WorkflowNodeSlice {
 type workflowL struct{}
 
 var (
-	workflowAllColumns            = []string{"id", "name", "description", "created_at", "updated_at"}
-	workflowColumnsWithoutDefault = []string{"name"}
-	workflowColumnsWithDefault    = []string{"id", "description", "created_at", "updated_at"}
+	workflowAllColumns            = []string{"id", "name", "description", "created_at", "updated_at", "variables", "execution_order", "cacheable"}
+	workflowColumnsWithoutDefault = []string{"name", "execution_order", "cacheable"}
+	workflowColumnsWithDefault    = []string{"id", "description", "created_at", "updated_at", "variables"}
 	workflowPrimaryKeyColumns     = []string{"id"}
 	workflowGeneratedColumns      = []string{}
 )
@@ -17,7 +17,12 @@ type Config struct {
 }
 
 func Connect(ctx context.Context, connStr string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, connStr)
+	config, err := parseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pgx pool: %w", err)
 	}
@@ -28,3 +33,39 @@ func Connect(ctx context.Context, connStr string) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// parseConfig parses a database URL into a pool config. pgx caches prepared statements per
+// connection unless the URL sets default_query_exec_mode, e.g. to simple_protocol behind
+// PgBouncer, so the mode is left to the URL rather than overridden here.
+func parseConfig(connStr string) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	return config, nil
+}
+
+// PoolStats is a point-in-time snapshot of a connection pool
+type PoolStats struct {
+	TotalConns        int32 `json:"totalConns"`
+	IdleConns         int32 `json:"idleConns"`
+	AcquiredConns     int32 `json:"acquiredConns"`
+	MaxConns          int32 `json:"maxConns"`
+	AcquireCount      int64 `json:"acquireCount"`
+	EmptyAcquireCount int64 `json:"emptyAcquireCount"`
+	AcquireDurationMs int64 `json:"acquireDurationMs"`
+}
+
+// NewPoolStats snapshots the connection counts of pool
+func NewPoolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		TotalConns:        stat.TotalConns(),
+		IdleConns:         stat.IdleConns(),
+		AcquiredConns:     stat.AcquiredConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		AcquireDurationMs: stat.AcquireDuration().Milliseconds(),
+	}
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type VersionDB interface {
+	CreateWorkflowVersion(ctx context.Context, workflowID string, previous []byte, definition []byte) (int, error)
+	GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*WorkflowVersion, error)
+}
+
+// ErrWorkflowVersionNotFound is returned, wrapped with the version, when a workflow version does not exist
+var ErrWorkflowVersionNotFound = errors.New("workflow version not found")
+
+// WorkflowVersion is a stored snapshot of a workflow definition
+type WorkflowVersion struct {
+	WorkflowID string
+	Version    int
+	Definition []byte
+	CreatedAt  time.Time
+}
+
+// VersionRepository handles database operations for workflow version snapshots
+type VersionRepository struct {
+	db *sql.DB
+}
+
+// NewVersionRepository creates a new version repository
+func NewVersionRepository(db *sql.DB) *VersionRepository {
+	return &VersionRepository{
+		db: db,
+	}
+}
+
+// CreateWorkflowVersion stores definition as the workflow's next version and returns its number.
+// When the workflow has no versions yet, previous is stored first as version 1, so the
+// definition from before the first change can be compared too. Snapshots of one workflow are
+// numbered one at a time by locking its row.
+func (r *VersionRepository) CreateWorkflowVersion(ctx context.Context, workflowID string, previous []byte, definition []byte) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create workflow version: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockWorkflow(ctx, tx, workflowID); err != nil {
+		return 0, err
+	}
+	latest, err := insertWorkflowVersion(ctx, tx, workflowID, previous, definition)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to create workflow version: %w", err)
+	}
+
+	return latest, nil
+}
+
+// lockWorkflow locks the workflow's row until tx ends, so its versions are numbered one at a time
+func lockWorkflow(ctx context.Context, tx *sql.Tx, workflowID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM workflows WHERE id = $1 FOR UPDATE`, workflowID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+		}
+		return fmt.Errorf("failed to lock workflow: %w", err)
+	}
+	return nil
+}
+
+// insertWorkflowVersion stores definition as the next version of a workflow locked by
+// lockWorkflow, preceded by previous when it has no versions yet, and returns its number
+func insertWorkflowVersion(ctx context.Context, tx *sql.Tx, workflowID string, previous []byte, definition []byte) (int, error) {
+	var latest int
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM workflow_versions WHERE workflow_id = $1`,
+		workflowID,
+	).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latest workflow version: %w", err)
+	}
+
+	snapshots := [][]byte{definition}
+	if latest == 0 && previous != nil {
+		snapshots = [][]byte{previous, definition}
+	}
+	for _, snapshot := range snapshots {
+		latest++
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO workflow_versions (workflow_id, version, definition)
+			VALUES ($1, $2, $3)`,
+			workflowID, latest, snapshot,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create workflow version: %w", err)
+		}
+	}
+	return latest, nil
+}
+
+// GetWorkflowVersion retrieves one stored version of a workflow
+func (r *VersionRepository) GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*WorkflowVersion, error) {
+	snapshot := &WorkflowVersion{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT workflow_id, version, definition, created_at
+		FROM workflow_versions
+		WHERE workflow_id = $1 AND version = $2`,
+		workflowID, version,
+	).Scan(&snapshot.WorkflowID, &snapshot.Version, &snapshot.Definition, &snapshot.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrWorkflowVersionNotFound, version)
+		}
+		return nil, fmt.Errorf("failed to fetch workflow version: %w", err)
+	}
+
+	return snapshot, nil
+}
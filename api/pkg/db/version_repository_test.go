@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWorkflowVersion(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		previous   []byte
+		definition []byte
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedVersion int
+		errorContains   string
+	}{
+		"first_change_stores_previous_as_version_one": {
+			previous:   []byte(`{"name":"before"}`),
+			definition: []byte(`{"name":"after"}`),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows WHERE id = \$1 FOR UPDATE`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("workflow-1"))
+				mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM workflow_versions`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WithArgs("workflow-1", 1, []byte(`{"name":"before"}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WithArgs("workflow-1", 2, []byte(`{"name":"after"}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedVersion: 2,
+		},
+
+		"later_change_appends_next_version": {
+			previous:   []byte(`{"name":"before"}`),
+			definition: []byte(`{"name":"after"}`),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("workflow-1"))
+				mock.ExpectQuery(`SELECT COALESCE`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(4))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WithArgs("workflow-1", 5, []byte(`{"name":"after"}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedVersion: 5,
+		},
+
+		"workflow_not_found": {
+			definition: []byte(`{}`),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows`).
+					WithArgs("workflow-1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			errorContains: "workflow not found: workflow-1",
+		},
+
+		"insert_error_rolls_back": {
+			definition: []byte(`{}`),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("workflow-1"))
+				mock.ExpectQuery(`SELECT COALESCE`).
+					WithArgs("workflow-1").
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WillReturnError(errors.New("constraint violation"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to create workflow version",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewVersionRepository(db)
+
+			// Execute the function
+			version, err := repo.CreateWorkflowVersion(context.Background(), "workflow-1", tc.previous, tc.definition)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVersion, version)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGetWorkflowVersion(t *testing.T) {
+	createdAt := time.Now()
+	columns := []string{"workflow_id", "version", "definition", "created_at"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedVersion *WorkflowVersion
+		errorContains   string
+	}{
+		"success": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM workflow_versions WHERE workflow_id = \$1 AND version = \$2`).
+					WithArgs("workflow-1", 2).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("workflow-1", 2, []byte(`{"name":"after"}`), createdAt))
+			},
+			expectedVersion: &WorkflowVersion{WorkflowID: "workflow-1", Version: 2, Definition: []byte(`{"name":"after"}`), CreatedAt: createdAt},
+		},
+
+		"version_not_found": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM workflow_versions`).
+					WithArgs("workflow-1", 2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			errorContains: "workflow version not found: 2",
+		},
+
+		"database_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM workflow_versions`).
+					WithArgs("workflow-1", 2).
+					WillReturnError(errors.New("database connection lost"))
+			},
+			errorContains: "failed to fetch workflow version",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewVersionRepository(db)
+
+			// Execute the function
+			version, err := repo.GetWorkflowVersion(context.Background(), "workflow-1", 2)
+
+			// Assert results
+			if tc.errorContains != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Nil(t, version)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVersion, version)
+			}
+
+			// Ensure all expectations were met
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,20 +3,50 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"workflow-code-test/api/pkg/db/models"
 
+	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/queries/qm"
 )
 
 type WorkFlowDB interface {
 	GetWorkflowByID(ctx context.Context, workflowID string) (*models.Workflow, error)
+	ListWorkflowIDs(ctx context.Context) ([]string, error)
+	UpdateNodeData(ctx context.Context, workflowID string, nodeID string, data []byte, expectedUpdatedAt null.Time, version *NodeVersion) error
+}
+
+// NodeVersion is the workflow version stored with a node update. Previous is the definition
+// from before the update, stored first when the workflow has no versions yet. Snapshot encodes
+// the workflow as read back after the update.
+type NodeVersion struct {
+	Previous []byte
+	Snapshot func(workflow *models.Workflow) ([]byte, error)
+}
+
+// ErrWorkflowNotFound is returned, wrapped with the ID, when a workflow does not exist
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+// ErrNodeNotFound is returned, wrapped with the ID, when a node does not exist in its workflow
+var ErrNodeNotFound = errors.New("node not found")
+
+// ErrNodeConflict is returned when a node changed between being read and being updated
+type ErrNodeConflict struct {
+	NodeID string
+}
+
+func (e ErrNodeConflict) Error() string {
+	return "node was modified concurrently: " + e.NodeID
 }
 
 // WorkflowRepository handles database operations for workflows
 type WorkflowRepository struct {
 	db *sql.DB
+	// readDB serves reads when a replica is configured; nil means reads use db
+	readDB *sql.DB
 }
 
 // NewWorkflowRepository creates a new workflow repository
@@ -26,21 +56,141 @@ func NewWorkflowRepository(db *sql.DB) *WorkflowRepository {
 	}
 }
 
-// GetWorkflowByID retrieves a workflow with all its nodes and edges
+// NewWorkflowRepositoryWithReplica creates a workflow repository that reads from replica
+// and writes to primary
+func NewWorkflowRepositoryWithReplica(primary *sql.DB, replica *sql.DB) *WorkflowRepository {
+	return &WorkflowRepository{
+		db:     primary,
+		readDB: replica,
+	}
+}
+
+// primaryKey marks a context whose reads must not be served by a lagging replica
+type primaryKey struct{}
+
+// WithPrimary returns a context under which repository reads go to the primary,
+// e.g. when the result feeds a write
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// reader returns the handle reads should use for ctx
+func (r *WorkflowRepository) reader(ctx context.Context) *sql.DB {
+	if r.readDB == nil {
+		return r.db
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return r.db
+	}
+	return r.readDB
+}
+
+// GetWorkflowByID retrieves a workflow with all its nodes and edges.
+// It reads from the replica when one is configured, unless ctx was marked WithPrimary.
 func (r *WorkflowRepository) GetWorkflowByID(ctx context.Context, workflowID string) (*models.Workflow, error) {
 	// Fetch the workflow with related nodes and edges
 	workflow, err := models.Workflows(
 		qm.Where("id = ?", workflowID),
 		qm.Load(models.WorkflowRels.WorkflowNodes),
 		qm.Load(models.WorkflowRels.WorkflowEdges),
-	).One(ctx, r.db)
+	).One(ctx, r.reader(ctx))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("workflow not found: %s", workflowID)
+			return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
 		}
 		return nil, fmt.Errorf("failed to fetch workflow: %w", err)
 	}
 
 	return workflow, nil
 }
+
+// ListWorkflowIDs returns the IDs of all workflows, ordered by ID
+func (r *WorkflowRepository) ListWorkflowIDs(ctx context.Context) ([]string, error) {
+	workflows, err := models.Workflows(
+		qm.Select(models.WorkflowColumns.ID),
+		qm.OrderBy(models.WorkflowColumns.ID),
+	).All(ctx, r.reader(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	ids := make([]string, 0, len(workflows))
+	for _, workflow := range workflows {
+		ids = append(ids, workflow.ID)
+	}
+	return ids, nil
+}
+
+// UpdateNodeData replaces the data JSON of a single node in a workflow.
+// The update only applies while the node's updated_at still matches expectedUpdatedAt,
+// so a concurrent update in between is reported as ErrNodeConflict instead of being lost.
+// A non-nil version is stored in the same transaction, so the update and its version are
+// either both kept or both rolled back.
+func (r *WorkflowRepository) UpdateNodeData(ctx context.Context, workflowID string, nodeID string, data []byte, expectedUpdatedAt null.Time, version *NodeVersion) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the workflow first, so the snapshot read back below can't interleave with
+	// another versioned update
+	if version != nil {
+		if err := lockWorkflow(ctx, tx, workflowID); err != nil {
+			return err
+		}
+	}
+
+	rowsAffected, err := models.WorkflowNodes(
+		qm.Where("workflow_id = ?", workflowID),
+		qm.Where("node_id = ?", nodeID),
+		qm.Where("updated_at IS NOT DISTINCT FROM ?", expectedUpdatedAt),
+	).UpdateAll(ctx, tx, models.M{
+		models.WorkflowNodeColumns.Data:      null.JSONFrom(data),
+		models.WorkflowNodeColumns.UpdatedAt: null.TimeFrom(time.Now()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Tell a deleted node apart from one that was updated since it was read
+		exists, err := models.WorkflowNodes(
+			qm.Where("workflow_id = ?", workflowID),
+			qm.Where("node_id = ?", nodeID),
+		).Exists(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to check node: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+		}
+		return ErrNodeConflict{NodeID: nodeID}
+	}
+
+	if version != nil {
+		// Read the change back, so the snapshot also holds any earlier change to other nodes
+		workflow, err := models.Workflows(
+			qm.Where("id = ?", workflowID),
+			qm.Load(models.WorkflowRels.WorkflowNodes),
+			qm.Load(models.WorkflowRels.WorkflowEdges),
+		).One(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch workflow: %w", err)
+		}
+		definition, err := version.Snapshot(workflow)
+		if err != nil {
+			return fmt.Errorf("failed to encode workflow version: %w", err)
+		}
+		if _, err := insertWorkflowVersion(ctx, tx, workflowID, version.Previous, definition); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+
+	return nil
+}
@@ -105,7 +105,7 @@ func TestGetWorkflowByID(t *testing.T) {
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedWorkflow: nil,
-			expectedError:    nil,
+			expectedError:    ErrWorkflowNotFound,
 			errorContains:    "workflow not found: non-existent-workflow",
 		},
 
@@ -200,6 +200,9 @@ func TestGetWorkflowByID(t *testing.T) {
 			if tc.errorContains != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tc.errorContains)
+				if tc.expectedError != nil {
+					assert.ErrorIs(t, err, tc.expectedError)
+				}
 				assert.Nil(t, workflow)
 			} else if tc.expectedError != nil {
 				assert.ErrorIs(t, err, tc.expectedError)
@@ -225,6 +228,216 @@ func TestGetWorkflowByID(t *testing.T) {
 	}
 }
 
+func TestUpdateNodeData(t *testing.T) {
+	data := []byte(`{"label":"Is it hot?"}`)
+	updatedAt := null.TimeFrom(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	version := &NodeVersion{
+		Previous: []byte(`{"name":"before"}`),
+		Snapshot: func(workflow *models.Workflow) ([]byte, error) {
+			return []byte(`{"name":"` + workflow.Name + `"}`), nil
+		},
+	}
+
+	// expectReadBack expects the workflow to be read back inside the transaction
+	expectReadBack := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT .* FROM "workflows" WHERE.*id = \$1`).
+			WithArgs("test-workflow-123").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("test-workflow-123", "after"))
+		mock.ExpectQuery(`SELECT .* FROM "workflow_nodes" WHERE.*workflow_id.*`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_id"}))
+		mock.ExpectQuery(`SELECT .* FROM "workflow_edges" WHERE.*workflow_id.*`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_id"}))
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		version *NodeVersion
+
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		errorContains string
+		conflict      bool
+	}{
+		"updates_node_data": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .* WHERE \(workflow_id = \$\d\) AND \(node_id = \$\d\) AND \(updated_at IS NOT DISTINCT FROM \$\d\)`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"stores_version_with_update": {
+			version: version,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-workflow-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("test-workflow-123"))
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .*`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectReadBack(mock)
+				mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM workflow_versions`).
+					WithArgs("test-workflow-123").
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WithArgs("test-workflow-123", 1, []byte(`{"name":"before"}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WithArgs("test-workflow-123", 2, []byte(`{"name":"after"}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"version_failure_rolls_back_update": {
+			version: version,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM workflows WHERE id = \$1 FOR UPDATE`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("test-workflow-123"))
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .*`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectReadBack(mock)
+				mock.ExpectQuery(`SELECT COALESCE`).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO workflow_versions`).
+					WillReturnError(errors.New("constraint violation"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to create workflow version",
+		},
+		"node_not_found": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .*`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT .* FROM "workflow_nodes"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			errorContains: "node not found: condition",
+		},
+		"node_modified_since_read": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .*`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT .* FROM "workflow_nodes"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			errorContains: "node was modified concurrently: condition",
+			conflict:      true,
+		},
+		"database_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "workflow_nodes" SET .*`).
+					WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			errorContains: "failed to update node",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewWorkflowRepository(db)
+
+			err = repo.UpdateNodeData(context.Background(), "test-workflow-123", "condition", data, updatedAt, tc.version)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				var conflict ErrNodeConflict
+				assert.Equal(t, tc.conflict, errors.As(err, &conflict))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListWorkflowIDs(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Mock setup
+		setupMock func(mock sqlmock.Sqlmock)
+
+		// Expected results
+		expectedIDs   []string
+		errorContains string
+	}{
+		"lists_ids_in_order": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT "id" FROM "workflows" ORDER BY id`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).
+						AddRow("550e8400-e29b-41d4-a716-446655440000").
+						AddRow("6f1c2d3e-0000-4000-8000-000000000001"))
+			},
+			expectedIDs: []string{"550e8400-e29b-41d4-a716-446655440000", "6f1c2d3e-0000-4000-8000-000000000001"},
+		},
+		"no_workflows": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT "id" FROM "workflows"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expectedIDs: []string{},
+		},
+		"database_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT "id" FROM "workflows"`).
+					WillReturnError(errors.New("connection reset"))
+			},
+			errorContains: "failed to list workflows",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Setup expectations
+			tc.setupMock(mock)
+
+			// Create repository
+			repo := NewWorkflowRepository(db)
+
+			ids, err := repo.ListWorkflowIDs(context.Background())
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedIDs, ids)
+			}
+
+			// Ensure all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 // TestNewWorkflowRepository tests the constructor
 func TestNewWorkflowRepository(t *testing.T) {
 	tests := map[string]struct {
@@ -255,6 +468,69 @@ func TestNewWorkflowRepository(t *testing.T) {
 	}
 }
 
+func TestGetWorkflowByIDReadReplica(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		withReplica bool
+		ctx         context.Context
+
+		// Expected results
+		expectPrimary bool
+	}{
+		"no_replica_reads_primary": {
+			ctx:           context.Background(),
+			expectPrimary: true,
+		},
+		"replica_serves_reads": {
+			withReplica:   true,
+			ctx:           context.Background(),
+			expectPrimary: false,
+		},
+		"with_primary_bypasses_replica": {
+			withReplica:   true,
+			ctx:           WithPrimary(context.Background()),
+			expectPrimary: true,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup mock databases
+			primary, primaryMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer primary.Close()
+			replica, replicaMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer replica.Close()
+
+			// Only the expected handle sees the query
+			expected := replicaMock
+			if tc.expectPrimary {
+				expected = primaryMock
+			}
+			expected.ExpectQuery(`SELECT .* FROM "workflows" WHERE.*id = \$1`).
+				WithArgs("test-workflow-123").
+				WillReturnError(sql.ErrNoRows)
+
+			// Create repository
+			repo := NewWorkflowRepository(primary)
+			if tc.withReplica {
+				repo = NewWorkflowRepositoryWithReplica(primary, replica)
+			}
+
+			// Execute the function
+			_, err = repo.GetWorkflowByID(tc.ctx, "test-workflow-123")
+			assert.EqualError(t, err, "workflow not found: test-workflow-123")
+
+			// Ensure all expectations were met
+			assert.NoError(t, primaryMock.ExpectationsWereMet())
+			assert.NoError(t, replicaMock.ExpectationsWereMet())
+		})
+	}
+}
+
 // Benchmark test for GetWorkflowByID
 func BenchmarkGetWorkflowByID(b *testing.B) {
 	// Setup mock database
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// LogSender implements Sender by logging messages instead of delivering them
+type LogSender struct {
+	now func() time.Time
+}
+
+// NewLogSender creates a sender that records messages in the log and reports them as sent
+func NewLogSender() *LogSender {
+	return NewLogSenderWithClock(time.Now)
+}
+
+// NewLogSenderWithClock creates a log sender whose message IDs are derived from now
+func NewLogSenderWithClock(now func() time.Time) *LogSender {
+	return &LogSender{now: now}
+}
+
+// Send logs the draft and returns a generated message ID
+func (l *LogSender) Send(ctx context.Context, draft EmailDraft) (string, error) {
+	slog.InfoContext(ctx, "Email send simulated", "to", draft.To, "cc", len(draft.Cc), "bcc", len(draft.Bcc), "attachments", len(draft.Attachments), "subject", draft.Subject)
+	return fmt.Sprintf("msg_%d", l.now().Unix()), nil
+}
@@ -0,0 +1,83 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"time"
+)
+
+// EmailDraft is an email ready to be delivered
+type EmailDraft struct {
+	To          string       `json:"to"`
+	From        string       `json:"from"`
+	Cc          []string     `json:"cc,omitempty"`
+	Bcc         []string     `json:"bcc,omitempty"`
+	Subject     string       `json:"subject"`
+	Body        string       `json:"body"`
+	BodyHtml    string       `json:"bodyHtml,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Timestamp   time.Time    `json:"timestamp"`
+}
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+// Map returns the draft in the shape stored in step output. Optional fields are omitted
+// when empty and the timestamp is formatted as RFC 3339. Attachments are described by
+// filename, content type and size; their content is not included.
+func (d EmailDraft) Map() map[string]any {
+	draft := map[string]any{
+		"to":        d.To,
+		"from":      d.From,
+		"subject":   d.Subject,
+		"body":      d.Body,
+		"timestamp": d.Timestamp.Format(time.RFC3339),
+	}
+	if len(d.Cc) > 0 {
+		draft["cc"] = d.Cc
+	}
+	if len(d.Bcc) > 0 {
+		draft["bcc"] = d.Bcc
+	}
+	if d.BodyHtml != "" {
+		draft["bodyHtml"] = d.BodyHtml
+	}
+	if len(d.Attachments) > 0 {
+		attachments := make([]map[string]any, 0, len(d.Attachments))
+		for _, attachment := range d.Attachments {
+			attachments = append(attachments, map[string]any{
+				"filename":    attachment.Filename,
+				"contentType": attachment.ContentType,
+				"size":        len(attachment.Content),
+			})
+		}
+		draft["attachments"] = attachments
+	}
+	return draft
+}
+
+// Sender defines the interface for delivering email.
+// Implementations may be backed by SMTP, a transactional email API, etc.
+type Sender interface {
+	// Send delivers the draft and returns the provider's message ID
+	Send(ctx context.Context, draft EmailDraft) (string, error)
+}
+
+// IsTransient reports whether a send failure is worth retrying.
+// SMTP 4xx replies (greylisting, mailbox busy) and network errors are transient;
+// SMTP 5xx replies such as an unknown recipient are permanent.
+func IsTransient(err error) bool {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"greylisted": {
+			err:      &textproto.Error{Code: 451, Msg: "4.7.1 Greylisted, try again later"},
+			expected: true,
+		},
+		"wrapped_mailbox_busy": {
+			err:      fmt.Errorf("send failed: %w", &textproto.Error{Code: 450, Msg: "mailbox busy"}),
+			expected: true,
+		},
+		"unknown_recipient": {
+			err:      &textproto.Error{Code: 550, Msg: "5.1.1 User unknown"},
+			expected: false,
+		},
+		"network_error": {
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			expected: true,
+		},
+		"generic_error": {
+			err:      errors.New("invalid address"),
+			expected: false,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsTransient(tc.err))
+		})
+	}
+}
+
+func TestLogSenderSend(t *testing.T) {
+	sender := NewLogSender()
+
+	messageID, err := sender.Send(context.Background(), EmailDraft{To: "user@example.com", Subject: "Alert"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^msg_\d+$`, messageID)
+}
+
+func TestLogSenderWithClock(t *testing.T) {
+	sender := NewLogSenderWithClock(func() time.Time {
+		return time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC)
+	})
+
+	messageID, err := sender.Send(context.Background(), EmailDraft{To: "user@example.com", Subject: "Alert"})
+	require.NoError(t, err)
+	assert.Equal(t, "msg_1705329024", messageID)
+}
+
+func TestEmailDraftMap(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC)
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		draft    EmailDraft
+		expected map[string]any
+	}{
+		"required_fields_only": {
+			draft: EmailDraft{To: "user@example.com", From: "alerts@example.com", Subject: "Alert", Body: "Hot", Timestamp: timestamp},
+			expected: map[string]any{
+				"to":        "user@example.com",
+				"from":      "alerts@example.com",
+				"subject":   "Alert",
+				"body":      "Hot",
+				"timestamp": "2024-01-15T14:30:24Z",
+			},
+		},
+		"optional_fields": {
+			draft: EmailDraft{
+				To:       "user@example.com",
+				From:     "alerts@example.com",
+				Cc:       []string{"ops@example.com"},
+				Bcc:      []string{"audit@example.com"},
+				Subject:  "Alert",
+				Body:     "Hot",
+				BodyHtml: "<p>Hot</p>",
+				Attachments: []Attachment{
+					{Filename: "report.csv", ContentType: "text/csv", Content: []byte("city,temperature\n")},
+				},
+				Timestamp: timestamp,
+			},
+			expected: map[string]any{
+				"to":       "user@example.com",
+				"from":     "alerts@example.com",
+				"cc":       []string{"ops@example.com"},
+				"bcc":      []string{"audit@example.com"},
+				"subject":  "Alert",
+				"body":     "Hot",
+				"bodyHtml": "<p>Hot</p>",
+				"attachments": []map[string]any{
+					{"filename": "report.csv", "contentType": "text/csv", "size": 17},
+				},
+				"timestamp": "2024-01-15T14:30:24Z",
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.draft.Map())
+		})
+	}
+}
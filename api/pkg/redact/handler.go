@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that masks sensitive attributes before delegating
+type Handler struct {
+	inner    slog.Handler
+	redactor *Redactor
+}
+
+// NewHandler wraps inner so every log line is redacted by redactor
+func NewHandler(inner slog.Handler, redactor *Redactor) *Handler {
+	return &Handler{
+		inner:    inner,
+		redactor: redactor,
+	}
+}
+
+// Enabled reports whether the inner handler handles records at the given level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle redacts the record's attributes and passes it to the inner handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.inner.Handle(ctx, redacted)
+}
+
+// WithAttrs returns a handler whose pre-set attributes are redacted
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+	return NewHandler(h.inner.WithAttrs(redacted), h.redactor)
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return NewHandler(h.inner.WithGroup(name), h.redactor)
+}
+
+// redactAttr masks a sensitive attribute, or redacts the maps, slices and groups it contains
+func (h *Handler) redactAttr(attr slog.Attr) slog.Attr {
+	if h.redactor.IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Mask)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		groupAttrs := value.Group()
+		redacted := make([]any, len(groupAttrs))
+		for i, groupAttr := range groupAttrs {
+			redacted[i] = h.redactAttr(groupAttr)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		// Maps and slices (including top-level JSON arrays) are redacted recursively
+		return slog.Any(attr.Key, h.redactor.Value("", value.Any()))
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,105 @@
+package redact
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Mask is the replacement for redacted values
+const Mask = "***"
+
+// DefaultPatterns are the key-name patterns redacted when none are configured
+var DefaultPatterns = []string{"password", "token", "authorization", "secret", "apikey", "api_key"}
+
+// PIIPatterns are the key-name patterns of personal details such as contact information
+var PIIPatterns = []string{"email", "phone", "address"}
+
+// Redactor masks values whose key names match any configured pattern
+type Redactor struct {
+	patterns []string
+}
+
+// New creates a redactor for the given key-name patterns.
+// Patterns are matched case-insensitively as substrings of the key.
+func New(patterns []string) *Redactor {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return &Redactor{
+		patterns: normalized,
+	}
+}
+
+// Default creates a redactor using DefaultPatterns
+func Default() *Redactor {
+	return New(DefaultPatterns)
+}
+
+// With returns a redactor that masks keys matching either r's patterns or the given ones
+func (r *Redactor) With(patterns []string) *Redactor {
+	return New(append(slices.Clone(r.patterns), patterns...))
+}
+
+// IsSensitive reports whether a key name matches any pattern
+func (r *Redactor) IsSensitive(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if strings.Contains(lowerKey, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns a deep copy of data with sensitive values masked.
+// The input map is never modified.
+func (r *Redactor) Map(data map[string]any) map[string]any {
+	if data == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(data))
+	for key, value := range data {
+		redacted[key] = r.Value(key, value)
+	}
+	return redacted
+}
+
+// Value masks value if key is sensitive, otherwise redacts any nested maps and slices
+func (r *Redactor) Value(key string, value any) any {
+	if r.IsSensitive(key) {
+		return Mask
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return r.Map(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = r.Value("", item)
+		}
+		return items
+	case []map[string]any:
+		items := make([]map[string]any, len(v))
+		for i, item := range v {
+			items[i] = r.Map(item)
+		}
+		return items
+	case []byte:
+		return value
+	}
+
+	// Other typed slices are copied element by element so nested maps are still redacted
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = r.Value("", rv.Index(i).Interface())
+		}
+		return items
+	}
+	return value
+}
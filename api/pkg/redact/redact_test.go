@@ -0,0 +1,143 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactorMap(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		patterns []string
+		data     map[string]any
+
+		// Expected output
+		expected map[string]any
+	}{
+		"default_patterns_mask_secrets": {
+			patterns: DefaultPatterns,
+			data: map[string]any{
+				"name":          "John",
+				"password":      "hunter2",
+				"Authorization": "Bearer abc",
+				"accessToken":   "xyz",
+			},
+			expected: map[string]any{
+				"name":          "John",
+				"password":      Mask,
+				"Authorization": Mask,
+				"accessToken":   Mask,
+			},
+		},
+		"nested_maps_and_slices": {
+			patterns: []string{"email"},
+			data: map[string]any{
+				"user": map[string]any{"email": "john@example.com", "city": "Sydney"},
+				"list": []any{map[string]any{"contactEmail": "a@example.com"}},
+			},
+			expected: map[string]any{
+				"user": map[string]any{"email": Mask, "city": "Sydney"},
+				"list": []any{map[string]any{"contactEmail": Mask}},
+			},
+		},
+		"typed_slices": {
+			patterns: []string{"email"},
+			data: map[string]any{
+				"emailDrafts": []map[string]any{{"to": "a@example.com"}},
+				"drafts":      []map[string]any{{"email": "a@example.com", "subject": "Alert"}},
+				"nested":      [][]any{{map[string]any{"email": "b@example.com"}}},
+				"cities":      []string{"Sydney", "Perth"},
+			},
+			expected: map[string]any{
+				"emailDrafts": Mask,
+				"drafts":      []map[string]any{{"email": Mask, "subject": "Alert"}},
+				"nested":      []any{[]any{map[string]any{"email": Mask}}},
+				"cities":      []any{"Sydney", "Perth"},
+			},
+		},
+		"no_patterns_keeps_everything": {
+			patterns: nil,
+			data:     map[string]any{"password": "hunter2"},
+			expected: map[string]any{"password": "hunter2"},
+		},
+		"nil_map": {
+			patterns: DefaultPatterns,
+			data:     nil,
+			expected: nil,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			redactor := New(tc.patterns)
+
+			redacted := redactor.Map(tc.data)
+
+			assert.Equal(t, tc.expected, redacted)
+		})
+	}
+}
+
+func TestRedactorMapDoesNotMutateInput(t *testing.T) {
+	data := map[string]any{"token": "abc", "nested": map[string]any{"password": "x"}}
+
+	Default().Map(data)
+
+	assert.Equal(t, "abc", data["token"])
+	assert.Equal(t, "x", data["nested"].(map[string]any)["password"])
+}
+
+func TestRedactorWith(t *testing.T) {
+	base := Default()
+	extended := base.With(PIIPatterns)
+
+	assert.True(t, extended.IsSensitive("password"))
+	assert.True(t, extended.IsSensitive("contactEmail"))
+	assert.True(t, extended.IsSensitive("Phone"))
+
+	// The original redactor keeps its own patterns
+	assert.False(t, base.IsSensitive("contactEmail"))
+}
+
+func TestHandlerRedactsLogLines(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), Default()))
+
+	logger.With("apiKey", "k1").Info("API response received",
+		"token", "t1",
+		"response", map[string]any{"authorization": "Bearer t2", "temperature": 25.5},
+		slog.Group("request", slog.String("password", "p1")),
+	)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, Mask, line["apiKey"])
+	assert.Equal(t, Mask, line["token"])
+	assert.Equal(t, Mask, line["response"].(map[string]any)["authorization"])
+	assert.Equal(t, 25.5, line["response"].(map[string]any)["temperature"])
+	assert.Equal(t, Mask, line["request"].(map[string]any)["password"])
+}
+
+func TestHandlerRedactsTopLevelArrays(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), Default()))
+
+	logger.Info("API response received",
+		"response", []any{map[string]any{"token": "t1", "day": "mon"}},
+		"drafts", []map[string]any{{"password": "p1"}},
+	)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	response := line["response"].([]any)[0].(map[string]any)
+	assert.Equal(t, Mask, response["token"])
+	assert.Equal(t, "mon", response["day"])
+	assert.Equal(t, Mask, line["drafts"].([]any)[0].(map[string]any)["password"])
+}
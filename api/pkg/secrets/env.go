@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvSecretStore implements SecretStore using environment variables
+type EnvSecretStore struct {
+	prefix string
+}
+
+// NewEnvSecretStore creates a secret store that reads secrets from environment variables.
+// The prefix is prepended to every secret name, e.g. prefix "SECRET_" resolves NAME from SECRET_NAME.
+func NewEnvSecretStore(prefix string) *EnvSecretStore {
+	return &EnvSecretStore{
+		prefix: prefix,
+	}
+}
+
+// Get returns the value of the named secret from the environment
+func (e *EnvSecretStore) Get(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(e.prefix + name)
+	if !ok {
+		return "", ErrSecretNotFound{Name: name}
+	}
+	return value, nil
+}
+
+// EnvAllowList exposes the environment variables it names to templates. Every other variable
+// is refused, so an empty or nil list exposes nothing.
+type EnvAllowList struct {
+	names map[string]bool
+}
+
+// NewEnvAllowList creates an allow-list of environment variable names, ignoring blank entries
+func NewEnvAllowList(names []string) *EnvAllowList {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed[name] = true
+		}
+	}
+	return &EnvAllowList{
+		names: allowed,
+	}
+}
+
+// Get returns the value of the named environment variable if the allow-list includes it
+func (a *EnvAllowList) Get(name string) (string, error) {
+	if a == nil || !a.names[name] {
+		return "", ErrEnvNotAllowed{Name: name}
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", ErrEnvNotFound{Name: name}
+	}
+	return value, nil
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSecretStoreGet(t *testing.T) {
+	t.Setenv("TEST_SECRET_API_TOKEN", "token-value")
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		prefix string
+		name   string
+
+		// Expected output
+		expectedValue string
+		expectedError bool
+	}{
+		"prefixed_secret_found": {
+			prefix:        "TEST_SECRET_",
+			name:          "API_TOKEN",
+			expectedValue: "token-value",
+		},
+		"secret_not_found": {
+			prefix:        "TEST_SECRET_",
+			name:          "MISSING",
+			expectedError: true,
+		},
+		"prefix_is_applied": {
+			prefix:        "OTHER_",
+			name:          "API_TOKEN",
+			expectedError: true,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := NewEnvSecretStore(tc.prefix)
+
+			value, err := store.Get(context.Background(), tc.name)
+
+			if tc.expectedError {
+				require.Error(t, err)
+				assert.IsType(t, ErrSecretNotFound{}, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestEnvAllowListGet(t *testing.T) {
+	t.Setenv("TEST_REGION", "ap-southeast-2")
+	t.Setenv("TEST_DB_PASSWORD", "hunter2")
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		allowList *EnvAllowList
+		name      string
+
+		// Expected output
+		expectedValue string
+		expectedError error
+	}{
+		"allowed_variable": {
+			allowList:     NewEnvAllowList([]string{"TEST_REGION", "TEST_MISSING"}),
+			name:          "TEST_REGION",
+			expectedValue: "ap-southeast-2",
+		},
+		"names_are_trimmed": {
+			allowList:     NewEnvAllowList([]string{" TEST_REGION "}),
+			name:          "TEST_REGION",
+			expectedValue: "ap-southeast-2",
+		},
+		"variable_not_allowed": {
+			allowList:     NewEnvAllowList([]string{"TEST_REGION"}),
+			name:          "TEST_DB_PASSWORD",
+			expectedError: ErrEnvNotAllowed{Name: "TEST_DB_PASSWORD"},
+		},
+		"empty_list_allows_nothing": {
+			allowList:     NewEnvAllowList(nil),
+			name:          "TEST_REGION",
+			expectedError: ErrEnvNotAllowed{Name: "TEST_REGION"},
+		},
+		"nil_list_allows_nothing": {
+			allowList:     nil,
+			name:          "TEST_REGION",
+			expectedError: ErrEnvNotAllowed{Name: "TEST_REGION"},
+		},
+		"allowed_variable_not_set": {
+			allowList:     NewEnvAllowList([]string{"TEST_REGION", "TEST_MISSING"}),
+			name:          "TEST_MISSING",
+			expectedError: ErrEnvNotFound{Name: "TEST_MISSING"},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := tc.allowList.Get(tc.name)
+
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"context"
+)
+
+// SecretStore defines the interface for resolving named secrets.
+// Implementations may be backed by environment variables, Vault, AWS Secrets Manager, etc.
+type SecretStore interface {
+	// Get returns the value of the named secret
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ErrSecretNotFound is returned when a secret does not exist in the store
+type ErrSecretNotFound struct {
+	Name string
+}
+
+func (e ErrSecretNotFound) Error() string {
+	return "secret not found: " + e.Name
+}
+
+// ErrEnvNotAllowed is returned when a template references an environment variable that is not
+// on the allow-list
+type ErrEnvNotAllowed struct {
+	Name string
+}
+
+func (e ErrEnvNotAllowed) Error() string {
+	return "environment variable not allowed: " + e.Name
+}
+
+// ErrEnvNotFound is returned when an allow-listed environment variable is not set
+type ErrEnvNotFound struct {
+	Name string
+}
+
+func (e ErrEnvNotFound) Error() string {
+	return "environment variable not found: " + e.Name
+}
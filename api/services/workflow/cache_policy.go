@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// workflowCacheTTL is how long a cached workflow definition is kept
+const workflowCacheTTL = 5 * time.Minute
+
+// maxTrackedWorkflows bounds the number of workflows whose database reads are counted at once
+const maxTrackedWorkflows = 10000
+
+// readCounter decides which workflows are read often enough to be worth caching: a workflow
+// is cached once it has been read from the database threshold times within one cache TTL.
+// A threshold of one or less caches every workflow on its first read.
+type readCounter struct {
+	mu        sync.Mutex
+	threshold int
+	reads     map[string]readWindow
+}
+
+// readWindow counts the database reads of one workflow since start
+type readWindow struct {
+	start time.Time
+	count int
+}
+
+// newReadCounter returns a counter caching workflows read threshold times within one cache TTL
+func newReadCounter(threshold int) *readCounter {
+	return &readCounter{threshold: threshold, reads: make(map[string]readWindow)}
+}
+
+// hot records a database read of the workflow and reports whether it should now be cached.
+// Counting starts over once the workflow is cached or its window has passed.
+func (c *readCounter) hot(workflowID string, now time.Time) bool {
+	if c == nil || c.threshold <= 1 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	window, tracked := c.reads[workflowID]
+	if !tracked || now.Sub(window.start) >= workflowCacheTTL {
+		window = readWindow{start: now}
+	}
+	window.count++
+	if window.count >= c.threshold {
+		delete(c.reads, workflowID)
+		return true
+	}
+
+	// Drop expired windows before tracking another workflow, and everything if none have expired
+	if !tracked && len(c.reads) >= maxTrackedWorkflows {
+		for id, other := range c.reads {
+			if now.Sub(other.start) >= workflowCacheTTL {
+				delete(c.reads, id)
+			}
+		}
+		if len(c.reads) >= maxTrackedWorkflows {
+			clear(c.reads)
+		}
+	}
+	c.reads[workflowID] = window
+	return false
+}
+
+// shouldCache reports whether a workflow just read from the database is stored in the cache.
+// The workflow's own cacheable flag wins over the read counter.
+func (s *Service) shouldCache(workflow *api.Workflow, workflowID string) bool {
+	if workflow.Cacheable != nil {
+		return *workflow.Cacheable
+	}
+	return s.reads.hot(workflowID, s.now())
+}
@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"workflow-code-test/api/pkg/cache"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+	"workflow-code-test/api/pkg/db/models"
+
+	"github.com/aarondl/null/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCounterHot(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		counter *readCounter
+		// reads are offsets from testNow at which the workflow is read
+		reads []time.Duration
+
+		expected []bool
+	}{
+		"nil_counter_caches_every_read": {
+			reads:    []time.Duration{0, 0},
+			expected: []bool{true, true},
+		},
+		"threshold_of_one_caches_every_read": {
+			counter:  newReadCounter(1),
+			reads:    []time.Duration{0, 0},
+			expected: []bool{true, true},
+		},
+		"cached_on_the_threshold_read": {
+			counter:  newReadCounter(3),
+			reads:    []time.Duration{0, time.Minute, 2 * time.Minute, 3 * time.Minute},
+			expected: []bool{false, false, true, false},
+		},
+		"window_expiry_restarts_the_count": {
+			counter:  newReadCounter(2),
+			reads:    []time.Duration{0, workflowCacheTTL, workflowCacheTTL + time.Second},
+			expected: []bool{false, false, true},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hot := make([]bool, 0, len(tc.reads))
+			for _, offset := range tc.reads {
+				hot = append(hot, tc.counter.hot("workflow-1", testNow.Add(offset)))
+			}
+			assert.Equal(t, tc.expected, hot)
+		})
+	}
+}
+
+func TestReadCounterBoundsTrackedWorkflows(t *testing.T) {
+	counter := newReadCounter(2)
+	for i := range maxTrackedWorkflows {
+		counter.hot(strconv.Itoa(i), testNow)
+	}
+	require.Len(t, counter.reads, maxTrackedWorkflows)
+
+	// Expired windows are dropped to make room
+	counter.hot("late", testNow.Add(workflowCacheTTL))
+	assert.Len(t, counter.reads, 1)
+}
+
+func TestGetWorkflowCachePolicy(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	cacheKey := "workflow:" + workflowID
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		cacheable       null.Bool
+		cacheAfterReads int
+		reads           int
+
+		// Expected results
+		expectedSets int
+	}{
+		"cached_on_first_read_by_default": {
+			reads:        1,
+			expectedSets: 1,
+		},
+		"cached_once_read_often_enough": {
+			cacheAfterReads: 3,
+			reads:           3,
+			expectedSets:    1,
+		},
+		"rarely_read_workflow_not_cached": {
+			cacheAfterReads: 3,
+			reads:           2,
+			expectedSets:    0,
+		},
+		"cacheable_flag_caches_on_first_read": {
+			cacheable:       null.BoolFrom(true),
+			cacheAfterReads: 3,
+			reads:           1,
+			expectedSets:    1,
+		},
+		"uncacheable_flag_never_caches": {
+			cacheable:    null.BoolFrom(false),
+			reads:        2,
+			expectedSets: 0,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
+			mockCache.EXPECT().
+				Get(gomock.Any(), cacheKey, gomock.Any()).
+				Return(cache.ErrCacheMiss{Key: cacheKey}).
+				Times(tc.reads)
+			mockDB.EXPECT().
+				GetWorkflowByID(gomock.Any(), workflowID).
+				Return(&models.Workflow{ID: workflowID, Name: "Test Workflow", Cacheable: tc.cacheable}, nil).
+				Times(tc.reads)
+			mockCache.EXPECT().
+				Set(gomock.Any(), cacheKey, gomock.Any(), workflowCacheTTL).
+				Return(nil).
+				Times(tc.expectedSets)
+
+			// Create service with mocks
+			service := &Service{db: mockDB, cache: mockCache, clock: fixedClock(testNow)}
+			WithCacheAfterReads(tc.cacheAfterReads)(service)
+
+			for range tc.reads {
+				workflow, err := service.GetWorkflow(context.Background(), workflowID)
+				require.NoError(t, err)
+				assert.Equal(t, tc.cacheable.Ptr(), workflow.Cacheable)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package workflow
+
+import "time"
+
+// Clock supplies the current time, letting tests pin timestamps
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// now returns the current time from the configured clock, falling back to the system clock
+func (s *Service) now() time.Time {
+	if s.clock == nil {
+		return realClock{}.Now()
+	}
+	return s.clock.Now()
+}
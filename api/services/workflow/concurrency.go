@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrTooManyExecutions is returned when a workflow already has the maximum number of executions running
+type ErrTooManyExecutions struct {
+	WorkflowID string
+	Limit      int
+}
+
+func (e ErrTooManyExecutions) Error() string {
+	return fmt.Sprintf("workflow '%s' already has %d executions running", e.WorkflowID, e.Limit)
+}
+
+// executionLimiter bounds how many executions of each workflow run at once, so a burst of
+// executions can't multiply the load on the workflow's integration endpoints.
+// A limit of zero or less means unlimited.
+type executionLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	running map[string]int
+}
+
+// newExecutionLimiter returns a limiter allowing limit concurrent executions per workflow
+func newExecutionLimiter(limit int) *executionLimiter {
+	return &executionLimiter{limit: limit, running: make(map[string]int)}
+}
+
+// acquire reserves an execution slot for the workflow. The returned release func frees it
+// and must be called once the execution finishes. Excess executions are rejected, not queued.
+func (l *executionLimiter) acquire(workflowID string) (func(), error) {
+	if l == nil || l.limit <= 0 {
+		return func() {}, nil
+	}
+
+	// Key on the canonical form so case variants of the same ID share one set of slots
+	if id, err := uuid.Parse(workflowID); err == nil {
+		workflowID = id.String()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running[workflowID] >= l.limit {
+		return nil, ErrTooManyExecutions{WorkflowID: workflowID, Limit: l.limit}
+	}
+	l.running[workflowID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.running[workflowID]--
+			if l.running[workflowID] <= 0 {
+				delete(l.running, workflowID)
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionLimiter(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		limit    int
+		acquires []string
+		released int
+
+		expectedError string
+	}{
+		"within_limit": {
+			limit:    2,
+			acquires: []string{"wf-1", "wf-1"},
+		},
+		"limit_reached": {
+			limit:         2,
+			acquires:      []string{"wf-1", "wf-1", "wf-1"},
+			expectedError: "workflow 'wf-1' already has 2 executions running",
+		},
+		"id_case_variants_share_slots": {
+			limit:         1,
+			acquires:      []string{"550e8400-e29b-41d4-a716-446655440000", "550E8400-E29B-41D4-A716-446655440000"},
+			expectedError: "workflow '550e8400-e29b-41d4-a716-446655440000' already has 1 executions running",
+		},
+		"workflows_limited_separately": {
+			limit:    1,
+			acquires: []string{"wf-1", "wf-2"},
+		},
+		"released_slot_is_reused": {
+			limit:    1,
+			acquires: []string{"wf-1", "wf-1"},
+			released: 1,
+		},
+		"unlimited": {
+			acquires: []string{"wf-1", "wf-1", "wf-1"},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter := newExecutionLimiter(tc.limit)
+
+			var err error
+			for i, workflowID := range tc.acquires {
+				var release func()
+				release, err = limiter.acquire(workflowID)
+				if err != nil {
+					break
+				}
+				if i < tc.released {
+					release()
+					// Releasing twice must not free a second slot
+					release()
+				}
+			}
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				var tooMany ErrTooManyExecutions
+				require.ErrorAs(t, err, &tooMany)
+				assert.Equal(t, tc.expectedError, tooMany.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExecutionLimiterNil(t *testing.T) {
+	// A service built without WithMaxConcurrentExecutions has no limiter
+	var limiter *executionLimiter
+	release, err := limiter.acquire("wf-1")
+	require.NoError(t, err)
+	release()
+}
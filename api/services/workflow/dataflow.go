@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// ErrUnproducedVariable is returned when a node reads a variable that no upstream node produces
+type ErrUnproducedVariable struct {
+	NodeID   string
+	NodeType api.WorkflowNodeType
+	Variable string
+}
+
+func (e ErrUnproducedVariable) Error() string {
+	return fmt.Sprintf("%s node '%s' references {{%s}} which no upstream node produces",
+		e.NodeType, e.NodeID, e.Variable)
+}
+
+// validateDataflow checks that every variable a node reads is produced by a node upstream of
+// it, is a workflow default or is reserved. A loop body reads what is upstream of its loop,
+// plus the loop's item. Form nodes declare the execution's input through their
+// outputVariables; without a form, or with a form that declares none, any variable may arrive
+// as input, so nothing is checked.
+func validateDataflow(workflow api.Workflow) error {
+	if workflow.Nodes == nil || !inputDeclared(*workflow.Nodes) {
+		return nil
+	}
+
+	defaults := make(map[string]bool)
+	if workflow.Variables != nil {
+		for name := range *workflow.Variables {
+			defaults[name] = true
+		}
+	}
+
+	nodeMap := make(map[string]api.WorkflowNode)
+	for _, node := range *workflow.Nodes {
+		nodeMap[node.Id] = node
+	}
+	predecessors := make(map[string][]string)
+	if workflow.Edges != nil {
+		for _, edge := range *workflow.Edges {
+			predecessors[edge.Target] = append(predecessors[edge.Target], edge.Source)
+		}
+	}
+
+	bodies := loopBodies(*workflow.Nodes)
+
+	for _, node := range *workflow.Nodes {
+		references := referencedVariables(node)
+		if len(references) == 0 {
+			continue
+		}
+
+		available := upstreamVariables(node.Id, nodeMap, predecessors)
+		var itemVar string
+		if loop, isBody := bodies[node.Id]; isBody {
+			available = upstreamVariables(loop.Id, nodeMap, predecessors)
+			itemVar = loopItemVar(loop)
+		}
+		for _, name := range references {
+			if isReservedVariable(name) || defaults[name] || available[name] {
+				continue
+			}
+			if itemVar != "" && (name == itemVar || strings.HasPrefix(name, itemVar+".")) {
+				continue
+			}
+			return ErrUnproducedVariable{NodeID: node.Id, NodeType: node.Type, Variable: name}
+		}
+	}
+
+	return nil
+}
+
+// inputDeclared reports whether the workflow has a form node and every form declares its outputVariables
+func inputDeclared(nodes []api.WorkflowNode) bool {
+	hasForm := false
+	for _, node := range nodes {
+		if node.Type != api.WorkflowNodeTypeForm {
+			continue
+		}
+		if node.Data == nil || node.Data.Metadata == nil {
+			return false
+		}
+		if _, exists := (*node.Data.Metadata)["outputVariables"]; !exists {
+			return false
+		}
+		hasForm = true
+	}
+	return hasForm
+}
+
+// upstreamVariables collects the variables produced by every node with a path to nodeID
+func upstreamVariables(nodeID string, nodeMap map[string]api.WorkflowNode, predecessors map[string][]string) map[string]bool {
+	available := make(map[string]bool)
+	visited := map[string]bool{nodeID: true}
+	queue := slices.Clone(predecessors[nodeID])
+
+	for len(queue) > 0 {
+		currentID := queue[0]
+		queue = queue[1:]
+		if visited[currentID] {
+			continue
+		}
+		visited[currentID] = true
+		queue = append(queue, predecessors[currentID]...)
+
+		node, exists := nodeMap[currentID]
+		if !exists {
+			continue
+		}
+		for _, name := range producedVariables(node) {
+			available[name] = true
+		}
+	}
+
+	return available
+}
+
+// producedVariables lists the variables a node makes available to later nodes
+func producedVariables(node api.WorkflowNode) []string {
+	var metadata map[string]any
+	if node.Data != nil && node.Data.Metadata != nil {
+		metadata = *node.Data.Metadata
+	}
+
+	switch node.Type {
+	case api.WorkflowNodeTypeForm:
+		return stringList(metadata["outputVariables"])
+
+	case api.WorkflowNodeTypeIntegration:
+		return append(declaredOutputs(node), "endpoint", "endpointAttempts")
+
+	case api.WorkflowNodeTypeCondition:
+		return []string{"conditionMet", "threshold", "operator", "actualValue", "valueType", "comparison"}
+
+	case api.WorkflowNodeTypeSplit:
+		return []string{"splitBranch"}
+
+	case api.WorkflowNodeTypeAggregate:
+		return append(declaredOutputs(node), "message")
+
+	case api.WorkflowNodeTypeLoop:
+		return append(declaredOutputs(node), "iterations", "message")
+	}
+
+	return nil
+}
+
+// referencedVariables lists the variables a node reads: the inputVariables of integration,
+// email and aggregate nodes, and the root of a condition's field or a loop's itemsVar. A
+// condition that awaits its field expects it from a parallel branch, so it is not checked.
+func referencedVariables(node api.WorkflowNode) []string {
+	var metadata map[string]any
+	if node.Data != nil && node.Data.Metadata != nil {
+		metadata = *node.Data.Metadata
+	}
+
+	switch node.Type {
+	case api.WorkflowNodeTypeIntegration, api.WorkflowNodeTypeEmail, api.WorkflowNodeTypeAggregate:
+		return stringList(metadata["inputVariables"])
+
+	case api.WorkflowNodeTypeCondition:
+		if awaitMs, ok := toFloat64(metadata["awaitFieldMs"]); ok && awaitMs > 0 {
+			return nil
+		}
+		field := DefaultConditionField
+		if value, ok := metadata["field"].(string); ok && value != "" {
+			field = value
+		}
+		root, _, _ := strings.Cut(field, ".")
+		return []string{root}
+
+	case api.WorkflowNodeTypeLoop:
+		if itemsVar, ok := metadata["itemsVar"].(string); ok && itemsVar != "" {
+			root, _, _ := strings.Cut(itemsVar, ".")
+			return []string{root}
+		}
+	}
+
+	return nil
+}
+
+// stringList returns the strings of a metadata array, ignoring entries of other types
+func stringList(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if name, ok := item.(string); ok && name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
@@ -0,0 +1,158 @@
+package workflow
+
+import (
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDataflow(t *testing.T) {
+	formNode := func(outputVariables ...any) api.WorkflowNode {
+		return api.WorkflowNode{
+			Id:   "form",
+			Type: api.WorkflowNodeTypeForm,
+			Data: &api.NodeData{Metadata: &map[string]any{"outputVariables": outputVariables}},
+		}
+	}
+	weatherNode := api.WorkflowNode{
+		Id:   "weather-api",
+		Type: api.WorkflowNodeTypeIntegration,
+		Data: &api.NodeData{Metadata: &map[string]any{
+			"inputVariables":  []any{"city"},
+			"outputVariables": []any{"temperature"},
+		}},
+	}
+	conditionNode := api.WorkflowNode{Id: "condition", Type: api.WorkflowNodeTypeCondition}
+	emailNode := func(inputVariables ...any) api.WorkflowNode {
+		return api.WorkflowNode{
+			Id:   "email",
+			Type: api.WorkflowNodeTypeEmail,
+			Data: &api.NodeData{Metadata: &map[string]any{"inputVariables": inputVariables}},
+		}
+	}
+	chain := func(nodes ...api.WorkflowNode) api.Workflow {
+		nodes = append([]api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}}, nodes...)
+		edges := make([]api.WorkflowEdge, 0, len(nodes)-1)
+		for i := 1; i < len(nodes); i++ {
+			edges = append(edges, api.WorkflowEdge{Id: nodes[i].Id + "-in", Source: nodes[i-1].Id, Target: nodes[i].Id})
+		}
+		return api.Workflow{Nodes: &nodes, Edges: &edges}
+	}
+	loopNode := api.WorkflowNode{
+		Id:   "per-subscriber",
+		Type: api.WorkflowNodeTypeLoop,
+		Data: &api.NodeData{Metadata: &map[string]any{"itemsVar": "subscribers", "itemVar": "subscriber", "body": "notify"}},
+	}
+	withLoopBody := func(workflow api.Workflow, inputVariables ...any) api.Workflow {
+		*workflow.Nodes = append(*workflow.Nodes, api.WorkflowNode{
+			Id:   "notify",
+			Type: api.WorkflowNodeTypeIntegration,
+			Data: &api.NodeData{Metadata: &map[string]any{"inputVariables": inputVariables}},
+		})
+		return workflow
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		workflow api.Workflow
+
+		expectedError string
+	}{
+		"variables_produced_upstream": {
+			workflow: chain(formNode("name", "email", "city"), weatherNode, conditionNode,
+				emailNode("name", "email", "city", "temperature", "conditionMet", "_workflowName")),
+		},
+		"email_reads_unproduced_variable": {
+			workflow:      chain(formNode("name", "city"), emailNode("name", "temperature")),
+			expectedError: "email node 'email' references {{temperature}} which no upstream node produces",
+		},
+		"condition_field_not_produced": {
+			workflow:      chain(formNode("name"), conditionNode),
+			expectedError: "condition node 'condition' references {{temperature}} which no upstream node produces",
+		},
+		"condition_field_path_root": {
+			workflow: chain(formNode("city"), api.WorkflowNode{
+				Id:   "weather-api",
+				Type: api.WorkflowNodeTypeIntegration,
+				Data: &api.NodeData{Metadata: &map[string]any{
+					"inputVariables": []any{"city"},
+					"responseVar":    "weatherResponse",
+				}},
+			}, api.WorkflowNode{
+				Id:   "condition",
+				Type: api.WorkflowNodeTypeCondition,
+				Data: &api.NodeData{Metadata: &map[string]any{"field": "weatherResponse.current.temperature_2m"}},
+			}),
+		},
+		"awaited_field_not_checked": {
+			workflow: chain(formNode("name"), api.WorkflowNode{
+				Id:   "condition",
+				Type: api.WorkflowNodeTypeCondition,
+				Data: &api.NodeData{Metadata: &map[string]any{"awaitFieldMs": 500}},
+			}),
+		},
+		"produced_downstream_only": {
+			workflow:      chain(formNode("name"), emailNode("temperature"), weatherNode),
+			expectedError: "email node 'email' references {{temperature}} which no upstream node produces",
+		},
+		"workflow_default": {
+			workflow: func() api.Workflow {
+				workflow := chain(formNode("name"), conditionNode)
+				workflow.Variables = &map[string]any{"temperature": 20}
+				return workflow
+			}(),
+		},
+		"form_without_output_variables_is_not_checked": {
+			workflow: chain(api.WorkflowNode{Id: "form", Type: api.WorkflowNodeTypeForm}, emailNode("temperature")),
+		},
+		"workflow_without_form_is_not_checked": {
+			workflow: chain(conditionNode, emailNode("anything")),
+		},
+		"aggregate_output_variable": {
+			workflow: chain(formNode("sydneyTemp", "melbourneTemp"), api.WorkflowNode{
+				Id:   "average",
+				Type: api.WorkflowNodeTypeAggregate,
+				Data: &api.NodeData{Metadata: &map[string]any{
+					"inputVariables": []any{"sydneyTemp", "melbourneTemp"},
+					"operation":      "avg",
+					"outputVariable": "temperature",
+				}},
+			}, conditionNode),
+		},
+		"loop_body_reads_item_and_upstream": {
+			workflow: withLoopBody(chain(formNode("subscribers", "city"), loopNode, emailNode("loopResults")),
+				"subscriber", "subscriber.email", "city", "_loopIndex"),
+		},
+		"loop_items_not_produced": {
+			workflow:      withLoopBody(chain(formNode("city"), loopNode), "subscriber"),
+			expectedError: "loop node 'per-subscriber' references {{subscribers}} which no upstream node produces",
+		},
+		"loop_body_reads_unproduced_variable": {
+			workflow:      withLoopBody(chain(formNode("subscribers"), loopNode), "subscriber", "temperature"),
+			expectedError: "integration node 'notify' references {{temperature}} which no upstream node produces",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateDataflow(tc.workflow)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrUnproducedVariable{})
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDataflowDemoWorkflow(t *testing.T) {
+	workflow, err := DemoWorkflow()
+	require.NoError(t, err)
+	assert.NoError(t, validateDataflow(*workflow))
+}
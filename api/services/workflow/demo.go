@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// DemoWorkflowID is the ID of the built-in demo workflow. It matches the sample workflow
+// seeded by db_migration, so the execute endpoint can run the demo once the database is seeded.
+const DemoWorkflowID = "550e8400-e29b-41d4-a716-446655440000"
+
+//go:embed demo_workflow.json
+var demoWorkflowJSON []byte
+
+// DemoWorkflow returns a fresh copy of the built-in demo workflow
+func DemoWorkflow() (*api.Workflow, error) {
+	var workflow api.Workflow
+	if err := json.Unmarshal(demoWorkflowJSON, &workflow); err != nil {
+		return nil, fmt.Errorf("invalid demo workflow: %w", err)
+	}
+	return &workflow, nil
+}
@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"workflow-code-test/api/pkg/mailer"
+)
+
+// DefaultAttachmentContentType is used for attachments without a contentType
+const DefaultAttachmentContentType = "application/octet-stream"
+
+// maxAttachmentBytes bounds the total size of one email's attachments, the same limit as a
+// response body kept with an execution
+const maxAttachmentBytes = maxPersistedResponseBytes
+
+// emailAttachment is one entry of an email node's attachments metadata
+type emailAttachment struct {
+	filename    string
+	contentVar  string
+	contentType string
+}
+
+// parseEmailAttachments reads the attachments list from email node metadata
+func parseEmailAttachments(metadata Metadata) ([]emailAttachment, error) {
+	entries, err := metadata.Slice("attachments")
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]emailAttachment, 0, len(entries))
+	for i, entry := range entries {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("attachments[%d] must be an object", i)
+		}
+		attachment := emailAttachment{contentType: DefaultAttachmentContentType}
+		for _, field := range []struct {
+			key    string
+			target *string
+		}{
+			{"filename", &attachment.filename},
+			{"contentVar", &attachment.contentVar},
+			{"contentType", &attachment.contentType},
+		} {
+			raw, exists := fields[field.key]
+			if !exists && field.key == "contentType" {
+				continue
+			}
+			value, ok := raw.(string)
+			if !ok || value == "" {
+				return nil, fmt.Errorf("attachments[%d].%s must be a non-empty string", i, field.key)
+			}
+			*field.target = value
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// resolveAttachments reads each attachment's content from executeVars. An attachment whose
+// content variable is not set, e.g. because the node producing it was on another branch, is
+// left out with a step warning. The content must be text, and all attachments together must
+// fit in maxAttachmentBytes.
+func resolveAttachments(ctx context.Context, attachments []emailAttachment, executeVars *ExecutionContext) ([]mailer.Attachment, error) {
+	var resolved []mailer.Attachment
+	total := 0
+	for _, attachment := range attachments {
+		value, exists := executeVars.Get(attachment.contentVar)
+		if !exists || value == nil {
+			warnStep(ctx, "Attachment content not found, attachment left out", "filename", attachment.filename, "contentVar", attachment.contentVar)
+			continue
+		}
+		content, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("attachment '%s': contentVar '%s' must be a string", attachment.filename, attachment.contentVar)
+		}
+		total += len(content)
+		if total > maxAttachmentBytes {
+			return nil, fmt.Errorf("attachments exceed %d bytes", maxAttachmentBytes)
+		}
+		resolved = append(resolved, mailer.Attachment{
+			Filename:    attachment.filename,
+			ContentType: attachment.contentType,
+			Content:     []byte(content),
+		})
+	}
+	return resolved, nil
+}
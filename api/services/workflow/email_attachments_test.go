@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/mailer"
+)
+
+func TestParseEmailAttachments(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata Metadata
+
+		expected      []emailAttachment
+		errorContains string
+	}{
+		"not_set": {
+			metadata: Metadata{},
+			expected: []emailAttachment{},
+		},
+		"default_content_type": {
+			metadata: Metadata{"attachments": []any{
+				map[string]any{"filename": "report.csv", "contentVar": "reportCsv", "contentType": "text/csv"},
+				map[string]any{"filename": "notes.txt", "contentVar": "notes"},
+			}},
+			expected: []emailAttachment{
+				{filename: "report.csv", contentVar: "reportCsv", contentType: "text/csv"},
+				{filename: "notes.txt", contentVar: "notes", contentType: DefaultAttachmentContentType},
+			},
+		},
+		"not_an_array": {
+			metadata:      Metadata{"attachments": "report.csv"},
+			errorContains: "attachments must be an array",
+		},
+		"entry_not_an_object": {
+			metadata:      Metadata{"attachments": []any{"report.csv"}},
+			errorContains: "attachments[0] must be an object",
+		},
+		"missing_filename": {
+			metadata:      Metadata{"attachments": []any{map[string]any{"contentVar": "reportCsv"}}},
+			errorContains: "attachments[0].filename must be a non-empty string",
+		},
+		"empty_content_type": {
+			metadata:      Metadata{"attachments": []any{map[string]any{"filename": "report.csv", "contentVar": "reportCsv", "contentType": ""}}},
+			errorContains: "attachments[0].contentType must be a non-empty string",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			attachments, err := parseEmailAttachments(tc.metadata)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, attachments)
+		})
+	}
+}
+
+func TestExecuteEmailNodeAttachments(t *testing.T) {
+	attachments := []any{
+		map[string]any{"filename": "report.csv", "contentVar": "reportCsv", "contentType": "text/csv"},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		vars map[string]any
+
+		expectedAttachments []mailer.Attachment
+		expectedDraft       any
+		expectedWarnings    []string
+		errorContains       string
+	}{
+		"content_attached": {
+			vars: map[string]any{"reportCsv": "city,temperature\nSydney,35\n"},
+			expectedAttachments: []mailer.Attachment{
+				{Filename: "report.csv", ContentType: "text/csv", Content: []byte("city,temperature\nSydney,35\n")},
+			},
+			expectedDraft: []map[string]any{
+				{"filename": "report.csv", "contentType": "text/csv", "size": 27},
+			},
+		},
+		"missing_content_left_out": {
+			vars:             map[string]any{},
+			expectedWarnings: []string{"Attachment content not found, attachment left out: filename=report.csv, contentVar=reportCsv"},
+		},
+		"content_not_a_string": {
+			vars:          map[string]any{"reportCsv": map[string]any{"city": "Sydney"}},
+			errorContains: "attachment 'report.csv': contentVar 'reportCsv' must be a string",
+		},
+		"content_too_large": {
+			vars:          map[string]any{"reportCsv": strings.Repeat("x", maxAttachmentBytes+1)},
+			errorContains: "attachments exceed 1048576 bytes",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sender := &scriptedSender{}
+			service := &Service{mailer: sender}
+
+			metadata := map[string]any{
+				"emailTemplate": map[string]any{"subject": "Daily report", "body": "Report attached"},
+				"attachments":   attachments,
+			}
+			node := api.WorkflowNode{Id: "email", Type: api.WorkflowNodeTypeEmail, Data: &api.NodeData{Metadata: &metadata}}
+			tc.vars["email"] = "user@example.com"
+			output := make(map[string]any)
+
+			ctx, warnings := withStepWarnings(context.Background())
+			err := service.executeEmailNode(ctx, node, NewExecutionContext(tc.vars), output)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Zero(t, sender.calls)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, sender.drafts, 1)
+			assert.Equal(t, tc.expectedAttachments, sender.drafts[0].Attachments)
+			assert.Equal(t, tc.expectedDraft, output["emailDraft"].(map[string]any)["attachments"])
+			assert.Equal(t, tc.expectedWarnings, warnings.list())
+		})
+	}
+}
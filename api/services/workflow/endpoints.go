@@ -0,0 +1,172 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// Endpoint strategies pick the order in which an integration's apiEndpoints are tried
+const (
+	// EndpointStrategyFailover always starts with the first endpoint
+	EndpointStrategyFailover = "failover"
+	// EndpointStrategyRoundRobin starts each call at the next endpoint, in proportion to the weights
+	EndpointStrategyRoundRobin = "round-robin"
+)
+
+// weightedEndpoint is one apiEndpoint template of an integration node
+type weightedEndpoint struct {
+	url    string
+	weight int
+}
+
+// parseIntegrationEndpoints reads the endpoint templates of an integration node and the
+// strategy for choosing between them. A node has either one apiEndpoint or an apiEndpoints
+// list, whose entries are URL templates or {"url", "weight"} objects.
+func parseIntegrationEndpoints(metadata map[string]any) ([]weightedEndpoint, string, error) {
+	strategy := EndpointStrategyFailover
+	if rawStrategy, exists := metadata["endpointStrategy"]; exists {
+		name, ok := rawStrategy.(string)
+		if !ok || (name != EndpointStrategyFailover && name != EndpointStrategyRoundRobin) {
+			return nil, "", fmt.Errorf("endpointStrategy must be one of failover or round-robin")
+		}
+		strategy = name
+	}
+
+	rawEndpoints, hasEndpoints := metadata["apiEndpoints"]
+	if !hasEndpoints {
+		apiEndpoint, hasEndpoint := metadata["apiEndpoint"]
+		if !hasEndpoint {
+			return nil, "", fmt.Errorf("integration node missing apiEndpoint in metadata")
+		}
+		apiEndpointStr, ok := apiEndpoint.(string)
+		if !ok {
+			return nil, "", fmt.Errorf("apiEndpoint must be a string")
+		}
+		return []weightedEndpoint{{url: apiEndpointStr, weight: 1}}, strategy, nil
+	}
+
+	if _, hasEndpoint := metadata["apiEndpoint"]; hasEndpoint {
+		return nil, "", fmt.Errorf("apiEndpoints can't be used with apiEndpoint")
+	}
+	entries, ok := rawEndpoints.([]any)
+	if !ok || len(entries) == 0 {
+		return nil, "", fmt.Errorf("apiEndpoints must be a non-empty array")
+	}
+
+	endpoints := make([]weightedEndpoint, 0, len(entries))
+	for i, entry := range entries {
+		switch value := entry.(type) {
+		case string:
+			endpoints = append(endpoints, weightedEndpoint{url: value, weight: 1})
+		case map[string]any:
+			url, _ := value["url"].(string)
+			if url == "" {
+				return nil, "", fmt.Errorf("apiEndpoints[%d] must be a string or an object with a url", i)
+			}
+			weight := 1
+			if rawWeight, exists := value["weight"]; exists {
+				number, ok := toFloat64(rawWeight)
+				if !ok || number < 1 || number != float64(int(number)) {
+					return nil, "", fmt.Errorf("apiEndpoints[%d] weight must be a positive integer", i)
+				}
+				weight = int(number)
+			}
+			endpoints = append(endpoints, weightedEndpoint{url: url, weight: weight})
+		default:
+			return nil, "", fmt.Errorf("apiEndpoints[%d] must be a string or an object with a url", i)
+		}
+	}
+	return endpoints, strategy, nil
+}
+
+// endpointRotator remembers where each round-robin integration node starts its next call.
+// A nil rotator starts every call at the first endpoint.
+type endpointRotator struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// newEndpointRotator returns a rotator with no calls recorded
+func newEndpointRotator() *endpointRotator {
+	return &endpointRotator{calls: make(map[string]int)}
+}
+
+// order returns the endpoint templates in the order to try them. Failover keeps the configured
+// order; round-robin starts at the endpoint whose turn it is and wraps around, so the other
+// endpoints remain fallbacks.
+func (r *endpointRotator) order(key string, endpoints []weightedEndpoint, strategy string) []string {
+	start := 0
+	if strategy == EndpointStrategyRoundRobin {
+		start = r.next(key, endpoints)
+	}
+
+	ordered := make([]string, 0, len(endpoints))
+	for i := range endpoints {
+		ordered = append(ordered, endpoints[(start+i)%len(endpoints)].url)
+	}
+	return ordered
+}
+
+// next returns the index of the endpoint whose turn it is, each endpoint taking as many
+// consecutive turns as its weight
+func (r *endpointRotator) next(key string, endpoints []weightedEndpoint) int {
+	if r == nil {
+		return 0
+	}
+
+	total := 0
+	for _, endpoint := range endpoints {
+		total += endpoint.weight
+	}
+
+	r.mu.Lock()
+	slot := r.calls[key] % total
+	r.calls[key] = slot + 1
+	r.mu.Unlock()
+
+	for i, endpoint := range endpoints {
+		if slot < endpoint.weight {
+			return i
+		}
+		slot -= endpoint.weight
+	}
+	return 0
+}
+
+// callEndpoints sends the request to each URL in turn until one answers with a 2xx status.
+// A failed call or an error status moves on to the next URL. The last URL's response is
+// returned even with an error status, so it is reported like a single endpoint's would be.
+func callEndpoints(ctx context.Context, client *http.Client, apiURLs []string, header http.Header) (*http.Response, string, int, error) {
+	for i, apiURL := range apiURLs {
+		last := i == len(apiURLs)-1
+
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create request", "error", err, "url", apiURL)
+			return nil, apiURL, i + 1, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header = header.Clone()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to call API", "error", err, "url", apiURL)
+			if last {
+				return nil, apiURL, i + 1, fmt.Errorf("failed to call API: %w", err)
+			}
+			continue
+		}
+
+		if !last && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			warnStep(ctx, "API returned non-2xx status code, trying the next endpoint", "status", resp.StatusCode, "url", apiURL)
+			if err := resp.Body.Close(); err != nil {
+				slog.WarnContext(ctx, "Failed to close response body", "error", err)
+			}
+			continue
+		}
+		return resp, apiURL, i + 1, nil
+	}
+	return nil, "", 0, fmt.Errorf("integration node missing apiEndpoint in metadata")
+}
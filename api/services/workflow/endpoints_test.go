@@ -0,0 +1,272 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIntegrationEndpoints(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata map[string]any
+
+		expectedEndpoints []weightedEndpoint
+		expectedStrategy  string
+		expectedError     string
+	}{
+		"single_endpoint": {
+			metadata:          map[string]any{"apiEndpoint": "https://api.example.com/{city}"},
+			expectedEndpoints: []weightedEndpoint{{url: "https://api.example.com/{city}", weight: 1}},
+			expectedStrategy:  EndpointStrategyFailover,
+		},
+		"endpoint_list_with_weights": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{
+					"https://eu.example.com/{city}",
+					map[string]any{"url": "https://us.example.com/{city}", "weight": float64(3)},
+				},
+				"endpointStrategy": "round-robin",
+			},
+			expectedEndpoints: []weightedEndpoint{
+				{url: "https://eu.example.com/{city}", weight: 1},
+				{url: "https://us.example.com/{city}", weight: 3},
+			},
+			expectedStrategy: EndpointStrategyRoundRobin,
+		},
+		"missing_endpoint": {
+			metadata:      map[string]any{},
+			expectedError: "integration node missing apiEndpoint in metadata",
+		},
+		"both_endpoint_settings": {
+			metadata: map[string]any{
+				"apiEndpoint":  "https://api.example.com",
+				"apiEndpoints": []any{"https://eu.example.com"},
+			},
+			expectedError: "apiEndpoints can't be used with apiEndpoint",
+		},
+		"empty_endpoint_list": {
+			metadata:      map[string]any{"apiEndpoints": []any{}},
+			expectedError: "apiEndpoints must be a non-empty array",
+		},
+		"entry_without_url": {
+			metadata:      map[string]any{"apiEndpoints": []any{map[string]any{"weight": 2}}},
+			expectedError: "apiEndpoints[0] must be a string or an object with a url",
+		},
+		"fractional_weight": {
+			metadata: map[string]any{"apiEndpoints": []any{
+				map[string]any{"url": "https://eu.example.com", "weight": 1.5},
+			}},
+			expectedError: "apiEndpoints[0] weight must be a positive integer",
+		},
+		"unknown_strategy": {
+			metadata: map[string]any{
+				"apiEndpoints":     []any{"https://eu.example.com"},
+				"endpointStrategy": "random",
+			},
+			expectedError: "endpointStrategy must be one of failover or round-robin",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			endpoints, strategy, err := parseIntegrationEndpoints(tc.metadata)
+
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEndpoints, endpoints)
+			assert.Equal(t, tc.expectedStrategy, strategy)
+		})
+	}
+}
+
+func TestEndpointRotatorOrder(t *testing.T) {
+	endpoints := []weightedEndpoint{
+		{url: "eu", weight: 2},
+		{url: "us", weight: 1},
+	}
+	rotator := newEndpointRotator()
+
+	// Each call starts at the endpoint whose turn it is, with the others kept as fallbacks
+	var firsts []string
+	for range 6 {
+		order := rotator.order("wf/api", endpoints, EndpointStrategyRoundRobin)
+		require.Len(t, order, 2)
+		firsts = append(firsts, order[0])
+	}
+	assert.Equal(t, []string{"eu", "eu", "us", "eu", "eu", "us"}, firsts)
+
+	// Nodes rotate independently, and failover always keeps the configured order
+	assert.Equal(t, []string{"eu", "us"}, rotator.order("wf/other", endpoints, EndpointStrategyRoundRobin))
+	assert.Equal(t, []string{"eu", "us"}, rotator.order("wf/api", endpoints, EndpointStrategyFailover))
+
+	var unset *endpointRotator
+	assert.Equal(t, []string{"eu", "us"}, unset.order("wf/api", endpoints, EndpointStrategyRoundRobin))
+}
+
+func TestExecuteIntegrationNodeEndpoints(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"temperature": 25.5}`))
+	}))
+	defer healthy.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": "maintenance"}`))
+	}))
+	defer unavailable.Close()
+
+	// A server that is closed straight away leaves an address nothing listens on
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata map[string]any
+		dryRun   bool
+
+		expectedOutput map[string]any
+		missingKeys    []string
+		errorContains  string
+	}{
+		"fails_over_on_error_status": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{unavailable.URL + "/weather/{city}", healthy.URL + "/weather/{city}"},
+			},
+			expectedOutput: map[string]any{
+				"temperature":      25.5,
+				"endpoint":         healthy.URL + "/weather/Sydney",
+				"endpointAttempts": 2,
+			},
+		},
+		"fails_over_on_unreachable_host": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{closedURL + "/weather/{city}", healthy.URL + "/weather/{city}"},
+			},
+			expectedOutput: map[string]any{
+				"temperature":      25.5,
+				"endpoint":         healthy.URL + "/weather/Sydney",
+				"endpointAttempts": 2,
+			},
+		},
+		"first_endpoint_succeeds": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{healthy.URL + "/weather/{city}", unavailable.URL + "/weather/{city}"},
+			},
+			expectedOutput: map[string]any{
+				"endpoint":         healthy.URL + "/weather/Sydney",
+				"endpointAttempts": 1,
+			},
+		},
+		"all_endpoints_fail": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{closedURL + "/weather/{city}", unavailable.URL + "/weather/{city}"},
+			},
+			expectedOutput: map[string]any{
+				"endpoint":         unavailable.URL + "/weather/Sydney",
+				"endpointAttempts": 2,
+			},
+			errorContains: "API returned status 503",
+		},
+		"single_endpoint_not_recorded": {
+			metadata:       map[string]any{"apiEndpoint": healthy.URL + "/weather/{city}"},
+			expectedOutput: map[string]any{"temperature": 25.5},
+			missingKeys:    []string{"endpoint", "endpointAttempts"},
+		},
+		"dry_run_reports_first_endpoint": {
+			metadata: map[string]any{
+				"apiEndpoints": []any{closedURL + "/weather/{city}", healthy.URL + "/weather/{city}"},
+			},
+			dryRun: true,
+			expectedOutput: map[string]any{
+				"request": map[string]any{"method": "GET", "url": closedURL + "/weather/Sydney"},
+			},
+			missingKeys: []string{"endpoint", "temperature"},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			metadata := map[string]any{
+				"inputVariables":  []any{"city"},
+				"options":         []any{map[string]any{"city": "Sydney"}},
+				"outputVariables": []any{"temperature"},
+			}
+			for key, value := range tc.metadata {
+				metadata[key] = value
+			}
+			node := api.WorkflowNode{
+				Id:   "integration-1",
+				Type: api.WorkflowNodeTypeIntegration,
+				Data: &api.NodeData{Metadata: &metadata},
+			}
+
+			ctx := context.Background()
+			if tc.dryRun {
+				ctx = withExecutionOptions(ctx, &api.ExecutionOptions{DryRun: boolPtr(true)})
+			}
+
+			service := &Service{}
+			output := make(map[string]any)
+			err := service.executeIntegrationNode(ctx, node, NewExecutionContext(map[string]any{"city": "Sydney"}), output)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+			} else {
+				require.NoError(t, err)
+			}
+			for key, expected := range tc.expectedOutput {
+				assert.Equal(t, expected, output[key], "key %s", key)
+			}
+			for _, key := range tc.missingKeys {
+				assert.NotContains(t, output, key)
+			}
+		})
+	}
+}
+
+func TestExecuteIntegrationNodeRoundRobin(t *testing.T) {
+	var hits []string
+	newServer := func(region string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits = append(hits, region)
+			w.Write([]byte(`{"temperature": 25.5}`))
+		}))
+	}
+	eu := newServer("eu")
+	defer eu.Close()
+	us := newServer("us")
+	defer us.Close()
+
+	metadata := map[string]any{
+		"inputVariables":   []any{"city"},
+		"options":          []any{map[string]any{"city": "Sydney"}},
+		"apiEndpoints":     []any{eu.URL + "/weather/{city}", us.URL + "/weather/{city}"},
+		"endpointStrategy": "round-robin",
+	}
+	node := api.WorkflowNode{
+		Id:   "integration-1",
+		Type: api.WorkflowNodeTypeIntegration,
+		Data: &api.NodeData{Metadata: &metadata},
+	}
+
+	service := &Service{endpoints: newEndpointRotator()}
+	for range 3 {
+		err := service.executeIntegrationNode(context.Background(), node, NewExecutionContext(map[string]any{"city": "Sydney"}), make(map[string]any))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"eu", "us", "eu"}, hits)
+}
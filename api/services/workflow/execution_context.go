@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ExecutionContext holds the variables shared between the nodes of a single execution.
+// All access goes through its methods, so it is safe for concurrent use.
+type ExecutionContext struct {
+	mu   sync.RWMutex
+	vars map[string]any
+
+	// conditionEvaluated records that a condition node produced conditionMet in this run
+	conditionEvaluated bool
+
+	// writers maps each declared output variable to the node that last wrote it
+	writers map[string]string
+}
+
+// NewExecutionContext creates an execution context seeded with a copy of vars
+func NewExecutionContext(vars map[string]any) *ExecutionContext {
+	c := &ExecutionContext{vars: make(map[string]any, len(vars))}
+	maps.Copy(c.vars, vars)
+	return c
+}
+
+// Get returns the raw value stored under key
+func (c *ExecutionContext) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, exists := c.vars[key]
+	return value, exists
+}
+
+// GetPath resolves a dot-separated path such as "weatherResponse.current.temperature_2m"
+// through nested objects and arrays. A key containing dots is matched as-is first.
+// It reports false when any segment along the path is missing.
+func (c *ExecutionContext) GetPath(path string) (any, bool) {
+	if value, exists := c.Get(path); exists {
+		return value, true
+	}
+
+	segments := strings.Split(path, ".")
+	value, exists := c.Get(segments[0])
+	if !exists {
+		return nil, false
+	}
+
+	for _, segment := range segments[1:] {
+		switch current := value.(type) {
+		case map[string]any:
+			value, exists = current[segment]
+			if !exists {
+				return nil, false
+			}
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// GetNumber returns the value under key coerced to a float64.
+// It reports false when the key is missing or not numeric.
+func (c *ExecutionContext) GetNumber(key string) (float64, bool) {
+	value, exists := c.Get(key)
+	if !exists {
+		return 0, false
+	}
+	return toFloat64(value)
+}
+
+// GetString returns the value under key if it is a string
+func (c *ExecutionContext) GetString(key string) (string, bool) {
+	value, exists := c.Get(key)
+	if !exists {
+		return "", false
+	}
+	str, ok := value.(string)
+	return str, ok
+}
+
+// GetBool returns the value under key if it is a bool
+func (c *ExecutionContext) GetBool(key string) (bool, bool) {
+	value, exists := c.Get(key)
+	if !exists {
+		return false, false
+	}
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// Set stores value under key
+func (c *ExecutionContext) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.vars[key] = value
+}
+
+// Merge stores every entry of values, overwriting existing keys
+func (c *ExecutionContext) Merge(values map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	maps.Copy(c.vars, values)
+}
+
+// MarkConditionEvaluated records that conditionMet holds the result of a condition evaluated in this run
+func (c *ExecutionContext) MarkConditionEvaluated() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conditionEvaluated = true
+}
+
+// ConditionEvaluated reports whether a condition was evaluated in this run
+func (c *ExecutionContext) ConditionEvaluated() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.conditionEvaluated
+}
+
+// recordWriter records nodeID as the writer of key, returning the node that wrote it before
+// when that was a different node
+func (c *ExecutionContext) recordWriter(key string, nodeID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.writers == nil {
+		c.writers = make(map[string]string)
+	}
+	previous, written := c.writers[key]
+	c.writers[key] = nodeID
+	return previous, written && previous != nodeID
+}
+
+// Snapshot returns a copy of all variables that is safe to read without locking
+func (c *ExecutionContext) Snapshot() map[string]any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return maps.Clone(c.vars)
+}
@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionContextAccessors(t *testing.T) {
+	executeVars := NewExecutionContext(map[string]any{
+		"temperature":  json.Number("28.5"),
+		"threshold":    25,
+		"city":         "Sydney",
+		"conditionMet": true,
+	})
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		get func() (any, bool)
+
+		expectedValue any
+		expectedOK    bool
+	}{
+		"number_from_json_number": {
+			get:           func() (any, bool) { return executeVars.GetNumber("temperature") },
+			expectedValue: 28.5,
+			expectedOK:    true,
+		},
+		"number_from_int": {
+			get:           func() (any, bool) { return executeVars.GetNumber("threshold") },
+			expectedValue: 25.0,
+			expectedOK:    true,
+		},
+		"number_from_string": {
+			get:           func() (any, bool) { return executeVars.GetNumber("city") },
+			expectedValue: 0.0,
+		},
+		"missing_number": {
+			get:           func() (any, bool) { return executeVars.GetNumber("humidity") },
+			expectedValue: 0.0,
+		},
+		"string": {
+			get:           func() (any, bool) { return executeVars.GetString("city") },
+			expectedValue: "Sydney",
+			expectedOK:    true,
+		},
+		"string_from_bool": {
+			get:           func() (any, bool) { return executeVars.GetString("conditionMet") },
+			expectedValue: "",
+		},
+		"bool": {
+			get:           func() (any, bool) { return executeVars.GetBool("conditionMet") },
+			expectedValue: true,
+			expectedOK:    true,
+		},
+		"missing_bool": {
+			get:           func() (any, bool) { return executeVars.GetBool("emailSent") },
+			expectedValue: false,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, ok := tc.get()
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestExecutionContextGetPath(t *testing.T) {
+	executeVars := NewExecutionContext(map[string]any{
+		"weatherResponse": map[string]any{
+			"current": map[string]any{"temperature_2m": json.Number("28.5")},
+			"hourly":  []any{map[string]any{"temperature_2m": 21.0}},
+		},
+		"city":        "Sydney",
+		"dotted.name": "literal",
+		"dotted":      map[string]any{"name": "nested"},
+	})
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		path string
+
+		expectedValue any
+		expectedOK    bool
+	}{
+		"flat_key": {
+			path:          "city",
+			expectedValue: "Sydney",
+			expectedOK:    true,
+		},
+		"nested_object": {
+			path:          "weatherResponse.current.temperature_2m",
+			expectedValue: json.Number("28.5"),
+			expectedOK:    true,
+		},
+		"array_index": {
+			path:          "weatherResponse.hourly.0.temperature_2m",
+			expectedValue: 21.0,
+			expectedOK:    true,
+		},
+		"literal_dotted_key_wins": {
+			path:          "dotted.name",
+			expectedValue: "literal",
+			expectedOK:    true,
+		},
+		"missing_root": {
+			path: "forecast.current.temperature_2m",
+		},
+		"missing_intermediate_key": {
+			path: "weatherResponse.daily.temperature_2m",
+		},
+		"index_out_of_range": {
+			path: "weatherResponse.hourly.3.temperature_2m",
+		},
+		"path_through_scalar": {
+			path: "city.name",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, ok := executeVars.GetPath(tc.path)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestExecutionContextCopiesSeed(t *testing.T) {
+	seed := map[string]any{"city": "Sydney"}
+	executeVars := NewExecutionContext(seed)
+
+	executeVars.Set("city", "Melbourne")
+	executeVars.Merge(map[string]any{"temperature": 20.0})
+
+	assert.Equal(t, map[string]any{"city": "Sydney"}, seed)
+
+	snapshot := executeVars.Snapshot()
+	snapshot["city"] = "Perth"
+	city, _ := executeVars.GetString("city")
+	assert.Equal(t, "Melbourne", city)
+}
+
+func TestExecutionContextConcurrentAccess(t *testing.T) {
+	executeVars := NewExecutionContext(nil)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("var-%d", i)
+			executeVars.Set(key, i)
+			executeVars.Merge(map[string]any{"last": i})
+			_, _ = executeVars.GetNumber(key)
+			_ = executeVars.Snapshot()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, executeVars.Snapshot(), 51)
+}
@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// maxExecutionLogEntries bounds the log entries kept for one execution
+const maxExecutionLogEntries = 1000
+
+// executionLogs collects the log entries written while one execution runs.
+// Parallel branches log concurrently, so entries are guarded by mu.
+type executionLogs struct {
+	mu      sync.Mutex
+	entries []api.ExecutionLogEntry
+	dropped int
+}
+
+// add keeps an entry until the execution has maxExecutionLogEntries, then counts the rest
+func (l *executionLogs) add(entry api.ExecutionLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) >= maxExecutionLogEntries {
+		l.dropped++
+		return
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// list returns the collected entries in the order they were written, ending with a warning
+// when entries were dropped
+func (l *executionLogs) list() []api.ExecutionLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := append([]api.ExecutionLogEntry(nil), l.entries...)
+	if l.dropped > 0 {
+		attributes := map[string]any{"dropped": l.dropped}
+		entries = append(entries, api.ExecutionLogEntry{
+			Level:      api.WARN,
+			Message:    fmt.Sprintf("Execution log limit of %d entries reached", maxExecutionLogEntries),
+			Timestamp:  entries[len(entries)-1].Timestamp,
+			Attributes: &attributes,
+		})
+	}
+	return entries
+}
+
+// executionLogsKey is the context key carrying the log collector of the current execution
+type executionLogsKey struct{}
+
+// withExecutionLogs returns a context whose log records are collected for the execution
+func withExecutionLogs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, executionLogsKey{}, &executionLogs{})
+}
+
+// executionLogsFromContext returns the log collector carried by ctx, if any
+func executionLogsFromContext(ctx context.Context) *executionLogs {
+	logs, _ := ctx.Value(executionLogsKey{}).(*executionLogs)
+	return logs
+}
+
+// logNodeKey is the context key carrying the ID of the node being executed
+type logNodeKey struct{}
+
+// withLogNode returns a context whose log records are attributed to nodeID
+func withLogNode(ctx context.Context, nodeID string) context.Context {
+	return context.WithValue(ctx, logNodeKey{}, nodeID)
+}
+
+// ExecutionLogHandler is a slog.Handler that copies every record logged with an execution's
+// context into that execution's logs, at any level, before delegating to inner. Wrap it in
+// the redacting handler so collected entries are redacted like the service logs.
+type ExecutionLogHandler struct {
+	inner  slog.Handler
+	attrs  []slog.Attr
+	prefix string
+}
+
+// NewExecutionLogHandler wraps inner so execution-scoped records are also collected
+func NewExecutionLogHandler(inner slog.Handler) *ExecutionLogHandler {
+	return &ExecutionLogHandler{inner: inner}
+}
+
+// Enabled reports true for any record of an execution, so debug entries are collected even
+// when the service logs at a higher level
+func (h *ExecutionLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return executionLogsFromContext(ctx) != nil || h.inner.Enabled(ctx, level)
+}
+
+// Handle collects the record for the execution carried by ctx and passes it to the inner
+// handler when that handler is enabled for its level
+func (h *ExecutionLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if logs := executionLogsFromContext(ctx); logs != nil {
+		logs.add(h.entry(ctx, record))
+	}
+	if !h.inner.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.inner.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler whose collected entries also carry attrs
+func (h *ExecutionLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefixed := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	prefixed = append(prefixed, h.attrs...)
+	for _, attr := range attrs {
+		prefixed = append(prefixed, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value})
+	}
+	return &ExecutionLogHandler{inner: h.inner.WithAttrs(attrs), attrs: prefixed, prefix: h.prefix}
+}
+
+// WithGroup returns a handler whose collected attribute keys are prefixed with name
+func (h *ExecutionLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &ExecutionLogHandler{inner: h.inner.WithGroup(name), attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// entry converts a record to a log entry. A nodeID attribute, or else the node carried by
+// ctx, becomes the entry's node.
+func (h *ExecutionLogHandler) entry(ctx context.Context, record slog.Record) api.ExecutionLogEntry {
+	entry := api.ExecutionLogEntry{
+		Level:     executionLogLevel(record.Level),
+		Message:   record.Message,
+		Timestamp: record.Time,
+	}
+	if nodeID, ok := ctx.Value(logNodeKey{}).(string); ok {
+		entry.NodeId = &nodeID
+	}
+
+	attributes := make(map[string]any)
+	addAttr := func(key string, value slog.Value) {
+		if key == "nodeID" || key == "nodeId" {
+			nodeID := value.String()
+			entry.NodeId = &nodeID
+			return
+		}
+		attributes[key] = logAttrValue(value)
+	}
+	for _, attr := range h.attrs {
+		addAttr(attr.Key, attr.Value)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(h.prefix+attr.Key, attr.Value)
+		return true
+	})
+
+	if len(attributes) > 0 {
+		entry.Attributes = &attributes
+	}
+	return entry
+}
+
+// executionLogLevel maps a slog level, including custom levels between the standard ones,
+// to the level reported for an entry
+func executionLogLevel(level slog.Level) api.ExecutionLogEntryLevel {
+	switch {
+	case level >= slog.LevelError:
+		return api.ERROR
+	case level >= slog.LevelWarn:
+		return api.WARN
+	case level >= slog.LevelInfo:
+		return api.INFO
+	default:
+		return api.DEBUG
+	}
+}
+
+// logAttrValue converts an attribute value to one that encodes as JSON. Errors and values
+// JSON can't encode are kept as their text.
+func logAttrValue(value slog.Value) any {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]any)
+		for _, attr := range value.Group() {
+			group[attr.Key] = logAttrValue(attr.Value)
+		}
+		return group
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindAny:
+		v := value.Any()
+		if err, ok := v.(error); ok {
+			return err.Error()
+		}
+		if _, err := json.Marshal(v); err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return v
+	}
+	return value.Any()
+}
@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+	"workflow-code-test/api/pkg/redact"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionLogHandler(t *testing.T) {
+	var written bytes.Buffer
+	inner := slog.NewJSONHandler(&written, &slog.HandlerOptions{Level: slog.LevelWarn})
+	logger := slog.New(redact.NewHandler(NewExecutionLogHandler(inner), redact.Default()))
+
+	ctx := withExecutionLogs(context.Background())
+	nodeCtx := withLogNode(ctx, "form")
+
+	logger.DebugContext(nodeCtx, "Evaluating condition value", "key", "temperature")
+	logger.With("workflowID", "wf-1").WarnContext(nodeCtx, "Transient email failure, retrying",
+		"nodeID", "email", "error", errors.New("greylisted"), "delay", time.Second, "token", "abc123")
+	logger.WithGroup("request").ErrorContext(ctx, "Failed to call API", "status", 503)
+	logger.Log(ctx, slog.LevelInfo+2, "Custom level")
+	logger.WarnContext(context.Background(), "Outside any execution")
+
+	entries := executionLogsFromContext(ctx).list()
+	require.Len(t, entries, 4)
+
+	// Debug entries are collected even though the service only writes warnings
+	assert.Equal(t, api.DEBUG, entries[0].Level)
+	assert.Equal(t, "Evaluating condition value", entries[0].Message)
+	require.NotNil(t, entries[0].NodeId)
+	assert.Equal(t, "form", *entries[0].NodeId)
+	assert.Equal(t, map[string]any{"key": "temperature"}, *entries[0].Attributes)
+
+	// A nodeID attribute names the node, and values are redacted and made JSON friendly
+	assert.Equal(t, api.WARN, entries[1].Level)
+	require.NotNil(t, entries[1].NodeId)
+	assert.Equal(t, "email", *entries[1].NodeId)
+	assert.Equal(t, map[string]any{
+		"workflowID": "wf-1",
+		"error":      "greylisted",
+		"delay":      "1s",
+		"token":      redact.Mask,
+	}, *entries[1].Attributes)
+
+	assert.Equal(t, api.ERROR, entries[2].Level)
+	assert.Nil(t, entries[2].NodeId)
+	assert.Equal(t, map[string]any{"request.status": int64(503)}, *entries[2].Attributes)
+
+	assert.Equal(t, api.INFO, entries[3].Level)
+	assert.Nil(t, entries[3].Attributes)
+
+	// Only records at the service's level reach its own output
+	output := written.String()
+	assert.NotContains(t, output, "Evaluating condition value")
+	assert.NotContains(t, output, "Custom level")
+	assert.Contains(t, output, "Transient email failure, retrying")
+	assert.Contains(t, output, "Outside any execution")
+	assert.NotContains(t, output, "abc123")
+}
+
+func TestExecutionLogsLimit(t *testing.T) {
+	logs := &executionLogs{}
+	loggedAt := time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC)
+	for range maxExecutionLogEntries + 5 {
+		logs.add(api.ExecutionLogEntry{Level: api.INFO, Message: "API response received", Timestamp: loggedAt})
+	}
+
+	entries := logs.list()
+	require.Len(t, entries, maxExecutionLogEntries+1)
+	last := entries[len(entries)-1]
+	assert.Equal(t, api.WARN, last.Level)
+	assert.Equal(t, "Execution log limit of 1000 entries reached", last.Message)
+	assert.Equal(t, map[string]any{"dropped": 5}, *last.Attributes)
+	assert.Equal(t, loggedAt, last.Timestamp)
+}
+
+func TestRecordExecutionStoresLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored *db.Execution
+	mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+	mockExecutions.EXPECT().
+		CreateExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, execution *db.Execution) error {
+			stored = execution
+			return nil
+		})
+
+	ctx := withExecutionLogs(context.Background())
+	logger := slog.New(NewExecutionLogHandler(slog.NewJSONHandler(&bytes.Buffer{}, nil)))
+	logger.InfoContext(withLogNode(ctx, "weather-api"), "API response received", "url", "https://api.example.com")
+	logger.DebugContext(ctx, "Ignoring input for reserved variable")
+
+	service := &Service{executions: mockExecutions}
+	result := &api.WorkflowExecutionResult{
+		ExecutedAt: testNow,
+		Status:     api.WorkflowExecutionResultStatusCompleted,
+		Steps:      []api.ExecutionStep{},
+	}
+	service.recordExecution(ctx, "550e8400-e29b-41d4-a716-446655440000", uuid.New(), api.WorkflowExecutionInput{}, result)
+
+	require.NotNil(t, stored)
+	require.Len(t, stored.Logs, 2)
+	assert.Equal(t, 0, stored.Logs[0].Position)
+	assert.Equal(t, "INFO", stored.Logs[0].Level)
+	assert.Equal(t, "weather-api", stored.Logs[0].NodeID.String)
+	assert.JSONEq(t, `{"url":"https://api.example.com"}`, string(stored.Logs[0].Attributes))
+	assert.Equal(t, 1, stored.Logs[1].Position)
+	assert.Equal(t, "DEBUG", stored.Logs[1].Level)
+	assert.False(t, stored.Logs[1].NodeID.Valid)
+	assert.JSONEq(t, `{}`, string(stored.Logs[1].Attributes))
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// ErrInvalidExecutionOrder is returned when a workflow's executionOrder can't be run as listed
+type ErrInvalidExecutionOrder struct {
+	Reason string
+}
+
+func (e ErrInvalidExecutionOrder) Error() string {
+	return "invalid executionOrder: " + e.Reason
+}
+
+// validateExecutionOrder checks that an explicit execution order begins with the start node,
+// names each existing node at most once and has an edge between every consecutive pair
+func validateExecutionOrder(workflow api.Workflow) error {
+	if workflow.ExecutionOrder == nil {
+		return nil
+	}
+	order := *workflow.ExecutionOrder
+	if len(order) == 0 {
+		return ErrInvalidExecutionOrder{Reason: "must list at least one node"}
+	}
+	if order[0] != StartNodeID {
+		return ErrInvalidExecutionOrder{Reason: fmt.Sprintf("must begin with the '%s' node", StartNodeID)}
+	}
+
+	nodes := make(map[string]bool)
+	if workflow.Nodes != nil {
+		for _, node := range *workflow.Nodes {
+			nodes[node.Id] = true
+		}
+	}
+	connected := make(map[[2]string]bool)
+	if workflow.Edges != nil {
+		for _, edge := range *workflow.Edges {
+			connected[[2]string{edge.Source, edge.Target}] = true
+		}
+	}
+
+	listed := make(map[string]bool, len(order))
+	for i, nodeID := range order {
+		if !nodes[nodeID] {
+			return ErrInvalidExecutionOrder{Reason: fmt.Sprintf("node '%s' does not exist", nodeID)}
+		}
+		if listed[nodeID] {
+			return ErrInvalidExecutionOrder{Reason: fmt.Sprintf("node '%s' is listed more than once", nodeID)}
+		}
+		listed[nodeID] = true
+
+		if i > 0 && !connected[[2]string{order[i-1], nodeID}] {
+			return ErrInvalidExecutionOrder{Reason: fmt.Sprintf("no edge from '%s' to '%s'", order[i-1], nodeID)}
+		}
+	}
+
+	return nil
+}
+
+// executeInOrder runs the nodes of an explicit execution order one after another instead of
+// traversing the graph. Each node is reached through the edge from the node before it; when a
+// condition or split does not take that edge, the rest of the sequence is on a branch that was
+// not chosen, so the run ends there like a traversal that reaches no further nodes.
+func (s *Service) executeInOrder(ctx context.Context, order []string, nodeMap map[string]api.WorkflowNode, adjacencyList map[string][]api.WorkflowEdge, executeVars *ExecutionContext, input api.WorkflowExecutionInput) ([]api.ExecutionStep, []api.TraversedEdge, error) {
+	steps := []api.ExecutionStep{}
+	traversedEdges := []api.TraversedEdge{}
+
+	// Edges the previous node's result follows
+	var next []api.WorkflowEdge
+	for i, nodeID := range order {
+		// Stop once the caller has gone or the request deadline has passed
+		if err := ctx.Err(); err != nil {
+			return steps, traversedEdges, fmt.Errorf("execution stopped: %w", err)
+		}
+
+		if i > 0 {
+			edge, followed := edgeTo(next, nodeID)
+			if !followed {
+				slog.DebugContext(ctx, "Execution order ends at a branch that was not taken", "from", order[i-1], "to", nodeID)
+				break
+			}
+			traversedEdges = append(traversedEdges, s.traversedEdge(edge, executeVars))
+		}
+
+		node := nodeMap[nodeID]
+		step := s.executeSingleNode(ctx, node, executeVars, input)
+		ensureStepOutput(&step)
+		steps = append(steps, step)
+		next = outgoingEdges(node, adjacencyList[nodeID], executeVars, step.Error != nil)
+
+		// Stop at the first failed step unless its error path leads to the next listed node; it
+		// stays in steps so callers can see why
+		if step.Error != nil {
+			routed := false
+			if i+1 < len(order) {
+				_, routed = edgeTo(next, order[i+1])
+			}
+			if !routed {
+				return steps, traversedEdges, fmt.Errorf("step error: %s, %s", step.NodeId, *step.Error)
+			}
+			slog.WarnContext(ctx, "Condition failed, following its error handle", "nodeID", node.Id, "error", *step.Error)
+			addStepWarning(&steps[len(steps)-1], "Condition failed, following its error handle")
+		}
+
+		// A stop node ends the run successfully, skipping the rest of the sequence
+		if node.Type == api.WorkflowNodeTypeStop {
+			break
+		}
+	}
+
+	return steps, traversedEdges, nil
+}
+
+// edgeTo returns the first of edges that enters target
+func edgeTo(edges []api.WorkflowEdge, target string) (api.WorkflowEdge, bool) {
+	for _, edge := range edges {
+		if edge.Target == target {
+			return edge, true
+		}
+	}
+	return api.WorkflowEdge{}, false
+}
@@ -0,0 +1,198 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestValidateExecutionOrder(t *testing.T) {
+	nodes := []api.WorkflowNode{
+		{Id: "start", Type: api.WorkflowNodeTypeStart},
+		{Id: "form", Type: api.WorkflowNodeTypeForm},
+		{Id: "condition", Type: api.WorkflowNodeTypeCondition},
+		{Id: "end", Type: api.WorkflowNodeTypeEnd},
+	}
+	edges := []api.WorkflowEdge{
+		{Id: "e1", Source: "start", Target: "form"},
+		{Id: "e2", Source: "form", Target: "condition"},
+		{Id: "e3", Source: "condition", Target: "end", SourceHandle: strPtr("true")},
+		{Id: "e4", Source: "end", Target: "form"},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		order []string
+
+		expectedError bool
+		errorContains string
+	}{
+		"follows_edges": {
+			order: []string{"start", "form", "condition", "end"},
+		},
+		"may_stop_before_the_last_node": {
+			order: []string{"start", "form"},
+		},
+		"empty": {
+			order:         []string{},
+			expectedError: true,
+			errorContains: "invalid executionOrder: must list at least one node",
+		},
+		"does_not_begin_with_start": {
+			order:         []string{"form", "condition", "end"},
+			expectedError: true,
+			errorContains: "must begin with the 'start' node",
+		},
+		"unknown_node": {
+			order:         []string{"start", "form", "weather-api"},
+			expectedError: true,
+			errorContains: "node 'weather-api' does not exist",
+		},
+		"node_listed_twice": {
+			order:         []string{"start", "form", "condition", "end", "form"},
+			expectedError: true,
+			errorContains: "node 'form' is listed more than once",
+		},
+		"no_edge_between_nodes": {
+			order:         []string{"start", "condition", "end"},
+			expectedError: true,
+			errorContains: "no edge from 'start' to 'condition'",
+		},
+	}
+
+	// Run test cases
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{}
+			workflow := api.Workflow{Nodes: &nodes, Edges: &edges, ExecutionOrder: &tt.order}
+
+			err := service.validateWorkflow(workflow)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrInvalidExecutionOrder{})
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExecuteWorkflowStepsExecutionOrder(t *testing.T) {
+	// Breadth-first, start would run both branches and reach end through either of them
+	nodes := []api.WorkflowNode{
+		{Id: "start", Type: api.WorkflowNodeTypeStart},
+		{Id: "note", Type: api.WorkflowNodeTypeNote},
+		{Id: "form", Type: api.WorkflowNodeTypeForm},
+		{Id: "condition", Type: api.WorkflowNodeTypeCondition},
+		{Id: "stop", Type: api.WorkflowNodeTypeStop},
+		{Id: "end", Type: api.WorkflowNodeTypeEnd},
+	}
+	edges := []api.WorkflowEdge{
+		{Id: "e1", Source: "start", Target: "note"},
+		{Id: "e2", Source: "start", Target: "form"},
+		{Id: "e3", Source: "form", Target: "condition"},
+		{Id: "e4", Source: "condition", Target: "end", SourceHandle: strPtr("true")},
+		{Id: "e5", Source: "condition", Target: "stop", SourceHandle: strPtr("false")},
+		{Id: "e6", Source: "stop", Target: "end"},
+		{Id: "e7", Source: "note", Target: "end"},
+		{Id: "e8", Source: "condition", Target: "stop", SourceHandle: strPtr(ErrorHandle)},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		order       []string
+		temperature any
+
+		expectedNodes []string
+		expectedEdges []string
+		expectedError string
+	}{
+		"runs_only_the_listed_nodes": {
+			order:         []string{"start", "form", "condition", "end"},
+			temperature:   35.0,
+			expectedNodes: []string{"start", "form", "condition", "end"},
+			expectedEdges: []string{"e2", "e3", "e4"},
+		},
+		"ends_where_the_condition_takes_another_branch": {
+			order:         []string{"start", "form", "condition", "end"},
+			temperature:   10.0,
+			expectedNodes: []string{"start", "form", "condition"},
+			expectedEdges: []string{"e2", "e3"},
+		},
+		"stop_node_ends_the_sequence": {
+			order:         []string{"start", "form", "condition", "stop", "end"},
+			temperature:   10.0,
+			expectedNodes: []string{"start", "form", "condition", "stop"},
+			expectedEdges: []string{"e2", "e3", "e5"},
+		},
+		"failed_condition_follows_its_error_handle": {
+			order:         []string{"start", "form", "condition", "stop"},
+			temperature:   "hot",
+			expectedNodes: []string{"start", "form", "condition", "stop"},
+			expectedEdges: []string{"e2", "e3", "e8"},
+		},
+		"failed_step_ends_the_sequence": {
+			order:         []string{"start", "form", "condition", "end"},
+			temperature:   "hot",
+			expectedNodes: []string{"start", "form", "condition"},
+			expectedEdges: []string{"e2", "e3"},
+			expectedError: "step error: condition",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{}
+			workflow := api.Workflow{Nodes: &nodes, Edges: &edges, ExecutionOrder: &tc.order}
+			input := api.WorkflowExecutionInput{
+				FormData:  &map[string]any{"temperature": tc.temperature},
+				Condition: &api.Condition{Operator: api.GreaterThan, Threshold: 30.0},
+			}
+
+			steps, traversedEdges, err := service.executeWorkflowSteps(context.Background(), workflow, input)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			nodeIDs := make([]string, 0, len(steps))
+			for _, step := range steps {
+				nodeIDs = append(nodeIDs, step.NodeId)
+			}
+			assert.Equal(t, tc.expectedNodes, nodeIDs)
+
+			edgeIDs := make([]string, 0, len(traversedEdges))
+			for _, edge := range traversedEdges {
+				edgeIDs = append(edgeIDs, edge.Id)
+			}
+			assert.Equal(t, tc.expectedEdges, edgeIDs)
+		})
+	}
+}
+
+func TestExecuteWorkflowStepsExecutionOrderCancelled(t *testing.T) {
+	service := &Service{}
+	order := []string{"start", "end"}
+	workflow := api.Workflow{
+		Nodes:          &[]api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "end", Type: api.WorkflowNodeTypeEnd}},
+		Edges:          &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "end"}},
+		ExecutionOrder: &order,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	steps, _, err := service.executeWorkflowSteps(ctx, workflow, api.WorkflowExecutionInput{})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, steps)
+}
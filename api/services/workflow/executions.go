@@ -0,0 +1,387 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
+
+	"github.com/aarondl/null/v8"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// recordExecution persists the execution input, with its form data redacted, and result.
+// Failures are logged and the run itself still succeeds. The result only gets its execution ID
+// once the execution is stored, so every returned ID can be fetched and replayed.
+func (s *Service) recordExecution(ctx context.Context, workflowID string, executionID openapi_types.UUID, input api.WorkflowExecutionInput, result *api.WorkflowExecutionResult) {
+	if s.executions == nil {
+		return
+	}
+
+	// Sensitive form data is masked before it is stored, not only when it is read back
+	inputJSON, err := json.Marshal(s.redactInput(input))
+	if err != nil {
+		slog.Warn("Failed to encode execution input", "error", err, "workflowID", workflowID, "executionID", executionID)
+		return
+	}
+	labelsJSON := []byte(`{}`)
+	if input.Labels != nil {
+		// A map of strings always encodes
+		labelsJSON, _ = json.Marshal(*input.Labels)
+	}
+
+	// Steps are stored as rows; the result blob only keeps the summary. The summary carries the
+	// execution ID, which the result itself only gets once the insert succeeds.
+	summary := *result
+	summary.ExecutionId = &executionID
+	summary.Steps = []api.ExecutionStep{}
+	resultJSON, err := json.Marshal(summary)
+	if err != nil {
+		slog.Warn("Failed to encode execution result", "error", err, "workflowID", workflowID, "executionID", executionID)
+		return
+	}
+
+	execution := &db.Execution{
+		ID:         executionID.String(),
+		WorkflowID: workflowID,
+		Status:     string(result.Status),
+		Input:      inputJSON,
+		Result:     resultJSON,
+		Labels:     labelsJSON,
+		ExecutedAt: result.ExecutedAt,
+	}
+	if result.ReplayOf != nil {
+		execution.ReplayOf = null.StringFrom(result.ReplayOf.String())
+	}
+	for i, step := range result.Steps {
+		record, err := mapStepToRecord(i, step)
+		if err != nil {
+			slog.Warn("Failed to encode execution step", "error", err, "workflowID", workflowID, "nodeID", step.NodeId)
+			return
+		}
+		execution.Steps = append(execution.Steps, record)
+	}
+	if logs := executionLogsFromContext(ctx); logs != nil {
+		for i, entry := range logs.list() {
+			record, err := mapLogToRecord(i, entry)
+			if err != nil {
+				slog.Warn("Failed to encode execution log", "error", err, "workflowID", workflowID, "executionID", executionID)
+				return
+			}
+			execution.Logs = append(execution.Logs, record)
+		}
+	}
+
+	// A cancelled or timed-out execution is still stored, so the insert must outlive the request
+	if err := s.executions.CreateExecution(context.WithoutCancel(ctx), execution); err != nil {
+		slog.Warn("Failed to record execution", "error", err, "workflowID", workflowID, "executionID", executionID)
+		return
+	}
+	result.ExecutionId = &executionID
+
+	slog.Debug("Execution recorded", "workflowID", workflowID, "executionID", execution.ID)
+}
+
+// GetExecution retrieves a stored execution result with the steps matching filter
+func (s *Service) GetExecution(ctx context.Context, workflowID string, executionID string, filter db.StepFilter) (*api.WorkflowExecutionResult, error) {
+	if s.executions == nil {
+		return nil, fmt.Errorf("execution history not configured")
+	}
+
+	execution, err := s.executions.GetExecutionByID(ctx, workflowID, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result api.WorkflowExecutionResult
+	if err := json.Unmarshal(execution.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode execution result: %w", err)
+	}
+
+	records, total, err := s.executions.ListExecutionSteps(ctx, executionID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Steps = make([]api.ExecutionStep, 0, len(records))
+	for _, record := range records {
+		step, err := mapRecordToStep(record)
+		if err != nil {
+			return nil, err
+		}
+		result.Steps = append(result.Steps, step)
+	}
+	result.TotalSteps = &total
+
+	// Input is redacted before it is stored. Redacting again covers rows stored before that,
+	// and rows stored before REDACT_INPUT_PII was enabled.
+	if len(execution.Input) > 0 {
+		var input api.WorkflowExecutionInput
+		if err := json.Unmarshal(execution.Input, &input); err != nil {
+			return nil, fmt.Errorf("failed to decode execution input: %w", err)
+		}
+		redacted := s.redactInput(input)
+		result.Input = &redacted
+	}
+
+	return &result, nil
+}
+
+// redactInput returns a copy of input with sensitive form data masked
+func (s *Service) redactInput(input api.WorkflowExecutionInput) api.WorkflowExecutionInput {
+	if input.FormData != nil {
+		formData := s.inputRedactor().Map(*input.FormData)
+		input.FormData = &formData
+	}
+	return input
+}
+
+// GetExecutionLogs retrieves the log entries written while a stored execution ran
+func (s *Service) GetExecutionLogs(ctx context.Context, workflowID string, executionID string) (*api.ExecutionLogList, error) {
+	if s.executions == nil {
+		return nil, fmt.Errorf("execution history not configured")
+	}
+
+	// Look the execution up first so another workflow's execution is reported as not found
+	execution, err := s.executions.GetExecutionByID(ctx, workflowID, executionID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(execution.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution ID format: %v", err)
+	}
+
+	records, err := s.executions.ListExecutionLogs(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &api.ExecutionLogList{
+		ExecutionId: openapi_types.UUID(id),
+		Logs:        make([]api.ExecutionLogEntry, 0, len(records)),
+	}
+	for _, record := range records {
+		entry, err := mapRecordToLog(record)
+		if err != nil {
+			return nil, err
+		}
+		list.Logs = append(list.Logs, entry)
+	}
+
+	return list, nil
+}
+
+// ListExecutions returns a page of a workflow's stored executions, newest first
+func (s *Service) ListExecutions(ctx context.Context, workflowID string, filter db.ExecutionFilter) (*api.ExecutionList, error) {
+	if s.executions == nil {
+		return nil, fmt.Errorf("execution history not configured")
+	}
+
+	executions, total, err := s.executions.ListExecutions(ctx, workflowID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &api.ExecutionList{
+		Executions: make([]api.ExecutionSummary, 0, len(executions)),
+		Total:      total,
+	}
+	for _, execution := range executions {
+		summary, err := mapExecutionToSummary(execution)
+		if err != nil {
+			return nil, err
+		}
+		list.Executions = append(list.Executions, summary)
+	}
+
+	return list, nil
+}
+
+// ReplayExecution re-runs a stored execution's input against the current workflow definition
+func (s *Service) ReplayExecution(ctx context.Context, workflowID string, executionID string) (*api.WorkflowExecutionResult, error) {
+	if s.executions == nil {
+		return nil, fmt.Errorf("execution history not configured")
+	}
+
+	execution, err := s.executions.GetExecutionByID(ctx, workflowID, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var input api.WorkflowExecutionInput
+	if err := json.Unmarshal(execution.Input, &input); err != nil {
+		return nil, fmt.Errorf("failed to decode execution input: %w", err)
+	}
+
+	originalID, err := uuid.Parse(execution.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution ID format: %v", err)
+	}
+	replayOf := openapi_types.UUID(originalID)
+
+	slog.Info("Replaying execution", "workflowID", workflowID, "executionID", executionID)
+	return s.runWorkflow(ctx, workflowID, input, &replayOf)
+}
+
+// validateLabels checks execution labels before they are stored.
+// Keys can't contain ':' because label filters are written as key:value.
+func validateLabels(labels *map[string]string) error {
+	if labels == nil {
+		return nil
+	}
+	for key := range *labels {
+		if key == "" || strings.Contains(key, ":") {
+			return fmt.Errorf("invalid label key '%s': keys must be non-empty and must not contain ':'", key)
+		}
+	}
+	return nil
+}
+
+// mapExecutionToSummary converts a persisted execution to its list representation
+func mapExecutionToSummary(execution db.Execution) (api.ExecutionSummary, error) {
+	executionID, err := uuid.Parse(execution.ID)
+	if err != nil {
+		return api.ExecutionSummary{}, fmt.Errorf("invalid execution ID format: %v", err)
+	}
+
+	summary := api.ExecutionSummary{
+		ExecutionId: openapi_types.UUID(executionID),
+		ExecutedAt:  execution.ExecutedAt,
+		Status:      execution.Status,
+	}
+
+	if execution.ReplayOf.Valid {
+		replayOf, err := uuid.Parse(execution.ReplayOf.String)
+		if err != nil {
+			return api.ExecutionSummary{}, fmt.Errorf("invalid replay reference format: %v", err)
+		}
+		replayOfID := openapi_types.UUID(replayOf)
+		summary.ReplayOf = &replayOfID
+	}
+
+	if len(execution.Labels) > 0 {
+		var labels map[string]string
+		if err := json.Unmarshal(execution.Labels, &labels); err != nil {
+			return api.ExecutionSummary{}, fmt.Errorf("failed to decode execution labels: %w", err)
+		}
+		if len(labels) > 0 {
+			summary.Labels = &labels
+		}
+	}
+
+	return summary, nil
+}
+
+// mapStepToRecord converts an API step to its persisted form
+func mapStepToRecord(position int, step api.ExecutionStep) (db.ExecutionStep, error) {
+	record := db.ExecutionStep{
+		Position:    position,
+		NodeID:      step.NodeId,
+		NodeType:    step.Type,
+		Status:      string(step.Status),
+		Label:       null.StringFromPtr(step.Label),
+		Description: null.StringFromPtr(step.Description),
+		Error:       null.StringFromPtr(step.Error),
+		Output:      []byte(`{}`),
+		RawResponse: null.StringFromPtr(step.RawResponse),
+	}
+
+	if step.Output != nil {
+		output, err := json.Marshal(*step.Output)
+		if err != nil {
+			return db.ExecutionStep{}, fmt.Errorf("failed to encode step output: %w", err)
+		}
+		record.Output = output
+	}
+
+	if step.Warnings != nil {
+		warnings, err := json.Marshal(*step.Warnings)
+		if err != nil {
+			return db.ExecutionStep{}, fmt.Errorf("failed to encode step warnings: %w", err)
+		}
+		record.Warnings = warnings
+	}
+
+	return record, nil
+}
+
+// mapRecordToStep converts a persisted step back to the API model
+func mapRecordToStep(record db.ExecutionStep) (api.ExecutionStep, error) {
+	step := api.ExecutionStep{
+		NodeId:      record.NodeID,
+		Type:        record.NodeType,
+		Status:      api.ExecutionStepStatus(record.Status),
+		Label:       record.Label.Ptr(),
+		Description: record.Description.Ptr(),
+		Error:       record.Error.Ptr(),
+		RawResponse: record.RawResponse.Ptr(),
+	}
+
+	output := make(map[string]any)
+	if len(record.Output) > 0 {
+		if err := json.Unmarshal(record.Output, &output); err != nil {
+			return api.ExecutionStep{}, fmt.Errorf("failed to decode step output: %w", err)
+		}
+	}
+	step.Output = &output
+
+	if len(record.Warnings) > 0 {
+		var warnings []string
+		if err := json.Unmarshal(record.Warnings, &warnings); err != nil {
+			return api.ExecutionStep{}, fmt.Errorf("failed to decode step warnings: %w", err)
+		}
+		step.Warnings = &warnings
+	}
+
+	return step, nil
+}
+
+// mapLogToRecord converts a collected log entry to its persisted form
+func mapLogToRecord(position int, entry api.ExecutionLogEntry) (db.ExecutionLog, error) {
+	record := db.ExecutionLog{
+		Position:   position,
+		Level:      string(entry.Level),
+		NodeID:     null.StringFromPtr(entry.NodeId),
+		Message:    entry.Message,
+		Attributes: []byte(`{}`),
+		LoggedAt:   entry.Timestamp,
+	}
+
+	if entry.Attributes != nil {
+		attributes, err := json.Marshal(*entry.Attributes)
+		if err != nil {
+			return db.ExecutionLog{}, fmt.Errorf("failed to encode log attributes: %w", err)
+		}
+		record.Attributes = attributes
+	}
+
+	return record, nil
+}
+
+// mapRecordToLog converts a persisted log entry back to the API model
+func mapRecordToLog(record db.ExecutionLog) (api.ExecutionLogEntry, error) {
+	entry := api.ExecutionLogEntry{
+		Level:     api.ExecutionLogEntryLevel(record.Level),
+		NodeId:    record.NodeID.Ptr(),
+		Message:   record.Message,
+		Timestamp: record.LoggedAt,
+	}
+
+	attributes := make(map[string]any)
+	if len(record.Attributes) > 0 {
+		if err := json.Unmarshal(record.Attributes, &attributes); err != nil {
+			return api.ExecutionLogEntry{}, fmt.Errorf("failed to decode log attributes: %w", err)
+		}
+	}
+	if len(attributes) > 0 {
+		entry.Attributes = &attributes
+	}
+
+	return entry, nil
+}
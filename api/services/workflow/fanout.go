@@ -0,0 +1,183 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultFanOutVar receives the fan-out results when no fanOutVar is configured
+const DefaultFanOutVar = "results"
+
+// Bounds on the number of fan-out calls in flight at once
+const (
+	DefaultFanOutConcurrency = 4
+	MaxFanOutConcurrency     = 10
+)
+
+// fanOutSettings is the parsed fan-out configuration of an integration node
+type fanOutSettings struct {
+	outputVar   string
+	concurrency int
+}
+
+// parseFanOutSettings reads fanOut, fanOutVar and maxConcurrency from integration metadata.
+// A nil result means only the first matching option is called as usual.
+func parseFanOutSettings(metadata Metadata) (*fanOutSettings, error) {
+	fanOut, err := metadata.Bool("fanOut")
+	if err != nil || !fanOut {
+		return nil, err
+	}
+	for _, key := range []string{"streamMode", "responseVar", "scalarOutputVar", "persistResponse"} {
+		if metadata.Has(key) {
+			return nil, fmt.Errorf("%s can't be used with fanOut", key)
+		}
+	}
+
+	settings := &fanOutSettings{outputVar: DefaultFanOutVar, concurrency: DefaultFanOutConcurrency}
+	outputVar, err := metadata.NonEmptyString("fanOutVar")
+	if err != nil {
+		return nil, err
+	}
+	if isReservedVariable(outputVar) {
+		return nil, fmt.Errorf("fanOutVar '%s' is reserved", outputVar)
+	}
+	if outputVar != "" {
+		settings.outputVar = outputVar
+	}
+	if rawConcurrency, exists := metadata["maxConcurrency"]; exists {
+		concurrency, ok := toFloat64(rawConcurrency)
+		if !ok || concurrency < 1 || concurrency != math.Trunc(concurrency) {
+			return nil, fmt.Errorf("maxConcurrency must be a positive integer")
+		}
+		if concurrency > MaxFanOutConcurrency {
+			return nil, fmt.Errorf("maxConcurrency must not exceed %d", MaxFanOutConcurrency)
+		}
+		settings.concurrency = int(concurrency)
+	}
+	return settings, nil
+}
+
+// executeIntegrationFanOut calls each option's requestURLs, at most settings.concurrency
+// options at a time, and stores one result per option in option order. Each result is the
+// option's values as written, with the node's outputVariables from its response on top.
+// The first failure cancels the calls still in flight and fails the node.
+func (s *Service) executeIntegrationFanOut(ctx context.Context, settings *fanOutSettings, options []map[string]any, requestURLs [][]string, client *http.Client, header http.Header, outputVars []string, outputTypes map[string]string, output map[string]any) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]any, len(options))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, settings.concurrency)
+	for i, option := range options {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := s.fetchFanOutOption(ctx, client, requestURLs[i], header, outputVars, outputTypes)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			for key, value := range option {
+				if _, exists := result[key]; !exists {
+					result[key] = value
+				}
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	output[settings.outputVar] = results
+	output[NodeMessageKey] = fmt.Sprintf("Fetched %d results", len(results))
+	return nil
+}
+
+// fetchFanOutOption makes one fan-out call and returns the outputVariables found in its response
+func (s *Service) fetchFanOutOption(ctx context.Context, client *http.Client, apiURLs []string, header http.Header, outputVars []string, outputTypes map[string]string) (map[string]any, error) {
+	resp, apiURL, _, err := callEndpoints(ctx, client, apiURLs, header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.WarnContext(ctx, "Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read API response", "error", err)
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.ErrorContext(ctx, "API returned non-2xx status code",
+			"status", resp.StatusCode,
+			"url", apiURL,
+			"body", s.redactBody(body))
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, s.redactBody(body))
+	}
+
+	var responseMap map[string]any
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&responseMap); err != nil {
+		slog.ErrorContext(ctx, "Failed to parse API response", "error", err, "body", s.redactBody(body))
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	slog.DebugContext(ctx, "API response received", "url", apiURL, "response", responseMap)
+
+	result := make(map[string]any, len(outputVars))
+	if err := extractOutputVariables(ctx, responseMap, outputVars, outputTypes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// dryRunFanOut reports the request each option would make first without calling the API
+func dryRunFanOut(ctx context.Context, requestURLs [][]string, output map[string]any) error {
+	requests := make([]any, 0, len(requestURLs))
+	for _, apiURLs := range requestURLs {
+		apiURL := apiURLs[0]
+		if _, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil); err != nil {
+			slog.ErrorContext(ctx, "Failed to create request", "error", err, "url", apiURL)
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		requests = append(requests, map[string]any{"method": "GET", "url": apiURL})
+	}
+	output["requests"] = requests
+	output[NodeMessageKey] = fmt.Sprintf("Dry run: %d GET requests not sent", len(requests))
+	return nil
+}
@@ -0,0 +1,266 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestParseFanOutSettings(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata Metadata
+
+		expected      *fanOutSettings
+		errorContains string
+	}{
+		"not_set": {
+			metadata: Metadata{},
+		},
+		"disabled": {
+			metadata: Metadata{"fanOut": false, "responseVar": "weather"},
+		},
+		"defaults": {
+			metadata: Metadata{"fanOut": true},
+			expected: &fanOutSettings{outputVar: DefaultFanOutVar, concurrency: DefaultFanOutConcurrency},
+		},
+		"custom_var_and_concurrency": {
+			metadata: Metadata{"fanOut": true, "fanOutVar": "forecasts", "maxConcurrency": 2.0},
+			expected: &fanOutSettings{outputVar: "forecasts", concurrency: 2},
+		},
+		"not_a_boolean": {
+			metadata:      Metadata{"fanOut": "yes"},
+			errorContains: "fanOut must be a boolean",
+		},
+		"empty_var": {
+			metadata:      Metadata{"fanOut": true, "fanOutVar": ""},
+			errorContains: "fanOutVar must be a non-empty string",
+		},
+		"reserved_var": {
+			metadata:      Metadata{"fanOut": true, "fanOutVar": "_now"},
+			errorContains: "fanOutVar '_now' is reserved",
+		},
+		"fractional_concurrency": {
+			metadata:      Metadata{"fanOut": true, "maxConcurrency": 1.5},
+			errorContains: "maxConcurrency must be a positive integer",
+		},
+		"zero_concurrency": {
+			metadata:      Metadata{"fanOut": true, "maxConcurrency": 0},
+			errorContains: "maxConcurrency must be a positive integer",
+		},
+		"concurrency_too_high": {
+			metadata:      Metadata{"fanOut": true, "maxConcurrency": 11},
+			errorContains: "maxConcurrency must not exceed 10",
+		},
+		"stream_mode_not_allowed": {
+			metadata:      Metadata{"fanOut": true, "streamMode": "count"},
+			errorContains: "streamMode can't be used with fanOut",
+		},
+		"response_var_not_allowed": {
+			metadata:      Metadata{"fanOut": true, "responseVar": "weather"},
+			errorContains: "responseVar can't be used with fanOut",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseFanOutSettings(tc.metadata)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, settings)
+		})
+	}
+}
+
+func TestExecuteIntegrationNodeFanOut(t *testing.T) {
+	options := []any{
+		map[string]any{"city": "Sydney", "country": "AU"},
+		map[string]any{"city": "Melbourne", "country": "AU"},
+		map[string]any{"city": "Auckland", "country": "NZ"},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata    map[string]any
+		executeVars map[string]any
+		dryRun      bool
+		failCity    string
+
+		expectedOutput map[string]any
+		errorContains  string
+	}{
+		"calls_every_matching_option": {
+			metadata: map[string]any{"inputVariables": []any{"country"}},
+			executeVars: map[string]any{
+				"country": "AU",
+			},
+			expectedOutput: map[string]any{
+				"results": []any{
+					map[string]any{"city": "Sydney", "country": "AU", "temperature": 20.0},
+					map[string]any{"city": "Melbourne", "country": "AU", "temperature": 15.0},
+				},
+				NodeMessageKey: "Fetched 2 results",
+			},
+		},
+		"calls_every_option_without_input_variables": {
+			metadata: map[string]any{
+				"inputVariables": []any{},
+				"fanOutVar":      "forecasts",
+				"maxConcurrency": 1,
+			},
+			expectedOutput: map[string]any{
+				"forecasts": []any{
+					map[string]any{"city": "Sydney", "country": "AU", "temperature": 20.0},
+					map[string]any{"city": "Melbourne", "country": "AU", "temperature": 15.0},
+					map[string]any{"city": "Auckland", "country": "NZ", "temperature": 18.0},
+				},
+			},
+		},
+		"checks_output_types": {
+			metadata: map[string]any{
+				"inputVariables": []any{"country"},
+				"outputTypes":    map[string]any{"temperature": "boolean"},
+			},
+			executeVars:   map[string]any{"country": "NZ"},
+			errorContains: "output variable 'temperature'",
+		},
+		"dry_run_lists_requests": {
+			metadata:    map[string]any{"inputVariables": []any{"country"}},
+			executeVars: map[string]any{"country": "AU"},
+			dryRun:      true,
+			expectedOutput: map[string]any{
+				"requests": []any{
+					map[string]any{"method": "GET", "url": "/weather/Sydney"},
+					map[string]any{"method": "GET", "url": "/weather/Melbourne"},
+				},
+				NodeMessageKey: "Dry run: 2 GET requests not sent",
+			},
+		},
+		"failed_call_fails_the_node": {
+			metadata:      map[string]any{"inputVariables": []any{}},
+			failCity:      "Melbourne",
+			errorContains: "API returned status 502",
+		},
+		"no_matching_option": {
+			metadata:      map[string]any{"inputVariables": []any{"country"}},
+			executeVars:   map[string]any{"country": "US"},
+			errorContains: "no matching option found for input values",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			temperatures := map[string]string{"Sydney": "20", "Melbourne": "15", "Auckland": "18"}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				city := strings.TrimPrefix(r.URL.Path, "/weather/")
+				if city == tc.failCity {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"current": {"temperature": ` + temperatures[city] + `}}`))
+			}))
+			defer server.Close()
+
+			metadata := map[string]any{
+				"apiEndpoint":     server.URL + "/weather/{city}",
+				"options":         options,
+				"outputVariables": []any{"temperature"},
+				"fanOut":          true,
+			}
+			for key, value := range tc.metadata {
+				metadata[key] = value
+			}
+			node := api.WorkflowNode{
+				Id:   "integration-1",
+				Type: api.WorkflowNodeTypeIntegration,
+				Data: &api.NodeData{Metadata: &metadata},
+			}
+
+			ctx := context.Background()
+			if tc.dryRun {
+				ctx = withDryRun(ctx)
+			}
+			service := &Service{}
+			output := make(map[string]any)
+
+			err := service.executeIntegrationNode(ctx, node, NewExecutionContext(tc.executeVars), output)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			if requests, ok := output["requests"].([]any); ok {
+				for _, request := range requests {
+					request := request.(map[string]any)
+					request["url"] = strings.TrimPrefix(request["url"].(string), server.URL)
+				}
+			}
+			for key, expected := range tc.expectedOutput {
+				assert.Equal(t, expected, output[key], "key %s", key)
+			}
+			assert.NotContains(t, output, "temperature")
+		})
+	}
+}
+
+func TestExecuteIntegrationNodeFanOutBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"temperature": 20}`))
+	}))
+	defer server.Close()
+
+	options := make([]any, 0, 8)
+	for _, city := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		options = append(options, map[string]any{"city": city})
+	}
+	metadata := map[string]any{
+		"inputVariables":  []any{},
+		"apiEndpoint":     server.URL + "/weather/{city}",
+		"options":         options,
+		"outputVariables": []any{"temperature"},
+		"fanOut":          true,
+		"maxConcurrency":  3,
+	}
+	node := api.WorkflowNode{
+		Id:   "integration-1",
+		Type: api.WorkflowNodeTypeIntegration,
+		Data: &api.NodeData{Metadata: &metadata},
+	}
+
+	service := &Service{}
+	output := make(map[string]any)
+	require.NoError(t, service.executeIntegrationNode(context.Background(), node, NewExecutionContext(nil), output))
+
+	assert.Len(t, output["results"], 8)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1))
+}
@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// ExecutionHook is called with the final result of a stored workflow's execution, whether it
+// completed, failed or was cancelled. Hooks run in the background after the execution is
+// recorded, so a slow hook never delays the response. The result is shared with the response
+// and with other hooks and must not be modified.
+type ExecutionHook func(ctx context.Context, workflowID string, result *api.WorkflowExecutionResult)
+
+// WithExecutionHooks adds hooks run after every execution of a stored workflow, including replays
+func WithExecutionHooks(hooks ...ExecutionHook) Option {
+	return func(s *Service) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
+// runExecutionHooks starts each hook in its own goroutine. Hooks outlive the request, so they
+// get a context that is not cancelled with it, and a panicking hook is logged rather than
+// taking down the server.
+func (s *Service) runExecutionHooks(ctx context.Context, workflowID string, result *api.WorkflowExecutionResult) {
+	if len(s.hooks) == 0 {
+		return
+	}
+
+	hookCtx := context.WithoutCancel(ctx)
+	for _, hook := range s.hooks {
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					slog.ErrorContext(hookCtx, "Execution hook panicked", "panic", recovered, "workflowID", workflowID)
+				}
+			}()
+			hook(hookCtx, workflowID, result)
+		}()
+	}
+}
+
+// ExecutionMetrics counts finished executions by status. Its Hook is wired by default so the
+// counts can be served alongside the other metrics.
+type ExecutionMetrics struct {
+	mu     sync.Mutex
+	counts map[api.WorkflowExecutionResultStatus]int64
+}
+
+// NewExecutionMetrics returns metrics with every count at zero
+func NewExecutionMetrics() *ExecutionMetrics {
+	return &ExecutionMetrics{counts: make(map[api.WorkflowExecutionResultStatus]int64)}
+}
+
+// Hook is an ExecutionHook counting the result's status
+func (m *ExecutionMetrics) Hook(_ context.Context, _ string, result *api.WorkflowExecutionResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[result.Status]++
+}
+
+// Counts returns the number of executions recorded for each status
+func (m *ExecutionMetrics) Counts() map[api.WorkflowExecutionResultStatus]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := map[api.WorkflowExecutionResultStatus]int64{
+		api.WorkflowExecutionResultStatusCompleted: 0,
+		api.WorkflowExecutionResultStatusFailed:    0,
+		api.WorkflowExecutionResultStatusPartial:   0,
+		api.WorkflowExecutionResultStatusCancelled: 0,
+	}
+	for status, count := range m.counts {
+		counts[status] = count
+	}
+	return counts
+}
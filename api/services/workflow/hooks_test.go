@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/cache"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hookCall is one invocation of a recording execution hook
+type hookCall struct {
+	workflowID string
+	status     api.WorkflowExecutionResultStatus
+	ctxErr     error
+}
+
+// recordingHook returns a hook sending each call to the returned channel
+func recordingHook() (ExecutionHook, chan hookCall) {
+	calls := make(chan hookCall, 1)
+	return func(ctx context.Context, workflowID string, result *api.WorkflowExecutionResult) {
+		calls <- hookCall{workflowID: workflowID, status: result.Status, ctxErr: ctx.Err()}
+	}, calls
+}
+
+// awaitHookCall waits for a hook call, failing the test if none arrives
+func awaitHookCall(t *testing.T, calls chan hookCall) hookCall {
+	t.Helper()
+	select {
+	case call := <-calls:
+		return call
+	case <-time.After(time.Second):
+		t.Fatal("execution hook was not called")
+		return hookCall{}
+	}
+}
+
+func TestExecutionHooks(t *testing.T) {
+	workflowID := openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"))
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		nodes []api.WorkflowNode
+
+		expectedStatus api.WorkflowExecutionResultStatus
+	}{
+		"completed": {
+			nodes:          []api.WorkflowNode{{Id: "node", Type: api.WorkflowNodeTypeForm}},
+			expectedStatus: api.WorkflowExecutionResultStatusCompleted,
+		},
+		"failed": {
+			nodes:          []api.WorkflowNode{{Id: "node", Type: api.WorkflowNodeTypeIntegration}},
+			expectedStatus: api.WorkflowExecutionResultStatusFailed,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			nodes := append([]api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}}, tc.nodes...)
+			nodes = append(nodes, api.WorkflowNode{Id: "end", Type: api.WorkflowNodeTypeEnd})
+			expectCachedWorkflow(mockCache, api.Workflow{
+				Id:    workflowID,
+				Nodes: &nodes,
+				Edges: &[]api.WorkflowEdge{
+					{Id: "e1", Source: "start", Target: "node"},
+					{Id: "e2", Source: "node", Target: "end"},
+				},
+			})
+
+			hook, calls := recordingHook()
+			service := &Service{cache: mockCache, hooks: []ExecutionHook{hook}}
+
+			// Hooks outlive the request, so cancelling it once the response is ready must not reach them
+			ctx, cancel := context.WithCancel(context.Background())
+			result, err := service.ExecuteWorkflow(ctx, workflowID.String(), api.WorkflowExecutionInput{})
+			cancel()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, result.Status)
+
+			call := awaitHookCall(t, calls)
+			assert.Equal(t, hookCall{workflowID: workflowID.String(), status: tc.expectedStatus}, call)
+		})
+	}
+}
+
+func TestExecutionHooksNotRunWithoutExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+	mockCache := cachemocks.NewMockCache(ctrl)
+
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	mockCache.EXPECT().Get(gomock.Any(), "workflow:"+workflowID, gomock.Any()).Return(cache.ErrCacheMiss{Key: "workflow:" + workflowID})
+	mockDB.EXPECT().GetWorkflowByID(gomock.Any(), workflowID).Return(nil, ErrWorkflowNotFound)
+
+	hook, calls := recordingHook()
+	service := &Service{db: mockDB, cache: mockCache, hooks: []ExecutionHook{hook}}
+
+	_, err := service.ExecuteWorkflow(context.Background(), workflowID, api.WorkflowExecutionInput{})
+	require.ErrorIs(t, err, ErrWorkflowNotFound)
+
+	// Hooks are only started once an execution has a result, so none can still be pending
+	assert.Empty(t, calls)
+}
+
+func TestRunExecutionHooksRecoversPanic(t *testing.T) {
+	hook, calls := recordingHook()
+	service := &Service{hooks: []ExecutionHook{
+		func(context.Context, string, *api.WorkflowExecutionResult) { panic("audit system unavailable") },
+		hook,
+	}}
+
+	service.runExecutionHooks(context.Background(), "workflow-1", &api.WorkflowExecutionResult{Status: api.WorkflowExecutionResultStatusCompleted})
+
+	call := awaitHookCall(t, calls)
+	assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, call.status)
+}
+
+func TestExecutionMetrics(t *testing.T) {
+	metrics := NewExecutionMetrics()
+	for _, status := range []api.WorkflowExecutionResultStatus{
+		api.WorkflowExecutionResultStatusCompleted,
+		api.WorkflowExecutionResultStatusCompleted,
+		api.WorkflowExecutionResultStatusFailed,
+	} {
+		metrics.Hook(context.Background(), "workflow-1", &api.WorkflowExecutionResult{Status: status})
+	}
+
+	expected := map[api.WorkflowExecutionResultStatus]int64{
+		api.WorkflowExecutionResultStatusCompleted: 2,
+		api.WorkflowExecutionResultStatusFailed:    1,
+		api.WorkflowExecutionResultStatusPartial:   0,
+		api.WorkflowExecutionResultStatusCancelled: 0,
+	}
+	assert.Equal(t, expected, metrics.Counts())
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -17,3 +18,67 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, errorMessage stri
 		slog.Error("Failed to encode error response", "error", err, "message", errorMessage)
 	}
 }
+
+// writeValidationErrorResponse writes a 422 listing each input field that can't be run as given
+func writeValidationErrorResponse(w http.ResponseWriter, invalid ErrInvalidInput) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(api.ValidationError{
+		Error:  invalid.Error(),
+		Fields: invalid.Fields,
+	}); err != nil {
+		slog.Error("Failed to encode validation error response", "error", err)
+	}
+}
+
+// validationStatus maps an error rejecting a workflow definition or its execution input before
+// any node runs to its HTTP status, returning the matched error so its own message is reported.
+// The error is nil for any other error.
+func validationStatus(err error) (int, error) {
+	var invalidInput ErrInvalidInput
+	if errors.As(err, &invalidInput) {
+		return http.StatusUnprocessableEntity, invalidInput
+	}
+
+	var (
+		invalidData   ErrInvalidNodeData
+		invalidHandle ErrInvalidEdgeHandle
+		unconnected   ErrUnconnectedNodes
+		invalidOrder  ErrInvalidExecutionOrder
+		unproduced    ErrUnproducedVariable
+		tooLarge      ErrWorkflowTooLarge
+		invalidLoop   ErrInvalidLoop
+	)
+	switch {
+	case errors.As(err, &invalidData):
+		return http.StatusBadRequest, invalidData
+	case errors.As(err, &invalidHandle):
+		return http.StatusBadRequest, invalidHandle
+	case errors.As(err, &unconnected):
+		return http.StatusBadRequest, unconnected
+	case errors.As(err, &invalidOrder):
+		return http.StatusBadRequest, invalidOrder
+	case errors.As(err, &unproduced):
+		return http.StatusBadRequest, unproduced
+	case errors.As(err, &tooLarge):
+		return http.StatusBadRequest, tooLarge
+	case errors.As(err, &invalidLoop):
+		return http.StatusBadRequest, invalidLoop
+	}
+	return 0, nil
+}
+
+// writeValidationError writes an error matched by validationStatus, listing the fields at
+// fault when it is invalid input
+func writeValidationError(w http.ResponseWriter, status int, err error) {
+	var invalidInput ErrInvalidInput
+	if errors.As(err, &invalidInput) {
+		writeValidationErrorResponse(w, invalidInput)
+		return
+	}
+	writeErrorResponse(w, status, err.Error())
+}
+
+// isNotFound reports whether err means the requested workflow does not exist
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrWorkflowNotFound)
+}
@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"workflow-code-test/api/pkg/cache"
+)
+
+// IdempotencyKeyHeader carries an integration node's idempotencyKey, so the API can recognise
+// a call it has already handled
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// integrationDedupPrefix namespaces the cached outputs of integration calls made with an idempotency key
+const integrationDedupPrefix = "integration-dedup"
+
+// maxDedupTTLMs is the longest dedupTtlMs a node may configure, one day
+const maxDedupTTLMs = 24 * 60 * 60 * 1000
+
+// idempotencySettings is the parsed idempotency configuration of an integration node
+type idempotencySettings struct {
+	key string
+	// dedupTTL is how long a call's output is kept to answer repeats; zero disables local dedup
+	dedupTTL time.Duration
+}
+
+// parseIdempotencySettings reads idempotencyKey and dedupTtlMs from integration metadata,
+// rendering the key's {{variable}} references. It returns nil when no key is configured.
+func parseIdempotencySettings(metadata Metadata, executeVars *ExecutionContext) (*idempotencySettings, error) {
+	if !metadata.Has("idempotencyKey") {
+		if metadata.Has("dedupTtlMs") {
+			return nil, fmt.Errorf("dedupTtlMs requires idempotencyKey")
+		}
+		return nil, nil
+	}
+
+	template, err := metadata.NonEmptyString("idempotencyKey")
+	if err != nil {
+		return nil, err
+	}
+	key := renderTemplate(template, executeVars.Snapshot())
+	// A key left with a reference would be shared by every call, deduplicating unrelated ones
+	if strings.Contains(key, "{{") {
+		return nil, fmt.Errorf("idempotencyKey '%s' references a variable not found in executeVars", template)
+	}
+	settings := &idempotencySettings{key: key}
+
+	if rawTTL, exists := metadata["dedupTtlMs"]; exists {
+		ttlMs, ok := toFloat64(rawTTL)
+		if !ok || ttlMs < 1 || ttlMs != math.Trunc(ttlMs) {
+			return nil, fmt.Errorf("dedupTtlMs must be a positive integer")
+		}
+		if ttlMs > maxDedupTTLMs {
+			return nil, fmt.Errorf("dedupTtlMs must not exceed %d", maxDedupTTLMs)
+		}
+		settings.dedupTTL = time.Duration(ttlMs) * time.Millisecond
+	}
+
+	return settings, nil
+}
+
+// storedWorkflowKey is the context key marking an execution of a stored workflow
+type storedWorkflowKey struct{}
+
+// withStoredWorkflow marks ctx as running a workflow loaded from the database, whose
+// _workflowId is owned by the server rather than taken from the request
+func withStoredWorkflow(ctx context.Context) context.Context {
+	return context.WithValue(ctx, storedWorkflowKey{}, true)
+}
+
+// isStoredWorkflow reports whether ctx belongs to an execution of a stored workflow
+func isStoredWorkflow(ctx context.Context) bool {
+	stored, _ := ctx.Value(storedWorkflowKey{}).(bool)
+	return stored
+}
+
+// dedupCacheKey scopes an idempotency key to the workflow and node making the call
+func dedupCacheKey(workflowID string, nodeID string, key string) string {
+	return fmt.Sprintf("%s:%s/%s:%s", integrationDedupPrefix, workflowID, nodeID, key)
+}
+
+// replayDeduplicated fills output with the cached output of a call already made with the same
+// idempotency key, reporting whether there was one. A cache error is logged and the call made.
+func (s *Service) replayDeduplicated(ctx context.Context, cacheKey string, output map[string]any) bool {
+	if s.cache == nil {
+		warnStep(ctx, "Cache disabled, idempotent call not deduplicated")
+		return false
+	}
+
+	var previous map[string]any
+	err := s.cache.Get(ctx, cacheKey, &previous)
+	if err != nil {
+		if _, ok := err.(cache.ErrCacheMiss); !ok {
+			slog.WarnContext(ctx, "Failed to get deduplicated call from cache", "error", err)
+		}
+		return false
+	}
+
+	for key, value := range previous {
+		output[key] = value
+	}
+	output["deduplicated"] = true
+	output[NodeMessageKey] = "Call already made with this idempotency key, not repeated"
+	return true
+}
+
+// storeDeduplicated caches the declared outputs of a successful call so repeats within ttl are
+// answered from them. Anything else in output, such as the raw response, is left out of the cache.
+func (s *Service) storeDeduplicated(ctx context.Context, cacheKey string, output map[string]any, declared []string, ttl time.Duration) {
+	if s.cache == nil {
+		return
+	}
+	outputs := make(map[string]any, len(declared))
+	for _, name := range declared {
+		if value, exists := output[name]; exists {
+			outputs[name] = value
+		}
+	}
+	if err := s.cache.Set(ctx, cacheKey, outputs, ttl); err != nil {
+		slog.WarnContext(ctx, "Failed to cache idempotent call", "error", err)
+	}
+}
@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/cache"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIdempotencySettings(t *testing.T) {
+	vars := map[string]any{"orderId": "A-1001", "_executionId": "exec-7"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata Metadata
+
+		expected      *idempotencySettings
+		errorContains string
+	}{
+		"not_set": {
+			metadata: Metadata{},
+		},
+		"key_rendered": {
+			metadata: Metadata{"idempotencyKey": "charge-{{orderId}}-{{_executionId}}"},
+			expected: &idempotencySettings{key: "charge-A-1001-exec-7"},
+		},
+		"dedup_ttl": {
+			metadata: Metadata{"idempotencyKey": "charge-{{orderId}}", "dedupTtlMs": 60000},
+			expected: &idempotencySettings{key: "charge-A-1001", dedupTTL: time.Minute},
+		},
+		"variable_not_found": {
+			metadata:      Metadata{"idempotencyKey": "charge-{{invoiceId}}"},
+			errorContains: "idempotencyKey 'charge-{{invoiceId}}' references a variable not found in executeVars",
+		},
+		"empty_key": {
+			metadata:      Metadata{"idempotencyKey": ""},
+			errorContains: "idempotencyKey must be a non-empty string",
+		},
+		"ttl_without_key": {
+			metadata:      Metadata{"dedupTtlMs": 60000},
+			errorContains: "dedupTtlMs requires idempotencyKey",
+		},
+		"ttl_not_an_integer": {
+			metadata:      Metadata{"idempotencyKey": "charge-{{orderId}}", "dedupTtlMs": 1.5},
+			errorContains: "dedupTtlMs must be a positive integer",
+		},
+		"ttl_too_long": {
+			metadata:      Metadata{"idempotencyKey": "charge-{{orderId}}", "dedupTtlMs": maxDedupTTLMs + 1},
+			errorContains: "dedupTtlMs must not exceed 86400000",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseIdempotencySettings(tc.metadata, NewExecutionContext(vars))
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, settings)
+		})
+	}
+}
+
+func TestExecuteIntegrationNodeIdempotency(t *testing.T) {
+	cacheKey := "integration-dedup:wf-1/charge:charge-A-1001"
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		extra     map[string]any
+		unsaved   bool
+		setupMock func(mockCache *cachemocks.MockCache)
+
+		expectedCalls  int
+		expectedOutput map[string]any
+		errorContains  string
+	}{
+		"key_sent_without_dedup": {
+			extra:          map[string]any{},
+			expectedCalls:  1,
+			expectedOutput: map[string]any{"chargeId": "ch_1"},
+		},
+		"first_call_cached": {
+			extra: map[string]any{"dedupTtlMs": 60000, "persistResponse": true},
+			setupMock: func(mockCache *cachemocks.MockCache) {
+				// Only the declared outputs are cached, not the raw response with the rest of the body
+				mockCache.EXPECT().Get(gomock.Any(), cacheKey, gomock.Any()).Return(cache.ErrCacheMiss{Key: cacheKey})
+				mockCache.EXPECT().Set(gomock.Any(), cacheKey, map[string]any{"chargeId": "ch_1"}, time.Minute).Return(nil)
+			},
+			expectedCalls: 1,
+			expectedOutput: map[string]any{
+				"chargeId":           "ch_1",
+				rawResponseOutputKey: "{\"cardLast4\":\"4242\",\"chargeId\":\"ch_1\"}\n",
+			},
+		},
+		"unsaved_workflow_not_deduplicated": {
+			extra:          map[string]any{"dedupTtlMs": 60000},
+			unsaved:        true,
+			expectedCalls:  1,
+			expectedOutput: map[string]any{"chargeId": "ch_1"},
+		},
+		"repeat_answered_from_cache": {
+			extra: map[string]any{"dedupTtlMs": 60000},
+			setupMock: func(mockCache *cachemocks.MockCache) {
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, dest any) error {
+						return json.Unmarshal([]byte(`{"chargeId":"ch_1"}`), dest)
+					})
+			},
+			expectedCalls: 0,
+			expectedOutput: map[string]any{
+				"chargeId":     "ch_1",
+				"deduplicated": true,
+				NodeMessageKey: "Call already made with this idempotency key, not repeated",
+			},
+		},
+		"fan_out_rejected": {
+			extra: map[string]any{
+				"options": []any{map[string]any{"orderId": "A-1001"}},
+				"fanOut":  true,
+			},
+			errorContains: "idempotencyKey can't be used with fanOut",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				assert.Equal(t, "charge-A-1001", r.Header.Get(IdempotencyKeyHeader))
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]any{"chargeId": "ch_1", "cardLast4": "4242"})
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockCache := cachemocks.NewMockCache(ctrl)
+			if tc.setupMock != nil {
+				tc.setupMock(mockCache)
+			}
+
+			metadata := map[string]any{
+				"inputVariables":  []any{"orderId"},
+				"apiEndpoint":     server.URL + "/charges/{orderId}",
+				"outputVariables": []any{"chargeId"},
+				"idempotencyKey":  "charge-{{orderId}}",
+			}
+			maps.Copy(metadata, tc.extra)
+			node := api.WorkflowNode{Id: "charge", Type: api.WorkflowNodeTypeIntegration, Data: &api.NodeData{Metadata: &metadata}}
+			executeVars := NewExecutionContext(map[string]any{"orderId": "A-1001", ReservedVarWorkflowID: "wf-1"})
+
+			service := &Service{cache: mockCache}
+			output := make(map[string]any)
+
+			ctx := context.Background()
+			if !tc.unsaved {
+				ctx = withStoredWorkflow(ctx)
+			}
+
+			err := service.executeIntegrationNode(ctx, node, executeVars, output)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				assert.Zero(t, calls)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func TestExecuteIntegrationNodeIdempotencyDryRun(t *testing.T) {
+	metadata := map[string]any{
+		"inputVariables":  []any{"orderId"},
+		"apiEndpoint":     "https://payments.example.com/charges/{orderId}",
+		"outputVariables": []any{"chargeId"},
+		"idempotencyKey":  "charge-{{orderId}}",
+		"dedupTtlMs":      60000,
+	}
+	node := api.WorkflowNode{Id: "charge", Type: api.WorkflowNodeTypeIntegration, Data: &api.NodeData{Metadata: &metadata}}
+
+	// A dry run neither calls the API nor reads or writes the cache
+	service := &Service{}
+	output := make(map[string]any)
+
+	err := service.executeIntegrationNode(withDryRun(context.Background()), node, NewExecutionContext(map[string]any{"orderId": "A-1001"}), output)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"method":         "GET",
+		"url":            "https://payments.example.com/charges/A-1001",
+		"idempotencyKey": "charge-A-1001",
+	}, output["request"])
+}
@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// ErrInvalidInput is returned when an execution input parses but can't be run as given, e.g.
+// a condition with an unknown operator. Each field error names the input field at fault.
+type ErrInvalidInput struct {
+	Fields []api.FieldError
+}
+
+func (e ErrInvalidInput) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return "invalid execution input: " + strings.Join(messages, "; ")
+}
+
+// rawCondition records the condition fields an execute request sets. api.Condition decodes an
+// omitted threshold as 0, so its presence is read separately.
+type rawCondition struct {
+	Threshold *float64 `json:"threshold"`
+}
+
+// thresholdMissingMessage is reported for a condition that needs a threshold but has none
+const thresholdMissingMessage = "threshold is required unless operator is truthy"
+
+// thresholdOmittedKey is the context key marking an execution whose input condition left out
+// both its operator and its threshold
+type thresholdOmittedKey struct{}
+
+// isThresholdOmitted reports whether ctx belongs to an execution marked by validateConditionInput
+func isThresholdOmitted(ctx context.Context) bool {
+	omitted, _ := ctx.Value(thresholdOmittedKey{}).(bool)
+	return omitted
+}
+
+// validateConditionInput checks an execution's condition before the workflow is loaded. The
+// operator must be a known one, and the threshold is required unless the operator is truthy,
+// which has none. It returns an error for each field. A condition leaving out the operator
+// takes each condition node's operator, so without a threshold the check is left until the
+// workflow is loaded: the returned context marks it for validateExecutionInput.
+func validateConditionInput(ctx context.Context, condition *api.Condition, raw rawCondition) (context.Context, []api.FieldError) {
+	if condition == nil {
+		return ctx, nil
+	}
+
+	var fields []api.FieldError
+	switch {
+	case condition.Operator == "":
+		if raw.Threshold == nil {
+			ctx = context.WithValue(ctx, thresholdOmittedKey{}, true)
+		}
+	case condition.Operator == api.Truthy:
+		// Truthy branches on the field itself and has no threshold
+	default:
+		if _, err := parseConditionOperator(string(condition.Operator)); err != nil {
+			fields = append(fields, api.FieldError{Field: "condition.operator", Message: err.Error()})
+		}
+		if raw.Threshold == nil {
+			fields = append(fields, api.FieldError{Field: "condition.threshold", Message: thresholdMissingMessage})
+		}
+	}
+	return ctx, fields
+}
+
+// validateExecutionInput checks the execution input against the loaded workflow before any
+// node runs, reporting every field at fault at once
+func validateExecutionInput(ctx context.Context, workflow api.Workflow, input api.WorkflowExecutionInput) error {
+	if workflow.Nodes == nil {
+		return nil
+	}
+
+	fields := validateFormInput(workflow, input)
+	if isThresholdOmitted(ctx) && input.Condition != nil {
+		fields = append(fields, validateDefaultOperatorThreshold(workflow, *input.Condition)...)
+	}
+
+	if len(fields) > 0 {
+		return ErrInvalidInput{Fields: fields}
+	}
+	return nil
+}
+
+// validateDefaultOperatorThreshold finishes the check validateConditionInput defers: a condition
+// without an operator or threshold takes each condition node's operator, so it only needs a
+// threshold when one of those operators is not truthy. A node without a valid operator is left
+// to fail its own step.
+func validateDefaultOperatorThreshold(workflow api.Workflow, condition api.Condition) []api.FieldError {
+	for _, node := range *workflow.Nodes {
+		if node.Type != api.WorkflowNodeTypeCondition {
+			continue
+		}
+		merged, err := withDefaultOperator(node, condition)
+		if err != nil || merged.Operator == api.Truthy {
+			continue
+		}
+		return []api.FieldError{{
+			Field:   "condition.threshold",
+			Message: fmt.Sprintf("%s; condition node '%s' uses %s", thresholdMissingMessage, node.Id, merged.Operator),
+		}}
+	}
+	return nil
+}
+
+// validateFormInput applies the requiredWhen rules of the workflow's form nodes to the form
+// input and workflow defaults, so a missing field is reported before any node runs. Rules
+// naming a variable another node produces are left to the form node, as the value may only
+// exist once it runs, and so are malformed rules, which fail the node's step.
+func validateFormInput(workflow api.Workflow, input api.WorkflowExecutionInput) []api.FieldError {
+
+	// Form nodes only pass input through, so they don't count as producing it
+	produced := make(map[string]bool)
+	for _, node := range *workflow.Nodes {
+		if node.Type == api.WorkflowNodeTypeForm {
+			continue
+		}
+		for _, name := range producedVariables(node) {
+			produced[name] = true
+		}
+	}
+
+	vars := NewExecutionContext(nil)
+	if workflow.Variables != nil {
+		vars.Merge(withoutHeaderVariables(*workflow.Variables))
+	}
+	if input.FormData != nil {
+		vars.Merge(withoutHeaderVariables(*input.FormData))
+	}
+
+	var fields []api.FieldError
+	reported := make(map[string]bool)
+	for _, node := range *workflow.Nodes {
+		if node.Type != api.WorkflowNodeTypeForm {
+			continue
+		}
+		rules, err := parseRequiredWhen(nodeMetadata(node))
+		if err != nil {
+			continue
+		}
+		for _, rule := range rules {
+			if produced[rule.field] || produced[rule.when] || reported[rule.field] || !rule.missing(vars) {
+				continue
+			}
+			reported[rule.field] = true
+			fields = append(fields, api.FieldError{
+				Field:   "formData." + rule.field,
+				Message: fmt.Sprintf("required when '%s' is %v", rule.when, rule.equals),
+			})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,185 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConditionInput(t *testing.T) {
+	threshold := 25.0
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		condition *api.Condition
+		raw       rawCondition
+
+		expected         []api.FieldError
+		expectedDeferred bool
+	}{
+		"no_condition": {},
+		"valid": {
+			condition: &api.Condition{Operator: api.GreaterThan, Threshold: 25},
+			raw:       rawCondition{Threshold: &threshold},
+		},
+		"operator_omitted": {
+			condition: &api.Condition{Threshold: 25},
+			raw:       rawCondition{Threshold: &threshold},
+		},
+		"operator_and_threshold_omitted": {
+			condition:        &api.Condition{},
+			expectedDeferred: true,
+		},
+		"truthy_without_threshold": {
+			condition: &api.Condition{Operator: api.Truthy},
+		},
+		"unknown_operator": {
+			condition: &api.Condition{Operator: "between", Threshold: 25},
+			raw:       rawCondition{Threshold: &threshold},
+			expected: []api.FieldError{{
+				Field:   "condition.operator",
+				Message: "operator must be one of greater_than, less_than, equals, not_equals, greater_than_or_equal, less_than_or_equal or truthy",
+			}},
+		},
+		"threshold_missing": {
+			condition: &api.Condition{Operator: api.LessThan},
+			expected: []api.FieldError{{
+				Field:   "condition.threshold",
+				Message: "threshold is required unless operator is truthy",
+			}},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, fields := validateConditionInput(context.Background(), tc.condition, tc.raw)
+			assert.Equal(t, tc.expected, fields)
+			assert.Equal(t, tc.expectedDeferred, isThresholdOmitted(ctx))
+		})
+	}
+}
+
+func TestValidateFormInput(t *testing.T) {
+	formNode := requiredWhenFormNode([]any{map[string]any{"field": "email", "when": "notify", "equals": true}})
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		nodes     []api.WorkflowNode
+		variables map[string]any
+		formData  map[string]any
+
+		expected []api.FieldError
+	}{
+		"field_present": {
+			nodes:    []api.WorkflowNode{formNode},
+			formData: map[string]any{"notify": true, "email": "ada@example.com"},
+		},
+		"rule_not_applicable": {
+			nodes:    []api.WorkflowNode{formNode},
+			formData: map[string]any{"notify": false},
+		},
+		"field_missing": {
+			nodes:    []api.WorkflowNode{formNode},
+			formData: map[string]any{"notify": true},
+			expected: []api.FieldError{{Field: "formData.email", Message: "required when 'notify' is true"}},
+		},
+		"workflow_default_applies": {
+			nodes:     []api.WorkflowNode{formNode},
+			variables: map[string]any{"notify": true},
+			expected:  []api.FieldError{{Field: "formData.email", Message: "required when 'notify' is true"}},
+		},
+		"field_produced_by_another_node": {
+			nodes: []api.WorkflowNode{
+				formNode,
+				{Id: "lookup", Type: api.WorkflowNodeTypeIntegration, Data: &api.NodeData{
+					Metadata: &map[string]any{"outputVariables": []any{"email"}},
+				}},
+			},
+			formData: map[string]any{"notify": true},
+		},
+		"malformed_rule_left_to_node": {
+			nodes:    []api.WorkflowNode{requiredWhenFormNode([]any{"email"})},
+			formData: map[string]any{"notify": true},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			workflow := api.Workflow{Nodes: &tc.nodes}
+			if tc.variables != nil {
+				workflow.Variables = &tc.variables
+			}
+			input := api.WorkflowExecutionInput{}
+			if tc.formData != nil {
+				input.FormData = &tc.formData
+			}
+
+			assert.Equal(t, tc.expected, validateFormInput(workflow, input))
+		})
+	}
+}
+
+func TestValidateExecutionInputDefaultOperator(t *testing.T) {
+	conditionNode := func(operator string) api.WorkflowNode {
+		metadata := map[string]any{"operator": operator}
+		return api.WorkflowNode{Id: "check", Type: api.WorkflowNodeTypeCondition, Data: &api.NodeData{Metadata: &metadata}}
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		node     api.WorkflowNode
+		deferred bool
+
+		expected []api.FieldError
+	}{
+		"truthy_node_needs_no_threshold": {
+			node:     conditionNode("truthy"),
+			deferred: true,
+		},
+		"numeric_node_needs_threshold": {
+			node:     conditionNode("greater_than"),
+			deferred: true,
+			expected: []api.FieldError{{
+				Field:   "condition.threshold",
+				Message: "threshold is required unless operator is truthy; condition node 'check' uses greater_than",
+			}},
+		},
+		"node_without_operator_left_to_step": {
+			node:     api.WorkflowNode{Id: "check", Type: api.WorkflowNodeTypeCondition},
+			deferred: true,
+		},
+		"threshold_sent": {
+			node: conditionNode("greater_than"),
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			raw := rawCondition{Threshold: new(float64)}
+			if tc.deferred {
+				raw = rawCondition{}
+			}
+			ctx, fields := validateConditionInput(ctx, &api.Condition{}, raw)
+			require.Empty(t, fields)
+
+			workflow := api.Workflow{Nodes: &[]api.WorkflowNode{tc.node}}
+			err := validateExecutionInput(ctx, workflow, api.WorkflowExecutionInput{Condition: &api.Condition{}})
+
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			var invalid ErrInvalidInput
+			require.ErrorAs(t, err, &invalid)
+			assert.Equal(t, tc.expected, invalid.Fields)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// jitterSource draws the randomized retry delays of one execution. Parallel branches retry
+// concurrently, so draws are guarded by mu. A nil source draws from the global generator.
+type jitterSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newJitterSource returns the source for a new execution. With a configured seed every
+// execution draws the same sequence, which keeps retry delays reproducible in tests.
+func (s *Service) newJitterSource() *jitterSource {
+	if s.jitterSeed != nil {
+		return &jitterSource{rng: rand.New(rand.NewPCG(*s.jitterSeed, 0))}
+	}
+	return &jitterSource{rng: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+}
+
+// upTo returns a random duration between 0 and limit inclusive
+func (j *jitterSource) upTo(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	if j == nil {
+		return time.Duration(rand.Int64N(int64(limit) + 1))
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return time.Duration(j.rng.Int64N(int64(limit) + 1))
+}
+
+// jitterSourceKey is the context key carrying the jitter source of the current execution
+type jitterSourceKey struct{}
+
+// withJitterSource returns a context whose retries draw their jitter from source
+func withJitterSource(ctx context.Context, source *jitterSource) context.Context {
+	return context.WithValue(ctx, jitterSourceKey{}, source)
+}
+
+// jitterSourceFromContext returns the jitter source carried by ctx, if any
+func jitterSourceFromContext(ctx context.Context) *jitterSource {
+	source, _ := ctx.Value(jitterSourceKey{}).(*jitterSource)
+	return source
+}
+
+// retryDelay returns the wait after a failed attempt: backoff doubled for each earlier failure,
+// capped at maxDelay. With jitter the wait is drawn between 0 and that interval ("full jitter"),
+// so executions that failed together don't all retry at the same moment.
+func retryDelay(ctx context.Context, backoff time.Duration, attempt int, maxDelay time.Duration, jitter bool) time.Duration {
+	delay := backoff * time.Duration(1<<(attempt-1))
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if !jitter {
+		return delay
+	}
+	return jitterSourceFromContext(ctx).upTo(delay)
+}
@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		backoff time.Duration
+		attempt int
+		jitter  bool
+
+		expectedDelay time.Duration
+		expectedMax   time.Duration
+	}{
+		"first_retry_waits_backoff": {
+			backoff:       time.Second,
+			attempt:       1,
+			expectedDelay: time.Second,
+		},
+		"backoff_doubles_per_attempt": {
+			backoff:       time.Second,
+			attempt:       3,
+			expectedDelay: 4 * time.Second,
+		},
+		"delay_is_capped": {
+			backoff:       20 * time.Second,
+			attempt:       2,
+			expectedDelay: maxEmailDelay,
+		},
+		"jitter_stays_within_interval": {
+			backoff:     time.Second,
+			attempt:     3,
+			jitter:      true,
+			expectedMax: 4 * time.Second,
+		},
+		"jitter_stays_within_cap": {
+			backoff:     20 * time.Second,
+			attempt:     4,
+			jitter:      true,
+			expectedMax: maxEmailDelay,
+		},
+		"jitter_without_backoff": {
+			attempt:       2,
+			jitter:        true,
+			expectedDelay: 0,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for range 50 {
+				delay := retryDelay(context.Background(), tc.backoff, tc.attempt, maxEmailDelay, tc.jitter)
+				if tc.expectedMax == 0 {
+					assert.Equal(t, tc.expectedDelay, delay)
+					continue
+				}
+				assert.GreaterOrEqual(t, delay, time.Duration(0))
+				assert.LessOrEqual(t, delay, tc.expectedMax)
+			}
+		})
+	}
+}
+
+func TestRetryDelaySeededJitter(t *testing.T) {
+	draw := func(service *Service) []time.Duration {
+		ctx := withJitterSource(context.Background(), service.newJitterSource())
+		delays := make([]time.Duration, 0, 5)
+		for attempt := 1; attempt <= 5; attempt++ {
+			delays = append(delays, retryDelay(ctx, time.Second, attempt, maxEmailDelay, true))
+		}
+		return delays
+	}
+
+	// Executions of a seeded service draw the same delays, so retries are reproducible
+	seed, otherSeed := uint64(1), uint64(7)
+	seeded := &Service{jitterSeed: &seed}
+	first := draw(seeded)
+	assert.Equal(t, first, draw(seeded))
+	assert.NotEqual(t, first, draw(&Service{jitterSeed: &otherSeed}))
+
+	// Delays are spread out rather than fixed at the backoff interval
+	assert.NotEqual(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}, first)
+}
@@ -0,0 +1,272 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	api "workflow-code-test/api/openapi"
+)
+
+const (
+	// DefaultLoopItemVar holds the current element when no itemVar is configured
+	DefaultLoopItemVar = "item"
+	// DefaultLoopOutputVar receives the iteration outputs when no outputVar is configured
+	DefaultLoopOutputVar = "loopResults"
+	// ReservedVarLoopIndex holds the zero-based index of the current element inside a loop body
+	ReservedVarLoopIndex = "_loopIndex"
+)
+
+// Bounds on the number of elements a loop node runs its body for
+const (
+	DefaultMaxLoopIterations = 100
+	MaxLoopIterations        = 1000
+)
+
+// loopBodyTypes are the node types a loop may run once per element
+var loopBodyTypes = []api.WorkflowNodeType{
+	api.WorkflowNodeTypeIntegration,
+	api.WorkflowNodeTypeEmail,
+	api.WorkflowNodeTypeAggregate,
+}
+
+// ErrInvalidLoop is returned when a loop node's body can't be run once per element
+type ErrInvalidLoop struct {
+	NodeID string
+	Reason string
+}
+
+func (e ErrInvalidLoop) Error() string {
+	return fmt.Sprintf("invalid loop node '%s': %s", e.NodeID, e.Reason)
+}
+
+// loopSettings is the parsed configuration of a loop node
+type loopSettings struct {
+	itemsVar      string
+	itemVar       string
+	body          string
+	outputVar     string
+	maxIterations int
+}
+
+// parseLoopSettings reads itemsVar, itemVar, body, outputVar and maxIterations from loop metadata
+func parseLoopSettings(metadata Metadata) (loopSettings, error) {
+	settings := loopSettings{itemVar: DefaultLoopItemVar, outputVar: DefaultLoopOutputVar, maxIterations: DefaultMaxLoopIterations}
+
+	for _, key := range []string{"itemsVar", "body"} {
+		if !metadata.Has(key) {
+			return loopSettings{}, fmt.Errorf("loop node missing %s in metadata", key)
+		}
+	}
+	var err error
+	if settings.itemsVar, err = metadata.NonEmptyString("itemsVar"); err != nil {
+		return loopSettings{}, err
+	}
+	if settings.body, err = metadata.NonEmptyString("body"); err != nil {
+		return loopSettings{}, err
+	}
+
+	for _, field := range []struct {
+		key    string
+		target *string
+	}{
+		{"itemVar", &settings.itemVar},
+		{"outputVar", &settings.outputVar},
+	} {
+		value, err := metadata.NonEmptyString(field.key)
+		if err != nil {
+			return loopSettings{}, err
+		}
+		if isReservedVariable(value) {
+			return loopSettings{}, fmt.Errorf("%s '%s' is reserved", field.key, value)
+		}
+		if value != "" {
+			*field.target = value
+		}
+	}
+
+	if rawMax, exists := metadata["maxIterations"]; exists {
+		maxIterations, ok := toFloat64(rawMax)
+		if !ok || maxIterations < 1 || maxIterations != math.Trunc(maxIterations) {
+			return loopSettings{}, fmt.Errorf("maxIterations must be a positive integer")
+		}
+		if maxIterations > MaxLoopIterations {
+			return loopSettings{}, fmt.Errorf("maxIterations must not exceed %d", MaxLoopIterations)
+		}
+		settings.maxIterations = int(maxIterations)
+	}
+
+	return settings, nil
+}
+
+// executeLoopNode runs the loop's body node once per element of the itemsVar array, each time
+// with the element bound to itemVar, and stores the body's outputs in order in outputVar. The
+// body runs on a copy of the variables, so one iteration's output never reaches the next or the
+// rest of the workflow. The first failed iteration fails the loop.
+func (s *Service) executeLoopNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, input api.WorkflowExecutionInput, output map[string]any) error {
+	// Check if node has metadata
+	if node.Data == nil || node.Data.Metadata == nil {
+		return fmt.Errorf("loop node missing metadata")
+	}
+
+	settings, err := parseLoopSettings(nodeMetadata(node))
+	if err != nil {
+		return err
+	}
+
+	body, exists := workflowNode(ctx, settings.body)
+	if !exists {
+		return fmt.Errorf("loop body node '%s' not found", settings.body)
+	}
+
+	rawItems, exists := executeVars.GetPath(settings.itemsVar)
+	if !exists {
+		return fmt.Errorf("loop variable '%s' not found in executeVars", settings.itemsVar)
+	}
+	items, ok := rawItems.([]any)
+	if !ok {
+		return fmt.Errorf("loop variable '%s' must be an array", settings.itemsVar)
+	}
+	if len(items) > settings.maxIterations {
+		return fmt.Errorf("loop variable '%s' has %d items, more than maxIterations %d", settings.itemsVar, len(items), settings.maxIterations)
+	}
+
+	results := make([]any, 0, len(items))
+	for i, item := range items {
+		// Stop between iterations once the caller has gone or the request deadline has passed
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("loop stopped at iteration %d: %w", i, err)
+		}
+
+		iterationVars := NewExecutionContext(executeVars.Snapshot())
+		if executeVars.ConditionEvaluated() {
+			iterationVars.MarkConditionEvaluated()
+		}
+		bindLoopItem(iterationVars, settings.itemVar, item)
+		iterationVars.Set(ReservedVarLoopIndex, i)
+
+		step := s.executeSingleNode(ctx, body, iterationVars, input)
+		if step.Warnings != nil {
+			for _, message := range *step.Warnings {
+				warnStep(ctx, message, "iteration", i)
+			}
+		}
+		if step.Error != nil {
+			return fmt.Errorf("iteration %d of '%s' failed: %s", i, body.Id, *step.Error)
+		}
+
+		result := make(map[string]any)
+		if step.Output != nil {
+			mergeIterationOutput(result, *step.Output)
+		}
+		results = append(results, result)
+	}
+
+	output[settings.outputVar] = results
+	output["iterations"] = len(items)
+	output["message"] = fmt.Sprintf("Ran '%s' for %d items", body.Id, len(items))
+
+	return nil
+}
+
+// bindLoopItem stores the current element under itemVar. The fields of an object element are
+// also stored as itemVar.field, so templates can reference them as {{item.email}}.
+func bindLoopItem(iterationVars *ExecutionContext, itemVar string, item any) {
+	iterationVars.Set(itemVar, item)
+	if fields, ok := item.(map[string]any); ok {
+		for key, value := range fields {
+			iterationVars.Set(itemVar+"."+key, value)
+		}
+	}
+}
+
+// mergeIterationOutput copies a body step's output into an iteration result, leaving out
+// reserved keys such as the node message
+func mergeIterationOutput(result map[string]any, output map[string]any) {
+	for key, value := range output {
+		if !isReservedVariable(key) {
+			result[key] = value
+		}
+	}
+}
+
+// validateLoops checks that every loop node's body exists, has a type that can run once per
+// element and is not connected by edges, which would also run it outside the loop
+func validateLoops(workflow api.Workflow) error {
+	if workflow.Nodes == nil {
+		return nil
+	}
+
+	nodeMap := make(map[string]api.WorkflowNode)
+	for _, node := range *workflow.Nodes {
+		nodeMap[node.Id] = node
+	}
+	connected := make(map[string]bool)
+	if workflow.Edges != nil {
+		for _, edge := range *workflow.Edges {
+			connected[edge.Source] = true
+			connected[edge.Target] = true
+		}
+	}
+
+	for _, node := range *workflow.Nodes {
+		if node.Type != api.WorkflowNodeTypeLoop {
+			continue
+		}
+		settings, err := parseLoopSettings(nodeMetadata(node))
+		if err != nil {
+			return ErrInvalidLoop{NodeID: node.Id, Reason: err.Error()}
+		}
+		body, exists := nodeMap[settings.body]
+		if !exists {
+			return ErrInvalidLoop{NodeID: node.Id, Reason: fmt.Sprintf("body node '%s' does not exist", settings.body)}
+		}
+		if !slices.Contains(loopBodyTypes, body.Type) {
+			return ErrInvalidLoop{NodeID: node.Id, Reason: fmt.Sprintf("body node '%s' is a %s node, expected integration, email or aggregate", body.Id, body.Type)}
+		}
+		if connected[body.Id] {
+			return ErrInvalidLoop{NodeID: node.Id, Reason: fmt.Sprintf("body node '%s' must not be connected by edges", body.Id)}
+		}
+	}
+
+	return nil
+}
+
+// loopBodies maps the ID of each loop body node to the loop node that runs it
+func loopBodies(nodes []api.WorkflowNode) map[string]api.WorkflowNode {
+	bodies := make(map[string]api.WorkflowNode)
+	for _, node := range nodes {
+		if node.Type != api.WorkflowNodeTypeLoop {
+			continue
+		}
+		if body, ok := nodeMetadata(node).String("body"); ok {
+			bodies[body] = node
+		}
+	}
+	return bodies
+}
+
+// loopItemVar returns the variable a loop node binds each element to
+func loopItemVar(node api.WorkflowNode) string {
+	if itemVar, ok := nodeMetadata(node).String("itemVar"); ok && itemVar != "" {
+		return itemVar
+	}
+	return DefaultLoopItemVar
+}
+
+// workflowNodesKey is the context key carrying the nodes of the workflow being executed
+type workflowNodesKey struct{}
+
+// withWorkflowNodes returns a context carrying the workflow's nodes by ID, so a loop node can
+// find its body
+func withWorkflowNodes(ctx context.Context, nodeMap map[string]api.WorkflowNode) context.Context {
+	return context.WithValue(ctx, workflowNodesKey{}, nodeMap)
+}
+
+// workflowNode returns the node of the workflow being executed with the given ID
+func workflowNode(ctx context.Context, nodeID string) (api.WorkflowNode, bool) {
+	nodeMap, _ := ctx.Value(workflowNodesKey{}).(map[string]api.WorkflowNode)
+	node, exists := nodeMap[nodeID]
+	return node, exists
+}
@@ -0,0 +1,301 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestParseLoopSettings(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata Metadata
+
+		expected      loopSettings
+		errorContains string
+	}{
+		"defaults": {
+			metadata: Metadata{"itemsVar": "subscribers", "body": "notify"},
+			expected: loopSettings{itemsVar: "subscribers", itemVar: DefaultLoopItemVar, body: "notify", outputVar: DefaultLoopOutputVar, maxIterations: DefaultMaxLoopIterations},
+		},
+		"configured": {
+			metadata: Metadata{"itemsVar": "subscribers", "itemVar": "subscriber", "body": "notify", "outputVar": "notified", "maxIterations": 5},
+			expected: loopSettings{itemsVar: "subscribers", itemVar: "subscriber", body: "notify", outputVar: "notified", maxIterations: 5},
+		},
+		"missing_items_var": {
+			metadata:      Metadata{"body": "notify"},
+			errorContains: "loop node missing itemsVar in metadata",
+		},
+		"missing_body": {
+			metadata:      Metadata{"itemsVar": "subscribers"},
+			errorContains: "loop node missing body in metadata",
+		},
+		"empty_body": {
+			metadata:      Metadata{"itemsVar": "subscribers", "body": ""},
+			errorContains: "body must be a non-empty string",
+		},
+		"reserved_item_var": {
+			metadata:      Metadata{"itemsVar": "subscribers", "body": "notify", "itemVar": "_item"},
+			errorContains: "itemVar '_item' is reserved",
+		},
+		"fractional_max_iterations": {
+			metadata:      Metadata{"itemsVar": "subscribers", "body": "notify", "maxIterations": 2.5},
+			errorContains: "maxIterations must be a positive integer",
+		},
+		"max_iterations_too_high": {
+			metadata:      Metadata{"itemsVar": "subscribers", "body": "notify", "maxIterations": MaxLoopIterations + 1},
+			errorContains: "maxIterations must not exceed 1000",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseLoopSettings(tc.metadata)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, settings)
+		})
+	}
+}
+
+func TestExecuteLoopNode(t *testing.T) {
+	addOffset := api.WorkflowNode{
+		Id:   "add-offset",
+		Type: api.WorkflowNodeTypeAggregate,
+		Data: &api.NodeData{Metadata: &map[string]any{
+			"inputVariables": []any{"reading", "offset"},
+			"operation":      "sum",
+			"outputVariable": "adjusted",
+		}},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		metadata map[string]any
+		vars     map[string]any
+
+		expectedOutput map[string]any
+		errorContains  string
+	}{
+		"runs_body_per_item": {
+			metadata: map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset"},
+			vars:     map[string]any{"readings": []any{1.0, 2.5}, "offset": 10},
+			expectedOutput: map[string]any{
+				"loopResults": []any{
+					map[string]any{"adjusted": 11.0, "message": "Computed sum of 2 values: 11"},
+					map[string]any{"adjusted": 12.5, "message": "Computed sum of 2 values: 12.5"},
+				},
+				"iterations": 2,
+				"message":    "Ran 'add-offset' for 2 items",
+			},
+		},
+		"empty_array": {
+			metadata: map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset", "outputVar": "adjustedReadings"},
+			vars:     map[string]any{"readings": []any{}, "offset": 10},
+			expectedOutput: map[string]any{
+				"adjustedReadings": []any{},
+				"iterations":       0,
+				"message":          "Ran 'add-offset' for 0 items",
+			},
+		},
+		"items_from_path": {
+			metadata: map[string]any{"itemsVar": "response.readings", "itemVar": "reading", "body": "add-offset"},
+			vars:     map[string]any{"response": map[string]any{"readings": []any{4.0}}, "offset": 1},
+			expectedOutput: map[string]any{
+				"loopResults": []any{map[string]any{"adjusted": 5.0, "message": "Computed sum of 2 values: 5"}},
+				"iterations":  1,
+				"message":     "Ran 'add-offset' for 1 items",
+			},
+		},
+		"too_many_items": {
+			metadata:      map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset", "maxIterations": 2},
+			vars:          map[string]any{"readings": []any{1, 2, 3}, "offset": 10},
+			errorContains: "loop variable 'readings' has 3 items, more than maxIterations 2",
+		},
+		"items_not_an_array": {
+			metadata:      map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset"},
+			vars:          map[string]any{"readings": "1,2", "offset": 10},
+			errorContains: "loop variable 'readings' must be an array",
+		},
+		"items_missing": {
+			metadata:      map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset"},
+			vars:          map[string]any{"offset": 10},
+			errorContains: "loop variable 'readings' not found in executeVars",
+		},
+		"body_missing": {
+			metadata:      map[string]any{"itemsVar": "readings", "body": "missing"},
+			vars:          map[string]any{"readings": []any{1}},
+			errorContains: "loop body node 'missing' not found",
+		},
+		"iteration_fails": {
+			metadata:      map[string]any{"itemsVar": "readings", "itemVar": "reading", "body": "add-offset"},
+			vars:          map[string]any{"readings": []any{1, "high"}, "offset": 10},
+			errorContains: "iteration 1 of 'add-offset' failed: aggregate variable 'reading' is not numeric: high",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{}
+			node := api.WorkflowNode{Id: "loop", Type: api.WorkflowNodeTypeLoop, Data: &api.NodeData{Metadata: &tc.metadata}}
+			ctx := withWorkflowNodes(context.Background(), map[string]api.WorkflowNode{addOffset.Id: addOffset})
+			executeVars := NewExecutionContext(tc.vars)
+			output := make(map[string]any)
+
+			err := service.executeLoopNode(ctx, node, executeVars, api.WorkflowExecutionInput{}, output)
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, output)
+
+			// Iterations run on copies, so neither the item nor the body's output leaks out
+			_, exists := executeVars.Get("adjusted")
+			assert.False(t, exists)
+			_, exists = executeVars.Get("reading")
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestExecuteLoopNodeBindsObjectFields(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"temperature": len(requested)})
+	}))
+	defer server.Close()
+
+	forecast := api.WorkflowNode{
+		Id:   "forecast",
+		Type: api.WorkflowNodeTypeIntegration,
+		Data: &api.NodeData{Metadata: &map[string]any{
+			"inputVariables":  []any{"site.city"},
+			"apiEndpoint":     server.URL + "/forecast?city={{site.city}}&index={{_loopIndex}}",
+			"outputVariables": []any{"temperature"},
+		}},
+	}
+	metadata := map[string]any{"itemsVar": "sites", "itemVar": "site", "body": "forecast", "outputVar": "forecasts"}
+	node := api.WorkflowNode{Id: "loop", Type: api.WorkflowNodeTypeLoop, Data: &api.NodeData{Metadata: &metadata}}
+	executeVars := NewExecutionContext(map[string]any{"sites": []any{
+		map[string]any{"city": "Sydney"},
+		map[string]any{"city": "Alice Springs"},
+	}})
+	output := make(map[string]any)
+
+	ctx := withWorkflowNodes(context.Background(), map[string]api.WorkflowNode{forecast.Id: forecast})
+	err := (&Service{}).executeLoopNode(ctx, node, executeVars, api.WorkflowExecutionInput{}, output)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"city=Sydney&index=0", "city=Alice%20Springs&index=1"}, requested)
+	assert.Equal(t, []any{
+		map[string]any{"temperature": 1.0},
+		map[string]any{"temperature": 2.0},
+	}, output["forecasts"])
+}
+
+func TestExecuteWorkflowStepsLoop(t *testing.T) {
+	workflow := api.Workflow{
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			{Id: "loop", Type: api.WorkflowNodeTypeLoop, Data: &api.NodeData{Metadata: &map[string]any{
+				"itemsVar": "readings", "itemVar": "reading", "body": "double",
+			}}},
+			{Id: "double", Type: api.WorkflowNodeTypeAggregate, Data: &api.NodeData{Metadata: &map[string]any{
+				"inputVariables": []any{"reading", "reading"}, "operation": "sum", "outputVariable": "doubled",
+			}}},
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{
+			{Id: "e1", Source: "start", Target: "loop"},
+			{Id: "e2", Source: "loop", Target: "end"},
+		},
+		Variables: &map[string]any{"readings": []any{1, 2}},
+	}
+
+	steps, _, err := (&Service{}).executeWorkflowSteps(context.Background(), workflow, api.WorkflowExecutionInput{})
+	require.NoError(t, err)
+
+	// The body runs inside the loop's step, never as a step of its own
+	nodeIDs := make([]string, 0, len(steps))
+	for _, step := range steps {
+		nodeIDs = append(nodeIDs, step.NodeId)
+	}
+	assert.Equal(t, []string{"start", "loop", "end"}, nodeIDs)
+	assert.Equal(t, 2, (*steps[1].Output)["iterations"])
+}
+
+func TestValidateLoops(t *testing.T) {
+	loopNode := func(body string) api.WorkflowNode {
+		return api.WorkflowNode{Id: "loop", Type: api.WorkflowNodeTypeLoop, Data: &api.NodeData{Metadata: &map[string]any{
+			"itemsVar": "subscribers", "body": body,
+		}}}
+	}
+	emailNode := api.WorkflowNode{Id: "notify", Type: api.WorkflowNodeTypeEmail}
+	baseEdges := []api.WorkflowEdge{{Id: "e1", Source: "start", Target: "loop"}}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		nodes []api.WorkflowNode
+		edges []api.WorkflowEdge
+
+		expectedError string
+	}{
+		"valid_body": {
+			nodes: []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, loopNode("notify"), emailNode},
+			edges: baseEdges,
+		},
+		"missing_settings": {
+			nodes:         []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "loop", Type: api.WorkflowNodeTypeLoop}},
+			edges:         baseEdges,
+			expectedError: "invalid loop node 'loop': loop node missing itemsVar in metadata",
+		},
+		"body_does_not_exist": {
+			nodes:         []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, loopNode("notify")},
+			edges:         baseEdges,
+			expectedError: "invalid loop node 'loop': body node 'notify' does not exist",
+		},
+		"body_type_not_supported": {
+			nodes:         []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, loopNode("start")},
+			edges:         baseEdges,
+			expectedError: "invalid loop node 'loop': body node 'start' is a start node, expected integration, email or aggregate",
+		},
+		"body_connected": {
+			nodes:         []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, loopNode("notify"), emailNode},
+			edges:         append(baseEdges, api.WorkflowEdge{Id: "e2", Source: "loop", Target: "notify"}),
+			expectedError: "invalid loop node 'loop': body node 'notify' must not be connected by edges",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateLoops(api.Workflow{Nodes: &tc.nodes, Edges: &tc.edges})
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrInvalidLoop{})
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
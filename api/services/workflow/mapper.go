@@ -3,15 +3,28 @@ package workflow
 import (
 	"encoding/json"
 	"fmt"
-	"time"
+	"log/slog"
+	"slices"
+	"strings"
 
 	api "workflow-code-test/api/openapi"
 	"workflow-code-test/api/pkg/db/models"
 
+	"github.com/aarondl/null/v8"
 	"github.com/google/uuid"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// ErrInvalidNodePosition is returned when a node's position has non-numeric coordinates
+type ErrInvalidNodePosition struct {
+	NodeID string
+	Reason string
+}
+
+func (e ErrInvalidNodePosition) Error() string {
+	return fmt.Sprintf("invalid position for node '%s': %s", e.NodeID, e.Reason)
+}
+
 // MapDBWorkflowToAPI converts a database workflow model to API workflow model
 func MapDBWorkflowToAPI(dbWorkflow *models.Workflow) (*api.Workflow, error) {
 	if dbWorkflow == nil {
@@ -38,6 +51,33 @@ func MapDBWorkflowToAPI(dbWorkflow *models.Workflow) (*api.Workflow, error) {
 		apiWorkflow.Description = &dbWorkflow.Description.String
 	}
 
+	// Map variable defaults if present
+	if dbWorkflow.Variables.Valid && dbWorkflow.Variables.JSON != nil {
+		var variables map[string]interface{}
+		if err := json.Unmarshal(dbWorkflow.Variables.JSON, &variables); err != nil {
+			return nil, fmt.Errorf("invalid workflow variables: %v", err)
+		}
+		if variables != nil {
+			apiWorkflow.Variables = &variables
+		}
+	}
+
+	// Map the explicit execution order if present
+	if dbWorkflow.ExecutionOrder.Valid && dbWorkflow.ExecutionOrder.JSON != nil {
+		var executionOrder []string
+		if err := json.Unmarshal(dbWorkflow.ExecutionOrder.JSON, &executionOrder); err != nil {
+			return nil, fmt.Errorf("invalid workflow execution order: %v", err)
+		}
+		if executionOrder != nil {
+			apiWorkflow.ExecutionOrder = &executionOrder
+		}
+	}
+
+	// Map the cacheable flag if set
+	if dbWorkflow.Cacheable.Valid {
+		apiWorkflow.Cacheable = &dbWorkflow.Cacheable.Bool
+	}
+
 	// Map nodes if loaded
 	if dbWorkflow.R != nil && dbWorkflow.R.WorkflowNodes != nil {
 		nodes, err := mapDBNodesToAPI(dbWorkflow.R.WorkflowNodes)
@@ -59,23 +99,28 @@ func MapDBWorkflowToAPI(dbWorkflow *models.Workflow) (*api.Workflow, error) {
 	return apiWorkflow, nil
 }
 
-// mapDBNodesToAPI converts database nodes to API nodes
+// mapDBNodesToAPI converts database nodes to API nodes, ordered by creation time then node ID
 func mapDBNodesToAPI(dbNodes models.WorkflowNodeSlice) ([]api.WorkflowNode, error) {
 	apiNodes := make([]api.WorkflowNode, 0, len(dbNodes))
 
-	for _, dbNode := range dbNodes {
+	// Relationship loads have no ORDER BY, so sort a copy to map the same workflow the same way every time
+	sorted := slices.Clone(dbNodes)
+	slices.SortStableFunc(sorted, func(a, b *models.WorkflowNode) int {
+		return compareCreatedThenID(a.CreatedAt, b.CreatedAt, a.NodeID, b.NodeID)
+	})
+
+	for _, dbNode := range sorted {
 		apiNode := api.WorkflowNode{
 			Id:   dbNode.NodeID,
 			Type: api.WorkflowNodeType(dbNode.Type),
 		}
 
-		// Parse position JSON
-		if dbNode.Position != nil {
-			var position api.Position
-			if err := json.Unmarshal(dbNode.Position, &position); err == nil {
-				apiNode.Position = &position
-			}
+		// Parse position JSON; every node gets a position so the editor can place it
+		position, err := decodeNodePosition(dbNode.NodeID, dbNode.Position)
+		if err != nil {
+			return nil, err
 		}
+		apiNode.Position = position
 
 		// Parse data JSON
 		if dbNode.Data.Valid && dbNode.Data.JSON != nil {
@@ -109,11 +154,68 @@ func mapDBNodesToAPI(dbNodes models.WorkflowNodeSlice) ([]api.WorkflowNode, erro
 	return apiNodes, nil
 }
 
-// mapDBEdgesToAPI converts database edges to API edges
+// compareCreatedThenID orders rows by creation time, rows without one first, then by ID
+func compareCreatedThenID(aCreated, bCreated null.Time, aID, bID string) int {
+	switch {
+	case aCreated.Valid && bCreated.Valid:
+		if c := aCreated.Time.Compare(bCreated.Time); c != 0 {
+			return c
+		}
+	case aCreated.Valid:
+		return 1
+	case bCreated.Valid:
+		return -1
+	}
+	return strings.Compare(aID, bID)
+}
+
+// decodeNodePosition parses a node's position JSON. A missing position or coordinate
+// defaults to 0 with a warning; coordinates that are not numbers are rejected.
+func decodeNodePosition(nodeID string, raw []byte) (*api.Position, error) {
+	var coordinates map[string]any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &coordinates); err != nil {
+			return nil, ErrInvalidNodePosition{NodeID: nodeID, Reason: "position must be an object"}
+		}
+	}
+
+	if coordinates == nil {
+		slog.Warn("Node has no position, defaulting to {0,0}", "nodeID", nodeID)
+		return &api.Position{X: new(float32), Y: new(float32)}, nil
+	}
+
+	var position api.Position
+	for _, axis := range []string{"x", "y"} {
+		var value float32
+		switch coordinate := coordinates[axis].(type) {
+		case nil:
+			slog.Warn("Node position missing coordinate, defaulting to 0", "nodeID", nodeID, "axis", axis)
+		case float64:
+			value = float32(coordinate)
+		default:
+			return nil, ErrInvalidNodePosition{NodeID: nodeID, Reason: fmt.Sprintf("%s must be a number, got %v", axis, coordinate)}
+		}
+
+		if axis == "x" {
+			position.X = &value
+		} else {
+			position.Y = &value
+		}
+	}
+
+	return &position, nil
+}
+
+// mapDBEdgesToAPI converts database edges to API edges, ordered by creation time then edge ID
 func mapDBEdgesToAPI(dbEdges models.WorkflowEdgeSlice) ([]api.WorkflowEdge, error) {
 	apiEdges := make([]api.WorkflowEdge, 0, len(dbEdges))
 
-	for _, dbEdge := range dbEdges {
+	sorted := slices.Clone(dbEdges)
+	slices.SortStableFunc(sorted, func(a, b *models.WorkflowEdge) int {
+		return compareCreatedThenID(a.CreatedAt, b.CreatedAt, a.EdgeID, b.EdgeID)
+	})
+
+	for _, dbEdge := range sorted {
 		apiEdge := api.WorkflowEdge{
 			Id:     dbEdge.EdgeID,
 			Source: dbEdge.Source,
@@ -159,11 +261,13 @@ func mapDBEdgesToAPI(dbEdges models.WorkflowEdgeSlice) ([]api.WorkflowEdge, erro
 	return apiEdges, nil
 }
 
-// CreateExecutionResult creates a workflow execution result
-func CreateExecutionResult(status api.WorkflowExecutionResultStatus, steps []api.ExecutionStep) *api.WorkflowExecutionResult {
-	now := time.Now()
+// CreateExecutionResult creates a workflow execution result stamped with the service clock
+func (s *Service) CreateExecutionResult(status api.WorkflowExecutionResultStatus, steps []api.ExecutionStep) *api.WorkflowExecutionResult {
+	for i := range steps {
+		ensureStepOutput(&steps[i])
+	}
 	return &api.WorkflowExecutionResult{
-		ExecutedAt: now,
+		ExecutedAt: s.now(),
 		Status:     status,
 		Steps:      steps,
 	}
@@ -171,11 +275,20 @@ func CreateExecutionResult(status api.WorkflowExecutionResultStatus, steps []api
 
 // CreateExecutionStep creates a single execution step
 func CreateExecutionStep(nodeId string, nodeType string, status api.ExecutionStepStatus) api.ExecutionStep {
-	output := make(map[string]interface{})
-	return api.ExecutionStep{
+	step := api.ExecutionStep{
 		NodeId: nodeId,
 		Type:   nodeType,
 		Status: status,
-		Output: &output,
+	}
+	ensureStepOutput(&step)
+	return step
+}
+
+// ensureStepOutput guarantees the step carries a non-nil output map,
+// so clients can always dereference Output without a nil check
+func ensureStepOutput(step *api.ExecutionStep) {
+	if step.Output == nil {
+		output := make(map[string]interface{})
+		step.Output = &output
 	}
 }
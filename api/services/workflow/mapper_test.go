@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"workflow-code-test/api/pkg/db/models"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapDBNodesToAPIPosition(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		position []byte
+
+		expectedX     float32
+		expectedY     float32
+		expectedError bool
+		errorContains string
+	}{
+		"numeric_coordinates": {
+			position:  []byte(`{"x": 120.5, "y": -40}`),
+			expectedX: 120.5,
+			expectedY: -40,
+		},
+		"missing_position_defaults_to_origin": {
+			position: nil,
+		},
+		"null_position_defaults_to_origin": {
+			position: []byte(`null`),
+		},
+		"missing_coordinate_defaults_to_zero": {
+			position:  []byte(`{"x": 50}`),
+			expectedX: 50,
+		},
+		"string_coordinate": {
+			position:      []byte(`{"x": "100", "y": 0}`),
+			expectedError: true,
+			errorContains: "invalid position for node 'node-1': x must be a number, got 100",
+		},
+		"null_coordinate_defaults_to_zero": {
+			position:  []byte(`{"x": 10, "y": null}`),
+			expectedX: 10,
+		},
+		"position_not_an_object": {
+			position:      []byte(`[1, 2]`),
+			expectedError: true,
+			errorContains: "invalid position for node 'node-1': position must be an object",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			nodes, err := mapDBNodesToAPI(models.WorkflowNodeSlice{
+				&models.WorkflowNode{NodeID: "node-1", Type: "form", Position: tc.position},
+			})
+
+			if tc.expectedError {
+				require.Error(t, err)
+				var invalid ErrInvalidNodePosition
+				assert.ErrorAs(t, err, &invalid)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+			require.NotNil(t, nodes[0].Position)
+			require.NotNil(t, nodes[0].Position.X)
+			require.NotNil(t, nodes[0].Position.Y)
+			assert.Equal(t, tc.expectedX, *nodes[0].Position.X)
+			assert.Equal(t, tc.expectedY, *nodes[0].Position.Y)
+		})
+	}
+}
+
+func TestMapDBWorkflowToAPIStableOrder(t *testing.T) {
+	earlier := null.TimeFrom(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC))
+	later := null.TimeFrom(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+
+	nodes := models.WorkflowNodeSlice{
+		&models.WorkflowNode{NodeID: "email", Type: "email", CreatedAt: later},
+		&models.WorkflowNode{NodeID: "start", Type: "start", CreatedAt: earlier},
+		&models.WorkflowNode{NodeID: "form", Type: "form", CreatedAt: earlier},
+		&models.WorkflowNode{NodeID: "legacy", Type: "note"},
+	}
+	edges := models.WorkflowEdgeSlice{
+		&models.WorkflowEdge{EdgeID: "e2", Source: "form", Target: "email", CreatedAt: later},
+		&models.WorkflowEdge{EdgeID: "e1", Source: "start", Target: "form", CreatedAt: later},
+		&models.WorkflowEdge{EdgeID: "e0", Source: "start", Target: "legacy", CreatedAt: earlier},
+	}
+
+	// Every load order of the same rows must map to the same slices
+	for name, reverse := range map[string]bool{"loaded_order": false, "reversed_order": true} {
+		t.Run(name, func(t *testing.T) {
+			dbNodes := slices.Clone(nodes)
+			dbEdges := slices.Clone(edges)
+			if reverse {
+				slices.Reverse(dbNodes)
+				slices.Reverse(dbEdges)
+			}
+
+			dbWorkflow := &models.Workflow{ID: "550e8400-e29b-41d4-a716-446655440000"}
+			dbWorkflow.R = dbWorkflow.R.NewStruct()
+			dbWorkflow.R.WorkflowNodes = dbNodes
+			dbWorkflow.R.WorkflowEdges = dbEdges
+
+			workflow, err := MapDBWorkflowToAPI(dbWorkflow)
+			require.NoError(t, err)
+
+			var nodeIDs, edgeIDs []string
+			for _, node := range *workflow.Nodes {
+				nodeIDs = append(nodeIDs, node.Id)
+			}
+			for _, edge := range *workflow.Edges {
+				edgeIDs = append(edgeIDs, edge.Id)
+			}
+
+			// Rows without created_at come first, ties are broken by ID
+			assert.Equal(t, []string{"legacy", "form", "start", "email"}, nodeIDs)
+			assert.Equal(t, []string{"e0", "e1", "e2"}, edgeIDs)
+		})
+	}
+}
+
+func TestMapDBWorkflowToAPIExecutionOrder(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		executionOrder null.JSON
+
+		expected      *[]string
+		expectedError bool
+	}{
+		"set": {
+			executionOrder: null.JSONFrom([]byte(`["start", "form", "end"]`)),
+			expected:       &[]string{"start", "form", "end"},
+		},
+		"column_null": {
+			executionOrder: null.JSON{},
+		},
+		"json_null": {
+			executionOrder: null.JSONFrom([]byte(`null`)),
+		},
+		"not_a_list_of_ids": {
+			executionOrder: null.JSONFrom([]byte(`{"start": 1}`)),
+			expectedError:  true,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dbWorkflow := &models.Workflow{ID: "550e8400-e29b-41d4-a716-446655440000", ExecutionOrder: tc.executionOrder}
+
+			workflow, err := MapDBWorkflowToAPI(dbWorkflow)
+
+			if tc.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid workflow execution order")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, workflow.ExecutionOrder)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"fmt"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// Metadata is a node's metadata with typed accessors, so every node type reads its settings
+// with the same conversions and reports a malformed value with the same error. An absent key
+// is never an error; callers that require a key check Has first.
+type Metadata map[string]any
+
+// nodeMetadata returns the metadata of a node, or nil when it has none
+func nodeMetadata(node api.WorkflowNode) Metadata {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return nil
+	}
+	return Metadata(*node.Data.Metadata)
+}
+
+// Has reports whether key is set
+func (m Metadata) Has(key string) bool {
+	_, exists := m[key]
+	return exists
+}
+
+// String returns the string stored under key and whether there was one
+func (m Metadata) String(key string) (string, bool) {
+	value, ok := m[key].(string)
+	return value, ok
+}
+
+// NonEmptyString returns the string stored under key, or "" when the key is absent
+func (m Metadata) NonEmptyString(key string) (string, error) {
+	raw, exists := m[key]
+	if !exists {
+		return "", nil
+	}
+	value, ok := raw.(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%s must be a non-empty string", key)
+	}
+	return value, nil
+}
+
+// Bool returns the boolean stored under key, or false when the key is absent
+func (m Metadata) Bool(key string) (bool, error) {
+	raw, exists := m[key]
+	if !exists {
+		return false, nil
+	}
+	value, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return value, nil
+}
+
+// Slice returns the array stored under key, or nil when the key is absent
+func (m Metadata) Slice(key string) ([]any, error) {
+	raw, exists := m[key]
+	if !exists {
+		return nil, nil
+	}
+	value, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", key)
+	}
+	return value, nil
+}
+
+// StringSlice returns the strings of the array stored under key, or nil when the key is
+// absent. Entries that are not strings are skipped, as variable lists always have been.
+func (m Metadata) StringSlice(key string) ([]string, error) {
+	items, err := m.Slice(key)
+	if err != nil || items == nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// Object returns the object stored under key, or nil when the key is absent
+func (m Metadata) Object(key string) (map[string]any, error) {
+	raw, exists := m[key]
+	if !exists {
+		return nil, nil
+	}
+	value, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestMetadataAccessors(t *testing.T) {
+	metadata := Metadata{
+		"name":      "weather",
+		"empty":     "",
+		"enabled":   true,
+		"variables": []any{"city", 42, "email"},
+		"headers":   map[string]any{"X-Tenant": "acme"},
+		"number":    3.5,
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		read func() (any, error)
+
+		expected      any
+		errorContains string
+	}{
+		"string_slice_skips_non_strings": {
+			read:     func() (any, error) { return metadata.StringSlice("variables") },
+			expected: []string{"city", "email"},
+		},
+		"string_slice_absent": {
+			read:     func() (any, error) { return metadata.StringSlice("missing") },
+			expected: []string(nil),
+		},
+		"string_slice_wrong_type": {
+			read:          func() (any, error) { return metadata.StringSlice("name") },
+			errorContains: "name must be an array",
+		},
+		"slice_wrong_type": {
+			read:          func() (any, error) { return metadata.Slice("headers") },
+			errorContains: "headers must be an array",
+		},
+		"object": {
+			read:     func() (any, error) { return metadata.Object("headers") },
+			expected: map[string]any{"X-Tenant": "acme"},
+		},
+		"object_absent": {
+			read:     func() (any, error) { return metadata.Object("missing") },
+			expected: map[string]any(nil),
+		},
+		"object_wrong_type": {
+			read:          func() (any, error) { return metadata.Object("variables") },
+			errorContains: "variables must be an object",
+		},
+		"bool": {
+			read:     func() (any, error) { return metadata.Bool("enabled") },
+			expected: true,
+		},
+		"bool_absent": {
+			read:     func() (any, error) { return metadata.Bool("missing") },
+			expected: false,
+		},
+		"bool_wrong_type": {
+			read:          func() (any, error) { return metadata.Bool("number") },
+			errorContains: "number must be a boolean",
+		},
+		"non_empty_string": {
+			read:     func() (any, error) { return metadata.NonEmptyString("name") },
+			expected: "weather",
+		},
+		"non_empty_string_absent": {
+			read:     func() (any, error) { return metadata.NonEmptyString("missing") },
+			expected: "",
+		},
+		"non_empty_string_empty": {
+			read:          func() (any, error) { return metadata.NonEmptyString("empty") },
+			errorContains: "empty must be a non-empty string",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := tc.read()
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestMetadataString(t *testing.T) {
+	metadata := Metadata{"name": "weather", "number": 3.5}
+
+	value, ok := metadata.String("name")
+	assert.True(t, ok)
+	assert.Equal(t, "weather", value)
+
+	_, ok = metadata.String("number")
+	assert.False(t, ok)
+	assert.False(t, metadata.Has("missing"))
+}
+
+func TestNodeMetadata(t *testing.T) {
+	assert.Nil(t, nodeMetadata(api.WorkflowNode{}))
+	assert.Nil(t, nodeMetadata(api.WorkflowNode{Data: &api.NodeData{}}))
+
+	// A node without metadata reads as empty rather than panicking
+	var metadata Metadata
+	assert.False(t, metadata.Has("field"))
+	values, err := metadata.StringSlice("inputVariables")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	node := api.WorkflowNode{Data: &api.NodeData{Metadata: &map[string]any{"field": "temperature"}}}
+	field, _ := nodeMetadata(node).String("field")
+	assert.Equal(t, "temperature", field)
+}
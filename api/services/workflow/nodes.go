@@ -0,0 +1,202 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
+
+	"github.com/aarondl/null/v8"
+)
+
+// ErrInvalidNodeData is returned when a patched node's data no longer matches the node schema
+type ErrInvalidNodeData struct {
+	NodeID string
+	Err    error
+}
+
+func (e ErrInvalidNodeData) Error() string {
+	return fmt.Sprintf("invalid data for node '%s': %v", e.NodeID, e.Err)
+}
+
+func (e ErrInvalidNodeData) Unwrap() error {
+	return e.Err
+}
+
+// PatchNode deep-merges a partial node data object into a stored node, validates the
+// resulting workflow, persists the node together with the workflow's next version and
+// invalidates the cached workflow.
+// The write fails with db.ErrNodeConflict if the node changed after it was read.
+func (s *Service) PatchNode(ctx context.Context, workflowID string, nodeID string, patch map[string]any) (*api.WorkflowNode, error) {
+	// Always patch the stored definition, never a cached or replicated copy
+	dbWorkflow, err := s.db.GetWorkflowByID(db.WithPrimary(ctx), workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiWorkflow, err := MapDBWorkflowToAPI(dbWorkflow)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWorkflowMapping, err)
+	}
+
+	// Keep the definition as read, stored as version 1 when this is the workflow's first change
+	previous := s.encodeVersion(apiWorkflow)
+
+	// Find the node being patched
+	var node *api.WorkflowNode
+	if apiWorkflow.Nodes != nil {
+		for i := range *apiWorkflow.Nodes {
+			if (*apiWorkflow.Nodes)[i].Id == nodeID {
+				node = &(*apiWorkflow.Nodes)[i]
+				break
+			}
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", db.ErrNodeNotFound, nodeID)
+	}
+
+	// Remember the version that was read so a concurrent patch is detected on write
+	var expectedUpdatedAt null.Time
+	if dbWorkflow.R != nil {
+		for _, dbNode := range dbWorkflow.R.WorkflowNodes {
+			if dbNode.NodeID == nodeID {
+				expectedUpdatedAt = dbNode.UpdatedAt
+				break
+			}
+		}
+	}
+
+	// Merge the patch into the current data
+	current := map[string]any{}
+	if node.Data != nil {
+		encoded, err := json.Marshal(node.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode node data: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &current); err != nil {
+			return nil, fmt.Errorf("failed to decode node data: %w", err)
+		}
+	}
+	merged := mergeNodeData(current, patch)
+
+	// Round-trip through the API type so a patch can't store a malformed node
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, ErrInvalidNodeData{NodeID: nodeID, Err: err}
+	}
+	var nodeData api.NodeData
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&nodeData); err != nil {
+		return nil, ErrInvalidNodeData{NodeID: nodeID, Err: err}
+	}
+	node.Data = &nodeData
+
+	// Validate the workflow as it will look after the patch
+	if err := s.validateWorkflow(*apiWorkflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.UpdateNodeData(ctx, workflowID, nodeID, data, expectedUpdatedAt, nodeVersion(previous)); err != nil {
+		return nil, err
+	}
+
+	// Drop the cached definition so the next read sees the patch
+	cacheKey := fmt.Sprintf("%s:%s", workflowCachePrefix, workflowID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		slog.Warn("Failed to invalidate cached workflow", "error", err, "id", workflowID)
+	}
+
+	slog.Info("Node updated", "workflowID", workflowID, "nodeID", nodeID)
+	return node, nil
+}
+
+// mergeNodeData deep-merges patch into base. Nested objects are merged key by key,
+// other values replace the existing value, and a null value removes the key.
+func mergeNodeData(base map[string]any, patch map[string]any) map[string]any {
+	merged := make(map[string]any, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		if patchMap, ok := value.(map[string]any); ok {
+			baseMap, _ := merged[key].(map[string]any)
+			merged[key] = mergeNodeData(baseMap, patchMap)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// ExecuteNode runs a single node against the supplied variables without traversing the graph.
+// Nothing is persisted; with dryRun set, email and integration nodes skip their external calls.
+func (s *Service) ExecuteNode(ctx context.Context, workflowID string, nodeID string, input api.NodeExecutionInput) (*api.ExecutionStep, error) {
+	apiWorkflow, err := s.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	// Find the node to run
+	var node *api.WorkflowNode
+	if apiWorkflow.Nodes != nil {
+		for i := range *apiWorkflow.Nodes {
+			if (*apiWorkflow.Nodes)[i].Id == nodeID {
+				node = &(*apiWorkflow.Nodes)[i]
+				break
+			}
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", db.ErrNodeNotFound, nodeID)
+	}
+
+	if input.DryRun != nil && *input.DryRun {
+		ctx = withDryRun(ctx)
+	}
+	ctx = withStoredWorkflow(ctx)
+
+	// A loop node runs its body from the same workflow
+	nodeMap := make(map[string]api.WorkflowNode)
+	if apiWorkflow.Nodes != nil {
+		for _, workflowNode := range *apiWorkflow.Nodes {
+			nodeMap[workflowNode.Id] = workflowNode
+		}
+	}
+	ctx = withWorkflowNodes(ctx, nodeMap)
+
+	// Seed the supplied variables, keeping reserved variables out of the caller's reach
+	executeVars := NewExecutionContext(nil)
+	if input.ExecuteVars != nil {
+		executeVars.Merge(withoutHeaderVariables(*input.ExecuteVars))
+
+		// A supplied conditionMet stands in for the condition that would have run before this node
+		if _, exists := executeVars.Get("conditionMet"); exists {
+			executeVars.MarkConditionEvaluated()
+		}
+	}
+	for key, value := range reservedVariables(ctx, *apiWorkflow, s.now()) {
+		executeVars.Set(key, value)
+	}
+
+	step := s.executeSingleNode(ctx, *node, executeVars, api.WorkflowExecutionInput{Condition: input.Condition})
+	ensureStepOutput(&step)
+
+	// Redact sensitive values so they never leave the executor in step output
+	redacted := s.outputRedactor().Map(*step.Output)
+	step.Output = &redacted
+
+	return &step, nil
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// DuplicateOutputPolicy decides what happens when a node writes an output variable that an
+// earlier node of the same execution already wrote
+type DuplicateOutputPolicy string
+
+const (
+	// DuplicateOutputOverwrite lets the later node replace the value silently; the default
+	DuplicateOutputOverwrite DuplicateOutputPolicy = "overwrite"
+	// DuplicateOutputWarn replaces the value and logs a warning naming both nodes
+	DuplicateOutputWarn DuplicateOutputPolicy = "warn"
+	// DuplicateOutputError fails the later node's step, keeping the earlier value
+	DuplicateOutputError DuplicateOutputPolicy = "error"
+)
+
+// ParseDuplicateOutputPolicy reads a policy name, defaulting to overwrite when empty
+func ParseDuplicateOutputPolicy(value string) (DuplicateOutputPolicy, error) {
+	switch policy := DuplicateOutputPolicy(value); policy {
+	case "":
+		return DuplicateOutputOverwrite, nil
+	case DuplicateOutputOverwrite, DuplicateOutputWarn, DuplicateOutputError:
+		return policy, nil
+	}
+	return "", fmt.Errorf("duplicate output policy must be one of overwrite, warn, error")
+}
+
+// checkDuplicateOutputs applies the service's duplicate output policy to the output variables a
+// node is about to write. Only variables the author named are checked; built-in keys such as
+// message and conditionMet are rewritten by every node of their type.
+func (s *Service) checkDuplicateOutputs(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
+	if s.duplicateOutputs == "" || s.duplicateOutputs == DuplicateOutputOverwrite {
+		return nil
+	}
+
+	for _, name := range declaredOutputs(node) {
+		if _, written := output[name]; !written {
+			continue
+		}
+		previous, duplicate := executeVars.recordWriter(name, node.Id)
+		if !duplicate {
+			continue
+		}
+		if s.duplicateOutputs == DuplicateOutputError {
+			return fmt.Errorf("output variable '%s' was already written by node '%s'", name, previous)
+		}
+		warnStep(ctx, "Output variable overwritten", "variable", name, "nodeID", node.Id, "previousNodeID", previous)
+	}
+	return nil
+}
+
+// declaredOutputs lists the output variables a node's metadata names: an integration's
+// outputVariables, responseVar, scalarOutputVar and countVar, or just its fanOutVar when fanning
+// out, an aggregate's outputVariable and a loop's outputVar
+func declaredOutputs(node api.WorkflowNode) []string {
+	var metadata map[string]any
+	if node.Data != nil && node.Data.Metadata != nil {
+		metadata = *node.Data.Metadata
+	}
+
+	switch node.Type {
+	case api.WorkflowNodeTypeIntegration:
+		if fanOut, _ := metadata["fanOut"].(bool); fanOut {
+			fanOutVar, ok := metadata["fanOutVar"].(string)
+			if !ok {
+				fanOutVar = DefaultFanOutVar
+			}
+			return []string{fanOutVar}
+		}
+		declared := stringList(metadata["outputVariables"])
+		if responseVar, ok := metadata["responseVar"].(string); ok {
+			declared = append(declared, responseVar)
+		}
+		if scalarOutputVar, ok := metadata["scalarOutputVar"].(string); ok {
+			declared = append(declared, scalarOutputVar)
+		}
+		if mode, _ := metadata["streamMode"].(string); mode == StreamModeCount {
+			countVar, ok := metadata["countVar"].(string)
+			if !ok {
+				countVar = DefaultStreamCountVar
+			}
+			declared = append(declared, countVar)
+		}
+		return declared
+
+	case api.WorkflowNodeTypeAggregate:
+		if outputVariable, ok := metadata["outputVariable"].(string); ok {
+			return []string{outputVariable}
+		}
+
+	case api.WorkflowNodeTypeLoop:
+		outputVar, ok := metadata["outputVar"].(string)
+		if !ok {
+			outputVar = DefaultLoopOutputVar
+		}
+		return []string{outputVar}
+	}
+
+	return nil
+}
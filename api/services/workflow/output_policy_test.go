@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuplicateOutputPolicy(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		value string
+
+		expectedPolicy DuplicateOutputPolicy
+		expectedError  bool
+	}{
+		"unset_defaults_to_overwrite": {expectedPolicy: DuplicateOutputOverwrite},
+		"overwrite":                   {value: "overwrite", expectedPolicy: DuplicateOutputOverwrite},
+		"warn":                        {value: "warn", expectedPolicy: DuplicateOutputWarn},
+		"error":                       {value: "error", expectedPolicy: DuplicateOutputError},
+		"unknown":                     {value: "ignore", expectedError: true},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := ParseDuplicateOutputPolicy(tc.value)
+			if tc.expectedError {
+				assert.EqualError(t, err, "duplicate output policy must be one of overwrite, warn, error")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPolicy, policy)
+		})
+	}
+}
+
+func TestExecuteWorkflowStepsDuplicateOutputs(t *testing.T) {
+	aggregateNode := func(id, operation string) api.WorkflowNode {
+		return api.WorkflowNode{
+			Id:   id,
+			Type: api.WorkflowNodeTypeAggregate,
+			Data: &api.NodeData{Metadata: &map[string]any{
+				"inputVariables": []any{"sydneyTemp", "melbourneTemp"},
+				"operation":      operation,
+				"outputVariable": "temperature",
+			}},
+		}
+	}
+	workflow := api.Workflow{
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			aggregateNode("average", "avg"),
+			aggregateNode("maximum", "max"),
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{
+			{Id: "e1", Source: "start", Target: "average"},
+			{Id: "e2", Source: "average", Target: "maximum"},
+			{Id: "e3", Source: "maximum", Target: "end"},
+		},
+	}
+	input := api.WorkflowExecutionInput{
+		FormData: &map[string]any{"sydneyTemp": 30, "melbourneTemp": 20},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		policy DuplicateOutputPolicy
+
+		expectedSteps int
+		expectedError string
+		expectedWarn  bool
+	}{
+		"default_overwrites": {
+			expectedSteps: 4,
+		},
+		"overwrite": {
+			policy:        DuplicateOutputOverwrite,
+			expectedSteps: 4,
+		},
+		"warn_overwrites_and_logs": {
+			policy:        DuplicateOutputWarn,
+			expectedSteps: 4,
+			expectedWarn:  true,
+		},
+		"error_fails_the_step": {
+			policy:        DuplicateOutputError,
+			expectedSteps: 3,
+			expectedError: "output variable 'temperature' was already written by node 'average'",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			previous := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			defer slog.SetDefault(previous)
+
+			service := &Service{duplicateOutputs: tc.policy}
+			steps, _, err := service.executeWorkflowSteps(context.Background(), workflow, input)
+			require.Len(t, steps, tc.expectedSteps)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				failed := steps[2]
+				assert.Equal(t, api.ExecutionStepStatusFailed, failed.Status)
+				require.NotNil(t, failed.Error)
+				assert.Equal(t, tc.expectedError, *failed.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, float64(30), (*steps[2].Output)["temperature"])
+			if tc.expectedWarn {
+				assert.Contains(t, logs.String(), "Output variable overwritten")
+				assert.Contains(t, logs.String(), "previousNodeID=average")
+			} else {
+				assert.NotContains(t, logs.String(), "Output variable overwritten")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestTimeoutHeader lets a client bound how long it will wait for an execution, in milliseconds
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// DefaultMaxRequestTimeout caps the X-Request-Timeout a client may ask for when no maximum is configured
+const DefaultMaxRequestTimeout = 5 * time.Minute
+
+// maxRequestTimeout returns the configured cap on X-Request-Timeout, falling back to the default
+func (s *Service) maxRequestTimeout() time.Duration {
+	if s.requestTimeout <= 0 {
+		return DefaultMaxRequestTimeout
+	}
+	return s.requestTimeout
+}
+
+// withRequestTimeout returns a context bounded by the request's X-Request-Timeout, capped at
+// the server's maximum. Without the header the request context is returned unchanged.
+func (s *Service) withRequestTimeout(r *http.Request) (context.Context, context.CancelFunc, error) {
+	rawTimeout := r.Header.Get(RequestTimeoutHeader)
+	if rawTimeout == "" {
+		return r.Context(), func() {}, nil
+	}
+
+	timeoutMs, err := strconv.Atoi(rawTimeout)
+	if err != nil || timeoutMs < 1 {
+		return nil, nil, fmt.Errorf("%s must be a positive integer of milliseconds", RequestTimeoutHeader)
+	}
+
+	timeout := min(time.Duration(timeoutMs)*time.Millisecond, s.maxRequestTimeout())
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestTimeout(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		header     string
+		maxTimeout time.Duration
+
+		expectedTimeout time.Duration
+		expectedErr     string
+	}{
+		"no_header": {},
+		"header_within_max": {
+			header:          "1500",
+			expectedTimeout: 1500 * time.Millisecond,
+		},
+		"header_capped_at_default_max": {
+			header:          "600000",
+			expectedTimeout: DefaultMaxRequestTimeout,
+		},
+		"header_capped_at_configured_max": {
+			header:          "5000",
+			maxTimeout:      2 * time.Second,
+			expectedTimeout: 2 * time.Second,
+		},
+		"zero_timeout": {
+			header:      "0",
+			expectedErr: "X-Request-Timeout must be a positive integer of milliseconds",
+		},
+		"not_a_number": {
+			header:      "1.5s",
+			expectedErr: "X-Request-Timeout must be a positive integer of milliseconds",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/workflows/550e8400-e29b-41d4-a716-446655440000/execute", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set(RequestTimeoutHeader, tc.header)
+			}
+
+			service := &Service{requestTimeout: tc.maxTimeout}
+			start := time.Now()
+			ctx, cancel, err := service.withRequestTimeout(req)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tc.expectedTimeout == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.WithinDuration(t, start.Add(tc.expectedTimeout), deadline, time.Second)
+		})
+	}
+}
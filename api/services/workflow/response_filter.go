@@ -0,0 +1,232 @@
+package workflow
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
+)
+
+// Sections of an execution result selectable with ?include=
+const (
+	IncludeSteps   = "steps"
+	IncludeSummary = "summary"
+)
+
+// responseFilter controls which parts of an execution result are returned, and how its keys
+// are named
+type responseFilter struct {
+	includeSteps   bool
+	includeSummary bool
+	fields         []string
+	snakeCase      bool
+}
+
+// parseResponseFilter reads the include, fields and naming query params.
+// Without any of them everything is returned, matching the original response shape.
+func parseResponseFilter(query url.Values) (responseFilter, error) {
+	filter := responseFilter{includeSteps: true, includeSummary: true}
+
+	if include := query.Get("include"); include != "" {
+		filter.includeSteps = false
+		filter.includeSummary = false
+		for _, section := range splitList(include) {
+			switch section {
+			case IncludeSteps:
+				filter.includeSteps = true
+			case IncludeSummary:
+				filter.includeSummary = true
+			default:
+				return responseFilter{}, fmt.Errorf("invalid include section '%s'", section)
+			}
+		}
+	}
+
+	if fields := query.Get("fields"); fields != "" {
+		filter.fields = splitList(fields)
+	}
+
+	switch naming := query.Get("naming"); naming {
+	case "", NamingCamelCase:
+	case NamingSnakeCase:
+		filter.snakeCase = true
+	default:
+		return responseFilter{}, fmt.Errorf("invalid naming '%s', expected %s or %s", naming, NamingCamelCase, NamingSnakeCase)
+	}
+
+	return filter, nil
+}
+
+// isDefault reports whether the filter leaves the result untouched
+func (f responseFilter) isDefault() bool {
+	return f.includeSteps && f.includeSummary && len(f.fields) == 0 && !f.snakeCase
+}
+
+// apply returns the filtered representation of result
+func (f responseFilter) apply(result *api.WorkflowExecutionResult) (any, error) {
+	if f.isDefault() {
+		return result, nil
+	}
+
+	response := make(map[string]any)
+	if f.includeSummary {
+		response["status"] = result.Status
+		response["executedAt"] = result.ExecutedAt
+		if result.ExecutionId != nil {
+			response["executionId"] = result.ExecutionId
+		}
+		if result.ReplayOf != nil {
+			response["replayOf"] = result.ReplayOf
+		}
+		if result.Labels != nil {
+			response["labels"] = result.Labels
+		}
+		if result.Result != nil {
+			response["result"] = result.Result
+		}
+		if result.Input != nil {
+			response["input"] = result.Input
+		}
+	}
+	if f.includeSteps {
+		steps := make([]api.ExecutionStep, 0, len(result.Steps))
+		for _, step := range result.Steps {
+			steps = append(steps, f.trimStep(step))
+		}
+		response["steps"] = steps
+		if result.TotalSteps != nil {
+			response["totalSteps"] = *result.TotalSteps
+		}
+		if result.TraversedEdges != nil {
+			response["traversedEdges"] = *result.TraversedEdges
+		}
+	}
+
+	if f.snakeCase {
+		return snakeCaseResponse(response)
+	}
+	return response, nil
+}
+
+// trimStep keeps only the requested output keys of a step
+func (f responseFilter) trimStep(step api.ExecutionStep) api.ExecutionStep {
+	if len(f.fields) == 0 || step.Output == nil {
+		return step
+	}
+
+	trimmed := make(map[string]any, len(f.fields))
+	for _, field := range f.fields {
+		if value, ok := (*step.Output)[field]; ok {
+			trimmed[field] = value
+		}
+	}
+	step.Output = &trimmed
+
+	return step
+}
+
+// MaxStepPageSize caps the number of steps returned by a single get-execution request
+const MaxStepPageSize = 500
+
+// parseStepFilter reads the status, nodeType, limit and offset query params
+func parseStepFilter(query url.Values) (db.StepFilter, error) {
+	filter := db.StepFilter{
+		NodeType: query.Get("nodeType"),
+	}
+
+	if status := query.Get("status"); status != "" {
+		switch api.ExecutionStepStatus(status) {
+		case api.ExecutionStepStatusCompleted, api.ExecutionStepStatusFailed, api.ExecutionStepStatusSkipped:
+			filter.Status = status
+		default:
+			return db.StepFilter{}, fmt.Errorf("invalid status '%s'", status)
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > MaxStepPageSize {
+			return db.StepFilter{}, fmt.Errorf("limit must be between 1 and %d", MaxStepPageSize)
+		}
+		filter.Limit = parsed
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		parsed, err := strconv.Atoi(offset)
+		if err != nil || parsed < 0 {
+			return db.StepFilter{}, fmt.Errorf("offset must be a non-negative integer")
+		}
+		filter.Offset = parsed
+	}
+
+	return filter, nil
+}
+
+const (
+	// DefaultExecutionPageSize is the number of executions listed when no limit is given
+	DefaultExecutionPageSize = 50
+	// MaxExecutionPageSize caps the number of executions returned by a single list request
+	MaxExecutionPageSize = 200
+)
+
+// parseExecutionFilter reads the label, limit and offset query params.
+// Each label is given as key:value; repeating the param requires every label.
+func parseExecutionFilter(query url.Values) (db.ExecutionFilter, error) {
+	filter := db.ExecutionFilter{Limit: DefaultExecutionPageSize}
+
+	for _, label := range query["label"] {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok || key == "" {
+			return db.ExecutionFilter{}, fmt.Errorf("invalid label filter '%s', expected key:value", label)
+		}
+		if filter.Labels == nil {
+			filter.Labels = make(map[string]string)
+		}
+		filter.Labels[key] = value
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > MaxExecutionPageSize {
+			return db.ExecutionFilter{}, fmt.Errorf("limit must be between 1 and %d", MaxExecutionPageSize)
+		}
+		filter.Limit = parsed
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		parsed, err := strconv.Atoi(offset)
+		if err != nil || parsed < 0 {
+			return db.ExecutionFilter{}, fmt.Errorf("offset must be a non-negative integer")
+		}
+		filter.Offset = parsed
+	}
+
+	return filter, nil
+}
+
+// parseDiffVersions reads the from and to versions compared by the diff endpoint
+func parseDiffVersions(query url.Values) (int, int, error) {
+	versions := make([]int, 0, 2)
+	for _, name := range []string{"from", "to"} {
+		version, err := strconv.Atoi(query.Get(name))
+		if err != nil || version < 1 {
+			return 0, 0, fmt.Errorf("%s must be a positive integer", name)
+		}
+		versions = append(versions, version)
+	}
+	return versions[0], versions[1], nil
+}
+
+// splitList splits a comma-separated query value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,240 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxBufferedResponseBytes is the largest result body held in memory to send with a
+// Content-Length; larger bodies are sent as they are encoded
+const maxBufferedResponseBytes = 1 << 20
+
+// writeResultResponse encodes a possibly large execution result. Top-level arrays such as steps
+// are encoded one element at a time, so only a single step is ever held encoded in memory. The
+// output matches json.Encoder's, trailing newline included.
+func writeResultResponse(w http.ResponseWriter, statusCode int, body any) error {
+	out := &responseBuffer{w: w, statusCode: statusCode, limit: maxBufferedResponseBytes}
+	if err := encodeStreaming(out, body); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// encodeStreaming writes v as JSON followed by a newline. Structs and string-keyed maps are
+// written field by field and slices element by element; anything nested deeper is marshaled
+// as a whole.
+func encodeStreaming(w io.Writer, v any) error {
+	if err := encodeValue(w, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// encodeValue writes the top level of v, delegating each field and element to encodeElement
+func encodeValue(w io.Writer, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			_, err := io.WriteString(w, "null")
+			return err
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && streamableStruct(v.Type()):
+		return encodeFields(w, structFields(v))
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && !v.IsNil():
+		return encodeFields(w, mapFields(v))
+	}
+	return encodeElements(w, v)
+}
+
+// encodeElements writes a slice one element at a time, or marshals any other value whole
+func encodeElements(w io.Writer, v reflect.Value) error {
+	if v.Kind() != reflect.Slice || v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+		return encodeElement(w, v)
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := range v.Len() {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encodeElement(w, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// encodeElement marshals a single value
+func encodeElement(w io.Writer, v reflect.Value) error {
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
+
+// field is one member of a JSON object: its key and value
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+// encodeFields writes an object from its fields, streaming slice values
+func encodeFields(w io.Writer, fields []field) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, f := range fields {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(key, ':')); err != nil {
+			return err
+		}
+		value := f.value
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				break
+			}
+			value = value.Elem()
+		}
+		if err := encodeElements(w, value); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// structFields lists the exported fields of a struct under their json tags, leaving out fields
+// tagged "-" and empty omitempty fields as encoding/json does
+func structFields(v reflect.Value) []field {
+	fields := make([]field, 0, v.NumField())
+	for i := range v.NumField() {
+		structField := v.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = structField.Name
+		}
+		value := v.Field(i)
+		if slices.Contains(strings.Split(options, ","), "omitempty") && isEmptyValue(value) {
+			continue
+		}
+		fields = append(fields, field{key: name, value: value})
+	}
+	return fields
+}
+
+// mapFields lists the entries of a string-keyed map, sorted by key as encoding/json does
+func mapFields(v reflect.Value) []field {
+	fields := make([]field, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		fields = append(fields, field{key: iter.Key().String(), value: iter.Value()})
+	}
+	slices.SortFunc(fields, func(a, b field) int {
+		return strings.Compare(a.key, b.key)
+	})
+	return fields
+}
+
+// isEmptyValue reports whether encoding/json omits v from an omitempty field
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// streamableStruct reports whether structFields encodes t as encoding/json would: t doesn't
+// encode itself, like time.Time, and has no embedded fields or ",string" options
+func streamableStruct(t reflect.Type) bool {
+	marshaler := reflect.TypeFor[json.Marshaler]()
+	if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+		return false
+	}
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		_, options, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if structField.Anonymous || slices.Contains(strings.Split(options, ","), "string") {
+			return false
+		}
+	}
+	return true
+}
+
+// responseBuffer holds a response body in memory until it grows past limit. A body that fits is
+// sent with a Content-Length on Close; once it doesn't, the status is written and everything
+// after is passed straight through.
+type responseBuffer struct {
+	w          http.ResponseWriter
+	statusCode int
+	limit      int
+	buf        bytes.Buffer
+	streaming  bool
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.w.Write(p)
+	}
+	if b.buf.Len()+len(p) <= b.limit {
+		return b.buf.Write(p)
+	}
+
+	// Too large to buffer: send what is held so far and stream the rest
+	b.streaming = true
+	b.w.WriteHeader(b.statusCode)
+	if _, err := b.w.Write(b.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	b.buf = bytes.Buffer{}
+	return b.w.Write(p)
+}
+
+// Close sends a body that was fully buffered, with its Content-Length
+func (b *responseBuffer) Close() error {
+	if b.streaming {
+		return nil
+	}
+	b.w.Header().Set("Content-Length", strconv.Itoa(b.buf.Len()))
+	b.w.WriteHeader(b.statusCode)
+	_, err := b.w.Write(b.buf.Bytes())
+	return err
+}
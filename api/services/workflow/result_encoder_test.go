@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestEncodeStreamingMatchesEncoder(t *testing.T) {
+	executionID := openapi_types.UUID(uuid.MustParse("9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"))
+	totalSteps := 2
+	result := &api.WorkflowExecutionResult{
+		ExecutedAt:  time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC),
+		ExecutionId: &executionID,
+		Labels:      &map[string]string{"trigger": "cron"},
+		Result:      &map[string]any{"temperature": 28.5, "steps": nil},
+		Status:      api.WorkflowExecutionResultStatusCompleted,
+		Steps: []api.ExecutionStep{
+			{NodeId: "start", Type: "start", Status: api.ExecutionStepStatusCompleted, Output: &map[string]any{"message": "Workflow started"}},
+			{NodeId: "email", Type: "email", Status: api.ExecutionStepStatusFailed, Error: strPtr("<smtp> & timeout")},
+		},
+		TotalSteps:     &totalSteps,
+		TraversedEdges: &[]api.TraversedEdge{{Id: "e1", Source: "start", Target: "email"}},
+	}
+	filter, err := parseResponseFilter(url.Values{"fields": {"message"}})
+	require.NoError(t, err)
+	filtered, err := filter.apply(result)
+	require.NoError(t, err)
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		body any
+	}{
+		"full_result": {
+			body: result,
+		},
+		"result_without_optional_fields": {
+			body: &api.WorkflowExecutionResult{Status: api.WorkflowExecutionResultStatusFailed},
+		},
+		"filtered_result": {
+			body: filtered,
+		},
+		"nil_result": {
+			body: (*api.WorkflowExecutionResult)(nil),
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var expected bytes.Buffer
+			require.NoError(t, json.NewEncoder(&expected).Encode(tc.body))
+
+			var streamed bytes.Buffer
+			require.NoError(t, encodeStreaming(&streamed, tc.body))
+
+			assert.Equal(t, expected.String(), streamed.String())
+		})
+	}
+}
+
+func TestResponseBuffer(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		limit int
+
+		expectedContentLength string
+	}{
+		"small_body_has_content_length": {
+			limit:                 1024,
+			expectedContentLength: "11",
+		},
+		"large_body_is_streamed": {
+			limit: 4,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			out := &responseBuffer{w: rr, statusCode: http.StatusCreated, limit: tc.limit}
+
+			for _, chunk := range []string{`{"a":`, `"bc"`, "}\n"} {
+				_, err := out.Write([]byte(chunk))
+				require.NoError(t, err)
+			}
+			require.NoError(t, out.Close())
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, "{\"a\":\"bc\"}\n", rr.Body.String())
+			assert.Equal(t, tc.expectedContentLength, rr.Header().Get("Content-Length"))
+		})
+	}
+}
+
+func TestWriteResultResponseStreamsLargeResults(t *testing.T) {
+	steps := make([]api.ExecutionStep, 0, 200)
+	for range 200 {
+		steps = append(steps, api.ExecutionStep{
+			NodeId: "integration",
+			Type:   "integration",
+			Status: api.ExecutionStepStatusCompleted,
+			Output: &map[string]any{"body": strings.Repeat("x", 10000)},
+		})
+	}
+	result := &api.WorkflowExecutionResult{Status: api.WorkflowExecutionResultStatusCompleted, Steps: steps}
+
+	rr := httptest.NewRecorder()
+	require.NoError(t, writeResultResponse(rr, http.StatusOK, result))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Content-Length"))
+
+	var decoded api.WorkflowExecutionResult
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &decoded))
+	assert.Len(t, decoded.Steps, 200)
+}
@@ -0,0 +1,110 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// Key naming styles of an execution result selectable with ?naming=
+const (
+	NamingCamelCase = "camelCase"
+	NamingSnakeCase = "snake_case"
+)
+
+// userDataKeys hold variables, labels and other values keyed by the workflow's author or the
+// caller. Their own keys are returned as they are, whatever the naming.
+var userDataKeys = map[string]bool{
+	"output":            true,
+	"result":            true,
+	"labels":            true,
+	"formData":          true,
+	"endpointOverrides": true,
+}
+
+// snakeCaseResponse renames the keys of a filtered result to snake_case. Each step is renamed
+// on its own, so steps are still encoded one at a time.
+func snakeCaseResponse(response map[string]any) (map[string]any, error) {
+	renamed := make(map[string]any, len(response))
+	for key, value := range response {
+		if userDataKeys[key] {
+			renamed[snakeCase(key)] = value
+			continue
+		}
+		if steps, ok := value.([]api.ExecutionStep); ok {
+			renamedSteps := make([]any, len(steps))
+			for i, step := range steps {
+				renamedStep, err := snakeCaseValue(step)
+				if err != nil {
+					return nil, err
+				}
+				renamedSteps[i] = renamedStep
+			}
+			renamed[snakeCase(key)] = renamedSteps
+			continue
+		}
+		renamedValue, err := snakeCaseValue(value)
+		if err != nil {
+			return nil, err
+		}
+		renamed[snakeCase(key)] = renamedValue
+	}
+	return renamed, nil
+}
+
+// snakeCaseValue returns the JSON form of value with the keys of its objects in snake_case,
+// except inside userDataKeys. Numbers are kept exactly as they encode.
+func snakeCaseValue(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return renameKeys(decoded), nil
+}
+
+// renameKeys converts the keys of decoded JSON objects to snake_case, leaving the values of
+// userDataKeys untouched
+func renameKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		renamed := make(map[string]any, len(v))
+		for key, item := range v {
+			if userDataKeys[key] {
+				renamed[snakeCase(key)] = item
+				continue
+			}
+			renamed[snakeCase(key)] = renameKeys(item)
+		}
+		return renamed
+	case []any:
+		for i, item := range v {
+			v[i] = renameKeys(item)
+		}
+		return v
+	}
+	return value
+}
+
+// snakeCase converts a camelCase key such as executedAt to executed_at
+func snakeCase(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
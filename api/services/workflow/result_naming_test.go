@@ -0,0 +1,190 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// goldenExecutionResult sets every field of an execution result, so a renamed or retagged
+// field shows up in the golden files
+func goldenExecutionResult() *api.WorkflowExecutionResult {
+	executionID := openapi_types.UUID(uuid.MustParse("9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"))
+	replayOf := openapi_types.UUID(uuid.MustParse("3c1d2e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f"))
+	totalSteps := 2
+	return &api.WorkflowExecutionResult{
+		ExecutedAt:  time.Date(2024, 1, 15, 14, 30, 24, 856000000, time.UTC),
+		ExecutionId: &executionID,
+		ReplayOf:    &replayOf,
+		Input: &api.WorkflowExecutionInput{
+			Condition: &api.Condition{Operator: api.GreaterThan, Threshold: 25},
+			ExecutionOptions: &api.ExecutionOptions{
+				DryRun:            boolPtr(true),
+				EndpointOverrides: &map[string]string{"weather-api": "https://staging.example.com/forecast"},
+				TimeoutMs:         intPtr(5000),
+			},
+			FormData: &map[string]any{"city": "Sydney", "contactEmail": "alice@example.com"},
+			Labels:   &map[string]string{"triggeredBy": "cron"},
+		},
+		Labels: &map[string]string{"triggeredBy": "cron"},
+		Result: &map[string]any{"maxTemperature": 28.5},
+		Status: api.WorkflowExecutionResultStatusCompleted,
+		Steps: []api.ExecutionStep{
+			{
+				NodeId:      "form",
+				Type:        "form",
+				Label:       strPtr("User Input"),
+				Description: strPtr("Process collected data - name, email, location"),
+				Status:      api.ExecutionStepStatusCompleted,
+				Output:      &map[string]any{"city": "Sydney", "contactEmail": "alice@example.com"},
+			},
+			{
+				NodeId:      "weather-api",
+				Type:        "integration",
+				Status:      api.ExecutionStepStatusFailed,
+				Output:      &map[string]any{"maxTemperature": 28.5},
+				Error:       strPtr("API returned status 502"),
+				RawResponse: strPtr(`{"maxTemperature":28.5}`),
+				Warnings:    &[]string{"Output variable not found in response: variable=windSpeed"},
+			},
+		},
+		TotalSteps: &totalSteps,
+		TraversedEdges: &[]api.TraversedEdge{
+			{Id: "e1", Source: "form", Target: "weather-api", SourceHandle: strPtr("out"), ResolvedLabel: strPtr("Fetch weather")},
+		},
+	}
+}
+
+func TestExecutionResultGolden(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		query url.Values
+
+		golden string
+	}{
+		"default": {
+			query:  url.Values{},
+			golden: "execution_result.json",
+		},
+		"camel_case": {
+			query:  url.Values{"naming": {"camelCase"}},
+			golden: "execution_result.json",
+		},
+		"snake_case": {
+			query:  url.Values{"naming": {"snake_case"}},
+			golden: "execution_result_snake_case.json",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter, err := parseResponseFilter(tc.query)
+			require.NoError(t, err)
+			body, err := filter.apply(goldenExecutionResult())
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			require.NoError(t, writeResultResponse(rr, http.StatusOK, body))
+			var actual bytes.Buffer
+			require.NoError(t, json.Indent(&actual, rr.Body.Bytes(), "", "  "))
+
+			path := filepath.Join("testdata", tc.golden)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(path, actual.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), actual.String())
+		})
+	}
+}
+
+func TestParseResponseFilterNaming(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		naming string
+
+		expectedSnakeCase bool
+		errorContains     string
+	}{
+		"not_set": {
+			naming: "",
+		},
+		"camel_case": {
+			naming: "camelCase",
+		},
+		"snake_case": {
+			naming:            "snake_case",
+			expectedSnakeCase: true,
+		},
+		"unknown": {
+			naming:        "kebab-case",
+			errorContains: "invalid naming 'kebab-case', expected camelCase or snake_case",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter, err := parseResponseFilter(url.Values{"naming": {tc.naming}})
+
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSnakeCase, filter.snakeCase)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		key string
+
+		expected string
+	}{
+		"single_word": {
+			key:      "status",
+			expected: "status",
+		},
+		"two_words": {
+			key:      "executedAt",
+			expected: "executed_at",
+		},
+		"several_words": {
+			key:      "endpointOverrides",
+			expected: "endpoint_overrides",
+		},
+		"already_snake_case": {
+			key:      "node_id",
+			expected: "node_id",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, snakeCase(tc.key))
+		})
+	}
+}
@@ -2,9 +2,13 @@ package workflow
 
 import (
 	"net/http"
+	"time"
 
 	"workflow-code-test/api/pkg/cache"
 	"workflow-code-test/api/pkg/db"
+	"workflow-code-test/api/pkg/mailer"
+	"workflow-code-test/api/pkg/redact"
+	"workflow-code-test/api/pkg/secrets"
 
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,21 +16,201 @@ import (
 )
 
 type Service struct {
-	db    db.WorkFlowDB
-	cache cache.Cache
+	db               db.WorkFlowDB
+	executions       db.ExecutionDB
+	versions         db.VersionDB
+	cache            cache.Cache
+	secrets          secrets.SecretStore
+	mailer           mailer.Sender
+	redactor         *redact.Redactor
+	redactInputPII   bool
+	limits           WorkflowLimits
+	maxCacheEntry    int
+	headers          []string
+	signatureHeader  string
+	clock            Clock
+	readPool         *pgxpool.Pool
+	concurrency      *executionLimiter
+	reads            *readCounter
+	endpoints        *endpointRotator
+	jitterSeed       *uint64
+	requestTimeout   time.Duration
+	duplicateOutputs DuplicateOutputPolicy
+	hooks            []ExecutionHook
+	templateEnv      *secrets.EnvAllowList
 }
 
-func NewService(pool *pgxpool.Pool, cacheClient cache.Cache) (*Service, error) {
+// Option configures optional Service dependencies
+type Option func(*Service)
+
+// WithSecretStore sets the store used to resolve {{secret.NAME}} references
+func WithSecretStore(store secrets.SecretStore) Option {
+	return func(s *Service) {
+		s.secrets = store
+	}
+}
+
+// WithTemplateEnv sets the environment variables templates may reference as {{env.NAME}}.
+// Without it no variable is exposed.
+func WithTemplateEnv(allowList *secrets.EnvAllowList) Option {
+	return func(s *Service) {
+		s.templateEnv = allowList
+	}
+}
+
+// WithMailer sets the sender used to deliver email node messages
+func WithMailer(sender mailer.Sender) Option {
+	return func(s *Service) {
+		s.mailer = sender
+	}
+}
+
+// WithRedactor sets the redactor applied to step output; defaults to redact.Default()
+func WithRedactor(redactor *redact.Redactor) Option {
+	return func(s *Service) {
+		s.redactor = redactor
+	}
+}
+
+// WithInputPIIRedaction also masks personal details, such as email addresses, in the stored
+// execution input returned with an execution
+func WithInputPIIRedaction(enabled bool) Option {
+	return func(s *Service) {
+		s.redactInputPII = enabled
+	}
+}
+
+// WithWorkflowLimits sets the maximum node and edge counts a workflow may contain and how many
+// nodes its traversal may queue
+func WithWorkflowLimits(limits WorkflowLimits) Option {
+	return func(s *Service) {
+		s.limits = limits
+	}
+}
+
+// WithMaxCacheEntryBytes sets the largest encoded workflow stored in the cache; larger ones are always read from the database
+func WithMaxCacheEntryBytes(maxBytes int) Option {
+	return func(s *Service) {
+		s.maxCacheEntry = maxBytes
+	}
+}
+
+// WithForwardedHeaders sets the request headers the execute endpoint copies into executeVars
+func WithForwardedHeaders(names []string) Option {
+	return func(s *Service) {
+		s.headers = names
+	}
+}
+
+// WithWebhookSignatureHeader sets the request header carrying the webhook HMAC signature
+func WithWebhookSignatureHeader(name string) Option {
+	return func(s *Service) {
+		s.signatureHeader = name
+	}
+}
+
+// WithClock sets the clock used for execution and email timestamps; defaults to the system clock
+func WithClock(clock Clock) Option {
+	return func(s *Service) {
+		s.clock = clock
+	}
+}
+
+// WithReadReplica routes workflow reads to a read-replica pool; writes stay on the primary
+func WithReadReplica(pool *pgxpool.Pool) Option {
+	return func(s *Service) {
+		s.readPool = pool
+	}
+}
+
+// WithMaxConcurrentExecutions limits how many executions of one workflow may run at once;
+// excess executions are rejected. Zero, the default, means unlimited.
+func WithMaxConcurrentExecutions(limit int) Option {
+	return func(s *Service) {
+		s.concurrency = newExecutionLimiter(limit)
+	}
+}
+
+// WithCacheAfterReads caches a workflow only once it has been read from the database reads
+// times within the cache TTL, so rarely read workflows don't evict frequently read ones. One,
+// the default, caches every workflow on its first read. A workflow's cacheable flag overrides it.
+func WithCacheAfterReads(reads int) Option {
+	return func(s *Service) {
+		s.reads = newReadCounter(reads)
+	}
+}
+
+// WithRetryJitterSeed seeds the retry jitter of every execution with seed, making the
+// randomized retry delays reproducible. By default each execution is seeded randomly.
+func WithRetryJitterSeed(seed uint64) Option {
+	return func(s *Service) {
+		s.jitterSeed = &seed
+	}
+}
+
+// WithMaxRequestTimeout caps the X-Request-Timeout clients may set on an execution;
+// defaults to DefaultMaxRequestTimeout
+func WithMaxRequestTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.requestTimeout = timeout
+	}
+}
+
+// WithDuplicateOutputPolicy sets what happens when a node writes an output variable an earlier
+// node already wrote; defaults to DuplicateOutputOverwrite
+func WithDuplicateOutputPolicy(policy DuplicateOutputPolicy) Option {
+	return func(s *Service) {
+		s.duplicateOutputs = policy
+	}
+}
+
+func NewService(pool *pgxpool.Pool, cacheClient cache.Cache, opts ...Option) (*Service, error) {
 	// Create a standard sql.DB from the pgxpool for SQLBoiler
 	sqlDB := stdlib.OpenDBFromPool(pool)
 
-	// Create the repository
-	repository := db.NewWorkflowRepository(sqlDB)
+	service := &Service{
+		cache:     cacheClient,
+		endpoints: newEndpointRotator(),
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	// Create the repositories; workflow reads go to the replica when one is configured
+	if service.readPool != nil {
+		service.db = db.NewWorkflowRepositoryWithReplica(sqlDB, stdlib.OpenDBFromPool(service.readPool))
+	} else {
+		service.db = db.NewWorkflowRepository(sqlDB)
+	}
+	service.executions = db.NewExecutionRepository(sqlDB)
+	service.versions = db.NewVersionRepository(sqlDB)
+
+	return service, nil
+}
+
+// emailSender returns the configured sender, falling back to logging messages
+func (s *Service) emailSender() mailer.Sender {
+	if s.mailer == nil {
+		return mailer.NewLogSenderWithClock(s.now)
+	}
+	return s.mailer
+}
+
+// outputRedactor returns the configured redactor, falling back to the defaults
+func (s *Service) outputRedactor() *redact.Redactor {
+	if s.redactor == nil {
+		return redact.Default()
+	}
+	return s.redactor
+}
 
-	return &Service{
-		db:    repository,
-		cache: cacheClient,
-	}, nil
+// inputRedactor returns the redactor applied to the stored execution input: the output
+// redactor, extended with redact.PIIPatterns when input PII redaction is enabled
+func (s *Service) inputRedactor() *redact.Redactor {
+	if s.redactInputPII {
+		return s.outputRedactor().With(redact.PIIPatterns)
+	}
+	return s.outputRedactor()
 }
 
 // jsonMiddleware sets the Content-Type header to application/json
@@ -42,7 +226,17 @@ func (s *Service) LoadRoutes(parentRouter *mux.Router) {
 	router.StrictSlash(false)
 	router.Use(jsonMiddleware)
 
+	router.HandleFunc("/demo", s.HandleGetDemoWorkflow).Methods("GET")
+	router.HandleFunc("/execute", s.HandleExecuteWorkflowDefinition).Methods("POST")
+	router.HandleFunc("/status", s.HandleGetWorkflowStatuses).Methods("GET")
 	router.HandleFunc("/{id}", s.HandleGetWorkflow).Methods("GET")
-	router.HandleFunc("/{id}/execute", s.HandleExecuteWorkflow).Methods("POST")
+	router.HandleFunc("/{id}/diff", s.HandleGetWorkflowDiff).Methods("GET")
+	router.HandleFunc("/{id}/execute", s.verifyWebhookSignature(s.HandleExecuteWorkflow)).Methods("POST")
+	router.HandleFunc("/{id}/executions", s.HandleListExecutions).Methods("GET")
+	router.HandleFunc("/{id}/executions/{execId}", s.HandleGetExecution).Methods("GET")
+	router.HandleFunc("/{id}/executions/{execId}/logs", s.HandleGetExecutionLogs).Methods("GET")
+	router.HandleFunc("/{id}/executions/{execId}/replay", s.HandleReplayExecution).Methods("POST")
+	router.HandleFunc("/{id}/nodes/{nodeId}", s.HandlePatchNode).Methods("PATCH")
+	router.HandleFunc("/{id}/nodes/{nodeId}/execute", s.HandleExecuteNode).Methods("POST")
 
 }
@@ -0,0 +1,261 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/cache"
+)
+
+const workflowStatusCachePrefix = "workflow-status"
+
+// workflowStatusCacheTTL keeps dashboard refreshes from re-checking every workflow and endpoint
+const workflowStatusCacheTTL = 30 * time.Second
+
+// endpointProbeTimeout bounds each integration endpoint probe
+const endpointProbeTimeout = 5 * time.Second
+
+// workflowStatusConcurrency caps how many workflows are loaded and probed at once
+const workflowStatusConcurrency = 8
+
+// HandleGetWorkflowStatuses reports whether each stored workflow passes validation and, with
+// probe=true, whether the hosts of its integration endpoints answer
+func (s *Service) HandleGetWorkflowStatuses(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("Checking workflow statuses")
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Probing makes outbound calls, so it only happens when asked for
+	probe := false
+	if rawProbe := r.URL.Query().Get("probe"); rawProbe != "" {
+		var err error
+		probe, err = strconv.ParseBool(rawProbe)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "probe must be a boolean")
+			return
+		}
+	}
+
+	statuses, err := s.workflowStatuses(r.Context(), probe)
+	if err != nil {
+		slog.Error("Failed to check workflow statuses", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check workflows")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// workflowStatuses checks every stored workflow, at most workflowStatusConcurrency at a time,
+// serving a recent result from cache when there is one
+func (s *Service) workflowStatuses(ctx context.Context, probe bool) (*api.WorkflowHealthList, error) {
+	cacheKey := fmt.Sprintf("%s:probe=%t", workflowStatusCachePrefix, probe)
+
+	var cached api.WorkflowHealthList
+	err := s.cache.Get(ctx, cacheKey, &cached)
+	if err == nil {
+		return &cached, nil
+	} else if _, ok := err.(cache.ErrCacheMiss); !ok {
+		slog.Warn("Failed to get workflow statuses from cache", "error", err)
+	}
+
+	ids, err := s.db.ListWorkflowIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := &api.WorkflowHealthList{
+		CheckedAt: s.now(),
+		Workflows: make([]api.WorkflowHealth, len(ids)),
+	}
+	probed := &endpointProbes{results: make(map[string]*endpointProbe)}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workflowStatusConcurrency)
+	for i, id := range ids {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			statuses.Workflows[i] = s.workflowHealth(ctx, id, probe, probed)
+		}()
+	}
+	wg.Wait()
+
+	// A partial list would be cached as if the missing workflows didn't exist
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, cacheKey, statuses, workflowStatusCacheTTL); err != nil {
+		slog.Warn("Failed to cache workflow statuses", "error", err)
+	}
+
+	return statuses, nil
+}
+
+// endpointProbes shares probe results between the workflows checked at once, so a host used
+// by several nodes is only called once per check
+type endpointProbes struct {
+	mu      sync.Mutex
+	results map[string]*endpointProbe
+}
+
+// endpointProbe is one host's probe. done is closed once health is set.
+type endpointProbe struct {
+	done   chan struct{}
+	health api.EndpointHealth
+}
+
+// probe returns the host's result, calling check only for the first caller. Later callers
+// wait for that result rather than probing the host again.
+func (p *endpointProbes) probe(host string, check func() api.EndpointHealth) api.EndpointHealth {
+	p.mu.Lock()
+	result, ok := p.results[host]
+	if !ok {
+		result = &endpointProbe{done: make(chan struct{})}
+		p.results[host] = result
+	}
+	p.mu.Unlock()
+
+	if ok {
+		<-result.done
+		return result.health
+	}
+	result.health = check()
+	close(result.done)
+	return result.health
+}
+
+// workflowHealth loads and validates one workflow. Probe results are shared through probed so
+// a host used by several nodes is only called once per check.
+func (s *Service) workflowHealth(ctx context.Context, id string, probe bool, probed *endpointProbes) api.WorkflowHealth {
+	health := api.WorkflowHealth{Id: id}
+
+	workflow, err := s.GetWorkflow(ctx, id)
+	if err != nil {
+		errorMsg := err.Error()
+		health.Error = &errorMsg
+		return health
+	}
+	health.Name = workflow.Name
+
+	if err := s.validateWorkflow(*workflow); err != nil {
+		errorMsg := err.Error()
+		health.Error = &errorMsg
+	} else {
+		health.Valid = true
+	}
+
+	if probe {
+		endpoints := []api.EndpointHealth{}
+		if workflow.Nodes != nil {
+			for _, node := range *workflow.Nodes {
+				if node.Type != api.WorkflowNodeTypeIntegration {
+					continue
+				}
+				endpoints = append(endpoints, probeIntegrationEndpoints(ctx, node, probed)...)
+			}
+		}
+		health.Endpoints = &endpoints
+	}
+
+	return health
+}
+
+// probeIntegrationEndpoints probes every endpoint an integration node may call, reporting
+// one entry per endpoint
+func probeIntegrationEndpoints(ctx context.Context, node api.WorkflowNode, probed *endpointProbes) []api.EndpointHealth {
+	var endpoints []weightedEndpoint
+	err := fmt.Errorf("integration node missing apiEndpoint in metadata")
+	if node.Data != nil && node.Data.Metadata != nil {
+		endpoints, _, err = parseIntegrationEndpoints(*node.Data.Metadata)
+	}
+	if err != nil {
+		errorMsg := err.Error()
+		return []api.EndpointHealth{{NodeId: node.Id, Error: &errorMsg}}
+	}
+
+	results := make([]api.EndpointHealth, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		results = append(results, probeIntegrationEndpoint(ctx, node.Id, endpoint.url, probed))
+	}
+	return results
+}
+
+// probeIntegrationEndpoint sends a HEAD request to the scheme and host of an integration
+// node's apiEndpoint. The path is left out because it usually holds placeholders that are
+// only filled in at execution time. Any HTTP response, whatever its status, counts as reachable.
+func probeIntegrationEndpoint(ctx context.Context, nodeID string, apiEndpoint string, probed *endpointProbes) api.EndpointHealth {
+	health := api.EndpointHealth{NodeId: nodeID}
+	fail := func(message string) api.EndpointHealth {
+		health.Error = &message
+		return health
+	}
+
+	if !strings.HasPrefix(apiEndpoint, "http://") && !strings.HasPrefix(apiEndpoint, "https://") {
+		return fail("apiEndpoint must be an absolute http(s) URL")
+	}
+	// A placeholder in the host makes the URL unparseable or leaves braces in the host
+	parsed, err := url.Parse(apiEndpoint)
+	if err != nil || parsed.Host == "" || strings.ContainsAny(parsed.Host, "{}") {
+		return fail("apiEndpoint has no fixed host to probe")
+	}
+	health.Url = parsed.Scheme + "://" + parsed.Host
+
+	result := probed.probe(health.Url, func() api.EndpointHealth {
+		return headEndpoint(ctx, health)
+	})
+	result.NodeId = nodeID
+	return result
+}
+
+// headEndpoint sends the HEAD request for probeIntegrationEndpoint, giving up after
+// endpointProbeTimeout
+func headEndpoint(ctx context.Context, health api.EndpointHealth) api.EndpointHealth {
+	fail := func(message string) api.EndpointHealth {
+		health.Error = &message
+		return health
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, health.Url, nil)
+	if err != nil {
+		return fail(fmt.Sprintf("failed to create request: %v", err))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		slog.Warn("Integration endpoint unreachable", "error", err, "url", health.Url, "nodeID", health.NodeId)
+		return fail(fmt.Sprintf("failed to reach endpoint: %v", err))
+	}
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("Failed to close response body", "error", err)
+	}
+	health.Reachable = true
+	statusCode := resp.StatusCode
+	health.StatusCode = &statusCode
+	return health
+}
@@ -0,0 +1,400 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/cache"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	"workflow-code-test/api/pkg/db"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectCachedWorkflow serves workflow from the mock cache, as GetWorkflow reads it
+func expectCachedWorkflow(mockCache *cachemocks.MockCache, workflow api.Workflow) {
+	mockCache.EXPECT().
+		Get(gomock.Any(), "workflow:"+workflow.Id.String(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, dest any) error {
+			data, err := json.Marshal(workflow)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(data, dest)
+		})
+}
+
+// integrationNode returns an integration node calling apiEndpoint
+func integrationNode(id string, apiEndpoint string) api.WorkflowNode {
+	return api.WorkflowNode{
+		Id:   id,
+		Type: api.WorkflowNodeTypeIntegration,
+		Data: &api.NodeData{Metadata: &map[string]any{"apiEndpoint": apiEndpoint}},
+	}
+}
+
+func TestHandleGetWorkflowStatuses(t *testing.T) {
+	validID := openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"))
+	invalidID := openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"))
+	missingID := "550e8400-e29b-41d4-a716-446655440002"
+
+	valid := api.Workflow{
+		Id:   validID,
+		Name: strPtr("Weather Alert"),
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "end"}},
+	}
+	invalid := api.Workflow{
+		Id:   invalidID,
+		Name: strPtr("Broken"),
+		Nodes: &[]api.WorkflowNode{
+			{
+				Id:   "condition",
+				Type: api.WorkflowNodeTypeCondition,
+				Data: &api.NodeData{Metadata: &map[string]any{
+					"hasHandles": map[string]any{"source": []any{"true", "false"}},
+				}},
+			},
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "condition", Target: "end", SourceHandle: strPtr("maybe")}},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		query string
+
+		// Mock setup
+		setupMock func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"reports_each_workflow": {
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockCache.EXPECT().
+					Get(gomock.Any(), "workflow-status:probe=false", gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: "workflow-status:probe=false"})
+				mockDB.EXPECT().
+					ListWorkflowIDs(gomock.Any()).
+					Return([]string{validID.String(), invalidID.String(), missingID}, nil)
+
+				expectCachedWorkflow(mockCache, valid)
+				expectCachedWorkflow(mockCache, invalid)
+				mockCache.EXPECT().
+					Get(gomock.Any(), "workflow:"+missingID, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: "workflow:" + missingID})
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), missingID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrWorkflowNotFound, missingID))
+
+				mockCache.EXPECT().
+					Set(gomock.Any(), "workflow-status:probe=false", gomock.Any(), workflowStatusCacheTTL).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowHealthList
+				require.NoError(t, json.Unmarshal(body, &response))
+				assert.Equal(t, testNow, response.CheckedAt)
+				require.Len(t, response.Workflows, 3)
+
+				assert.Equal(t, validID.String(), response.Workflows[0].Id)
+				assert.Equal(t, "Weather Alert", *response.Workflows[0].Name)
+				assert.True(t, response.Workflows[0].Valid)
+				assert.Nil(t, response.Workflows[0].Error)
+				assert.Nil(t, response.Workflows[0].Endpoints, "endpoints are only reported when probed")
+
+				assert.False(t, response.Workflows[1].Valid)
+				require.NotNil(t, response.Workflows[1].Error)
+				assert.Contains(t, *response.Workflows[1].Error, "maybe")
+
+				assert.False(t, response.Workflows[2].Valid)
+				require.NotNil(t, response.Workflows[2].Error)
+				assert.Contains(t, *response.Workflows[2].Error, "workflow not found")
+			},
+		},
+
+		"serves_cached_result": {
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockCache.EXPECT().
+					Get(gomock.Any(), "workflow-status:probe=false", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, dest any) error {
+						*dest.(*api.WorkflowHealthList) = api.WorkflowHealthList{
+							CheckedAt: testNow,
+							Workflows: []api.WorkflowHealth{{Id: validID.String(), Valid: true}},
+						}
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowHealthList
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response.Workflows, 1)
+				assert.True(t, response.Workflows[0].Valid)
+			},
+		},
+
+		"invalid_probe_flag": {
+			query:          "?probe=sometimes",
+			setupMock:      func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				require.NoError(t, json.Unmarshal(body, &response))
+				assert.Equal(t, "probe must be a boolean", response.Error)
+			},
+		},
+
+		"database_error": {
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockCache.EXPECT().
+					Get(gomock.Any(), "workflow-status:probe=false", gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: "workflow-status:probe=false"})
+				mockDB.EXPECT().
+					ListWorkflowIDs(gomock.Any()).
+					Return(nil, errors.New("connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				require.NoError(t, json.Unmarshal(body, &response))
+				assert.Equal(t, "Failed to check workflows", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+			tc.setupMock(mockDB, mockCache)
+
+			service := &Service{db: mockDB, cache: mockCache, clock: fixedClock(testNow)}
+
+			req, err := http.NewRequest("GET", "/workflows/status"+tc.query, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			service.HandleGetWorkflowStatuses(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestWorkflowStatusesProbe(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.Equal(t, "/", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// A server that is closed straight away leaves an address nothing listens on
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	workflowID := openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"))
+	workflow := api.Workflow{
+		Id: workflowID,
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			integrationNode("weather", server.URL+"/weather/{city}?units=metric"),
+			integrationNode("forecast", server.URL+"/forecast/{city}"),
+			integrationNode("offline", closedURL+"/status"),
+			integrationNode("templated", "https://{region}.example.com/data"),
+			integrationNode("relative", "/weather/{city}"),
+			{
+				Id:   "regional",
+				Type: api.WorkflowNodeTypeIntegration,
+				Data: &api.NodeData{Metadata: &map[string]any{
+					"apiEndpoints": []any{server.URL + "/eu/{city}", closedURL + "/us/{city}"},
+				}},
+			},
+		},
+		Edges: &[]api.WorkflowEdge{
+			{Id: "e1", Source: "start", Target: "weather"},
+			{Id: "e2", Source: "weather", Target: "forecast"},
+			{Id: "e3", Source: "forecast", Target: "offline"},
+			{Id: "e4", Source: "offline", Target: "templated"},
+			{Id: "e5", Source: "templated", Target: "relative"},
+			{Id: "e6", Source: "relative", Target: "regional"},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+	mockCache := cachemocks.NewMockCache(ctrl)
+
+	mockCache.EXPECT().
+		Get(gomock.Any(), "workflow-status:probe=true", gomock.Any()).
+		Return(cache.ErrCacheMiss{Key: "workflow-status:probe=true"})
+	mockDB.EXPECT().ListWorkflowIDs(gomock.Any()).Return([]string{workflowID.String()}, nil)
+	expectCachedWorkflow(mockCache, workflow)
+	mockCache.EXPECT().
+		Set(gomock.Any(), "workflow-status:probe=true", gomock.Any(), workflowStatusCacheTTL).
+		Return(nil)
+
+	service := &Service{db: mockDB, cache: mockCache, clock: fixedClock(testNow)}
+	statuses, err := service.workflowStatuses(context.Background(), true)
+	require.NoError(t, err)
+
+	require.Len(t, statuses.Workflows, 1)
+	assert.True(t, statuses.Workflows[0].Valid)
+	require.NotNil(t, statuses.Workflows[0].Endpoints)
+	endpoints := *statuses.Workflows[0].Endpoints
+	require.Len(t, endpoints, 7)
+
+	// Any HTTP answer counts as reachable, and a shared host is only probed once
+	for _, endpoint := range endpoints[:2] {
+		assert.Equal(t, server.URL, endpoint.Url)
+		assert.True(t, endpoint.Reachable)
+		require.NotNil(t, endpoint.StatusCode)
+		assert.Equal(t, http.StatusNotFound, *endpoint.StatusCode)
+	}
+	assert.Equal(t, "weather", endpoints[0].NodeId)
+	assert.Equal(t, "forecast", endpoints[1].NodeId)
+	assert.Equal(t, int32(1), probes.Load())
+
+	assert.Equal(t, "offline", endpoints[2].NodeId)
+	assert.False(t, endpoints[2].Reachable)
+	require.NotNil(t, endpoints[2].Error)
+	assert.Contains(t, *endpoints[2].Error, "failed to reach endpoint")
+
+	assert.False(t, endpoints[3].Reachable)
+	assert.Equal(t, "apiEndpoint has no fixed host to probe", *endpoints[3].Error)
+
+	assert.False(t, endpoints[4].Reachable)
+	assert.Equal(t, "apiEndpoint must be an absolute http(s) URL", *endpoints[4].Error)
+
+	// Each endpoint of a multi-endpoint node is reported, reusing earlier probes of the same host
+	assert.Equal(t, "regional", endpoints[5].NodeId)
+	assert.True(t, endpoints[5].Reachable)
+	assert.Equal(t, "regional", endpoints[6].NodeId)
+	assert.False(t, endpoints[6].Reachable)
+	assert.Equal(t, int32(1), probes.Load())
+}
+
+func TestWorkflowStatusesProbeConcurrently(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+	mockCache := cachemocks.NewMockCache(ctrl)
+
+	mockCache.EXPECT().
+		Get(gomock.Any(), "workflow-status:probe=true", gomock.Any()).
+		Return(cache.ErrCacheMiss{Key: "workflow-status:probe=true"})
+
+	// More workflows than workers, all sharing one host. Loading each is slow enough that the
+	// workers overlap, so the peak number of loads in flight shows the pool's bound.
+	var ids []string
+	var inFlight, peak atomic.Int32
+	for range 3 * workflowStatusConcurrency {
+		workflow := api.Workflow{
+			Id:    openapi_types.UUID(uuid.New()),
+			Nodes: &[]api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, integrationNode("weather", server.URL+"/weather")},
+			Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "weather"}},
+		}
+		ids = append(ids, workflow.Id.String())
+		mockCache.EXPECT().
+			Get(gomock.Any(), "workflow:"+workflow.Id.String(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, dest any) error {
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					previous := peak.Load()
+					if current <= previous || peak.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+
+				data, err := json.Marshal(workflow)
+				if err != nil {
+					return err
+				}
+				return json.Unmarshal(data, dest)
+			})
+	}
+	mockDB.EXPECT().ListWorkflowIDs(gomock.Any()).Return(ids, nil)
+	mockCache.EXPECT().
+		Set(gomock.Any(), "workflow-status:probe=true", gomock.Any(), workflowStatusCacheTTL).
+		Return(nil)
+
+	service := &Service{db: mockDB, cache: mockCache, clock: fixedClock(testNow)}
+	statuses, err := service.workflowStatuses(context.Background(), true)
+	require.NoError(t, err)
+
+	// Results keep the listed order however the workers finish
+	require.Len(t, statuses.Workflows, len(ids))
+	for i, health := range statuses.Workflows {
+		assert.Equal(t, ids[i], health.Id)
+		require.NotNil(t, health.Endpoints)
+		require.Len(t, *health.Endpoints, 1)
+		assert.True(t, (*health.Endpoints)[0].Reachable)
+	}
+	assert.Equal(t, int32(1), probes.Load(), "a host shared by workflows checked at once is probed once")
+	assert.LessOrEqual(t, peak.Load(), int32(workflowStatusConcurrency))
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func TestWorkflowStatusesCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+	mockCache := cachemocks.NewMockCache(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Nothing is loaded or cached once the request has gone away
+	mockCache.EXPECT().
+		Get(gomock.Any(), "workflow-status:probe=true", gomock.Any()).
+		Return(cache.ErrCacheMiss{Key: "workflow-status:probe=true"})
+	mockDB.EXPECT().ListWorkflowIDs(gomock.Any()).Return([]string{uuid.NewString()}, nil)
+
+	service := &Service{db: mockDB, cache: mockCache, clock: fixedClock(testNow)}
+	_, err := service.workflowStatuses(ctx, true)
+	assert.ErrorIs(t, err, context.Canceled)
+}
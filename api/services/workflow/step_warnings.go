@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+)
+
+// maxStepWarnings bounds the warnings kept for one step
+const maxStepWarnings = 20
+
+// stepWarnings collects the soft problems met while one node runs, so they are returned on
+// its step rather than only logged. Fan-out calls warn concurrently, so messages are guarded by mu.
+type stepWarnings struct {
+	mu       sync.Mutex
+	messages []string
+	dropped  int
+}
+
+// add keeps a warning until the step has maxStepWarnings, then counts the rest
+func (w *stepWarnings) add(message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.messages) >= maxStepWarnings {
+		w.dropped++
+		return
+	}
+	w.messages = append(w.messages, message)
+}
+
+// list returns the collected warnings in the order they were raised, ending with a note when
+// some were dropped
+func (w *stepWarnings) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	messages := append([]string(nil), w.messages...)
+	if w.dropped > 0 {
+		messages = append(messages, fmt.Sprintf("%d more warnings omitted", w.dropped))
+	}
+	return messages
+}
+
+// stepWarningsKey is the context key carrying the warning collector of the node being executed
+type stepWarningsKey struct{}
+
+// withStepWarnings returns a context whose step warnings are collected in the returned collector
+func withStepWarnings(ctx context.Context) (context.Context, *stepWarnings) {
+	warnings := &stepWarnings{}
+	return context.WithValue(ctx, stepWarningsKey{}, warnings), warnings
+}
+
+// warnStep logs a warning and, when a node is being executed, adds it to that node's step.
+// The attributes are appended to the message as key=value pairs; the node's own ID is left out.
+func warnStep(ctx context.Context, message string, args ...any) {
+	slog.WarnContext(ctx, message, args...)
+
+	warnings, ok := ctx.Value(stepWarningsKey{}).(*stepWarnings)
+	if !ok {
+		return
+	}
+	record := slog.NewRecord(time.Time{}, slog.LevelWarn, message, 0)
+	record.Add(args...)
+	var details []string
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != "nodeID" && attr.Key != "nodeId" {
+			details = append(details, fmt.Sprintf("%s=%v", attr.Key, attr.Value.Resolve().Any()))
+		}
+		return true
+	})
+	if len(details) > 0 {
+		message += ": " + strings.Join(details, ", ")
+	}
+	warnings.add(message)
+}
+
+// addStepWarning adds a warning raised by the traversal, after the node's step was built
+func addStepWarning(step *api.ExecutionStep, message string) {
+	if step.Warnings == nil {
+		step.Warnings = &[]string{}
+	}
+	*step.Warnings = append(*step.Warnings, message)
+}
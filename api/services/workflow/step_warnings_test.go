@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "workflow-code-test/api/openapi"
+)
+
+func TestWarnStep(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		warn func(ctx context.Context)
+
+		expected []string
+	}{
+		"message_only": {
+			warn: func(ctx context.Context) {
+				warnStep(ctx, "API response too large to persist")
+			},
+			expected: []string{"API response too large to persist"},
+		},
+		"attributes_appended_without_node_id": {
+			warn: func(ctx context.Context) {
+				warnStep(ctx, "Output variable not found in API response", "nodeID", "integration-1", "variable", "humidity", "attempt", 2)
+			},
+			expected: []string{"Output variable not found in API response: variable=humidity, attempt=2"},
+		},
+		"kept_in_order_and_capped": {
+			warn: func(ctx context.Context) {
+				for i := range maxStepWarnings + 3 {
+					warnStep(ctx, fmt.Sprintf("warning %d", i))
+				}
+			},
+			expected: func() []string {
+				expected := make([]string, 0, maxStepWarnings+1)
+				for i := range maxStepWarnings {
+					expected = append(expected, fmt.Sprintf("warning %d", i))
+				}
+				return append(expected, "3 more warnings omitted")
+			}(),
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, warnings := withStepWarnings(context.Background())
+			tc.warn(ctx)
+			assert.Equal(t, tc.expected, warnings.list())
+		})
+	}
+}
+
+func TestWarnStepWithoutCollector(t *testing.T) {
+	assert.NotPanics(t, func() {
+		warnStep(context.Background(), "Nothing collects this")
+	})
+}
+
+func TestExecuteSingleNodeWarnings(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		vars map[string]any
+
+		expected *[]string
+	}{
+		"missing_fields_warned": {
+			vars:     map[string]any{"name": "Alice"},
+			expected: &[]string{"Expected input field not found in executeVars: field=email", "Expected input field not found in executeVars: field=city"},
+		},
+		"no_warnings_left_unset": {
+			vars: map[string]any{"name": "Alice", "email": "alice@example.com", "city": "Sydney"},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			node := api.WorkflowNode{Id: "form", Type: api.WorkflowNodeTypeForm, Data: &api.NodeData{Metadata: &map[string]any{
+				"inputFields":     []any{"name", "email", "city"},
+				"outputVariables": []any{"name"},
+			}}}
+
+			service := &Service{}
+			step := service.executeSingleNode(context.Background(), node, NewExecutionContext(tc.vars), api.WorkflowExecutionInput{})
+
+			assert.Equal(t, api.ExecutionStepStatusCompleted, step.Status)
+			assert.Equal(t, tc.expected, step.Warnings)
+		})
+	}
+}
+
+func TestStepWarningsRoundTrip(t *testing.T) {
+	output := map[string]any{}
+	warnings := []string{"Expected input field not found in executeVars: field=email"}
+	step := api.ExecutionStep{NodeId: "form", Type: "form", Status: api.ExecutionStepStatusCompleted, Output: &output, Warnings: &warnings}
+
+	record, err := mapStepToRecord(0, step)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["Expected input field not found in executeVars: field=email"]`, string(record.Warnings))
+
+	restored, err := mapRecordToStep(record)
+	require.NoError(t, err)
+	assert.Equal(t, step.Warnings, restored.Warnings)
+
+	// Steps without warnings store none
+	step.Warnings = nil
+	record, err = mapStepToRecord(0, step)
+	require.NoError(t, err)
+	assert.Nil(t, record.Warnings)
+}
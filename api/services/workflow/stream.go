@@ -0,0 +1,250 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Integration stream modes decode the response off the wire instead of reading it into memory
+const (
+	// StreamModeFields keeps only the values of the node's outputVariables
+	StreamModeFields = "fields"
+	// StreamModeCount counts the elements of an array without keeping them
+	StreamModeCount = "count"
+)
+
+// DefaultStreamCountVar receives the element count in count mode when no countVar is configured
+const DefaultStreamCountVar = "itemCount"
+
+// streamSettings is the parsed streaming configuration of an integration node
+type streamSettings struct {
+	mode       string
+	countVar   string
+	countField string
+}
+
+// parseStreamSettings reads streamMode, countVar and countField from integration metadata.
+// A nil result means the response is read in full as usual.
+func parseStreamSettings(metadata map[string]any) (*streamSettings, error) {
+	rawMode, exists := metadata["streamMode"]
+	if !exists {
+		return nil, nil
+	}
+	mode, ok := rawMode.(string)
+	if !ok || (mode != StreamModeFields && mode != StreamModeCount) {
+		return nil, fmt.Errorf("streamMode must be one of fields or count")
+	}
+	if _, hasResponseVar := metadata["responseVar"]; hasResponseVar {
+		return nil, fmt.Errorf("responseVar can't be used with streamMode")
+	}
+	if _, hasScalarOutputVar := metadata["scalarOutputVar"]; hasScalarOutputVar {
+		return nil, fmt.Errorf("scalarOutputVar can't be used with streamMode")
+	}
+
+	if mode == StreamModeFields && len(streamOutputKeys(metadata)) == 0 {
+		return nil, fmt.Errorf("streamMode fields needs outputVariables")
+	}
+
+	settings := &streamSettings{mode: mode, countVar: DefaultStreamCountVar}
+	if rawCountVar, exists := metadata["countVar"]; exists {
+		countVar, ok := rawCountVar.(string)
+		if !ok || countVar == "" {
+			return nil, fmt.Errorf("countVar must be a non-empty string")
+		}
+		if isReservedVariable(countVar) {
+			return nil, fmt.Errorf("countVar '%s' is reserved", countVar)
+		}
+		settings.countVar = countVar
+	}
+	if rawCountField, exists := metadata["countField"]; exists {
+		countField, ok := rawCountField.(string)
+		if !ok || countField == "" {
+			return nil, fmt.Errorf("countField must be a non-empty string")
+		}
+		settings.countField = countField
+	}
+	return settings, nil
+}
+
+// streamJSONFields walks a JSON object token by token and keeps only the values of the given
+// keys, searching up to maxDepth levels of nested objects like findValueInMap. Everything else
+// is skipped without being decoded, so memory use depends on the kept values, not the document.
+func streamJSONFields(r io.Reader, keys []string, maxDepth int) (map[string]any, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, fmt.Errorf("API response is not a JSON object")
+	}
+
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	candidates := make(map[string][]any, len(keys))
+	if err := streamObjectFields(decoder, wanted, 0, maxDepth, candidates); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any, len(candidates))
+	for key, values := range candidates {
+		fields[key] = preferNumeric(values)
+	}
+	return fields, nil
+}
+
+// streamObjectFields collects wanted values from the object whose opening brace was just read
+func streamObjectFields(decoder *json.Decoder, wanted map[string]bool, depth int, maxDepth int, candidates map[string][]any) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v", token)
+		}
+
+		if wanted[key] {
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			candidates[key] = append(candidates[key], normalizeJSONNumber(value))
+			continue
+		}
+
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+		delim, isDelim := token.(json.Delim)
+		switch {
+		case isDelim && delim == '{' && depth < maxDepth:
+			if err := streamObjectFields(decoder, wanted, depth+1, maxDepth, candidates); err != nil {
+				return err
+			}
+		case isDelim:
+			if err := skipJSONContainer(decoder); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing brace
+	_, err := decoder.Token()
+	return err
+}
+
+// countJSONArray counts the elements of a JSON array without decoding them. With field set,
+// the array is the value of that top-level key of an object; otherwise the document itself.
+func countJSONArray(r io.Reader, field string) (int, error) {
+	decoder := json.NewDecoder(r)
+
+	if field != "" {
+		if err := expectDelim(decoder, '{'); err != nil {
+			return 0, fmt.Errorf("API response is not a JSON object")
+		}
+		for {
+			if !decoder.More() {
+				return 0, fmt.Errorf("countField '%s' not found in API response", field)
+			}
+			token, err := decoder.Token()
+			if err != nil {
+				return 0, err
+			}
+			if token == field {
+				break
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := expectDelim(decoder, '['); err != nil {
+		return 0, fmt.Errorf("counted value is not a JSON array")
+	}
+	count := 0
+	for decoder.More() {
+		if err := skipJSONValue(decoder); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// expectDelim reads the next token and fails unless it is the given delimiter
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got %v", want, token)
+	}
+	return nil
+}
+
+// skipJSONValue reads past the next value, whatever its type
+func skipJSONValue(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := token.(json.Delim); ok {
+		return skipJSONContainer(decoder)
+	}
+	return nil
+}
+
+// skipJSONContainer reads past the rest of the object or array whose opening delimiter was just read
+func skipJSONContainer(decoder *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+// streamIntegrationResponse decodes a successful integration response according to its stream
+// mode. Fields mode returns just the outputVariables found in the response; count mode stores
+// the element count in output and returns an empty response.
+func streamIntegrationResponse(body io.Reader, stream *streamSettings, metadata map[string]any, output map[string]any) (map[string]any, error) {
+	if stream.mode == StreamModeCount {
+		count, err := countJSONArray(body, stream.countField)
+		if err != nil {
+			return nil, err
+		}
+		output[stream.countVar] = count
+		return map[string]any{}, nil
+	}
+
+	// Search as deep as the non-streamed extraction does
+	return streamJSONFields(body, streamOutputKeys(metadata), 2)
+}
+
+// streamOutputKeys returns the outputVariables names that fields mode keeps
+func streamOutputKeys(metadata map[string]any) []string {
+	var keys []string
+	if outputVarsList, ok := metadata["outputVariables"].([]any); ok {
+		for _, varName := range outputVarsList {
+			if varNameStr, ok := varName.(string); ok {
+				keys = append(keys, varNameStr)
+			}
+		}
+	}
+	return keys
+}
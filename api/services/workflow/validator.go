@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	api "workflow-code-test/api/openapi"
+)
+
+const (
+	// DefaultMaxWorkflowNodes is the node limit used when none is configured
+	DefaultMaxWorkflowNodes = 200
+	// DefaultMaxWorkflowEdges is the edge limit used when none is configured
+	DefaultMaxWorkflowEdges = 500
+	// DefaultMaxQueuedNodes is the traversal queue limit used when none is configured
+	DefaultMaxQueuedNodes = 1000
+	// DefaultMaxEnqueuedNodes is the limit on nodes queued over a whole traversal used when none is configured
+	DefaultMaxEnqueuedNodes = 5000
+)
+
+// WorkflowLimits bounds the size of a workflow definition and how broad its traversal may grow
+type WorkflowLimits struct {
+	MaxNodes int
+	MaxEdges int
+	// MaxQueued bounds the nodes waiting in the traversal queue at once
+	MaxQueued int
+	// MaxEnqueued bounds the nodes queued over the whole traversal
+	MaxEnqueued int
+}
+
+// ErrWorkflowTooLarge is returned when a workflow exceeds the configured limits
+type ErrWorkflowTooLarge struct {
+	Nodes    int
+	Edges    int
+	MaxNodes int
+	MaxEdges int
+}
+
+func (e ErrWorkflowTooLarge) Error() string {
+	return fmt.Sprintf("workflow exceeds size limits: %d nodes (max %d), %d edges (max %d)",
+		e.Nodes, e.MaxNodes, e.Edges, e.MaxEdges)
+}
+
+// ErrTraversalTooBroad is returned when a traversal fans out past the configured breadth limits
+type ErrTraversalTooBroad struct {
+	Queued      int
+	Enqueued    int
+	MaxQueued   int
+	MaxEnqueued int
+}
+
+func (e ErrTraversalTooBroad) Error() string {
+	return fmt.Sprintf("traversal exceeds breadth limits: %d nodes queued (max %d), %d nodes enqueued in total (max %d)",
+		e.Queued, e.MaxQueued, e.Enqueued, e.MaxEnqueued)
+}
+
+// ErrInvalidEdgeHandle is returned when an edge leaves a node through a handle the node does not declare
+type ErrInvalidEdgeHandle struct {
+	EdgeID   string
+	Source   string
+	Handle   string
+	Declared []string
+}
+
+func (e ErrInvalidEdgeHandle) Error() string {
+	return fmt.Sprintf("edge '%s' uses sourceHandle '%s' but node '%s' declares [%s]",
+		e.EdgeID, e.Handle, e.Source, strings.Join(e.Declared, ", "))
+}
+
+// ErrUnconnectedNodes is returned when a workflow has several nodes but no edges, so execution
+// could never leave the start node
+type ErrUnconnectedNodes struct {
+	Nodes int
+}
+
+func (e ErrUnconnectedNodes) Error() string {
+	return fmt.Sprintf("workflow has %d nodes but no edges connecting them", e.Nodes)
+}
+
+// workflowLimits returns the configured limits, falling back to the defaults
+func (s *Service) workflowLimits() WorkflowLimits {
+	limits := s.limits
+	if limits.MaxNodes <= 0 {
+		limits.MaxNodes = DefaultMaxWorkflowNodes
+	}
+	if limits.MaxEdges <= 0 {
+		limits.MaxEdges = DefaultMaxWorkflowEdges
+	}
+	if limits.MaxQueued <= 0 {
+		limits.MaxQueued = DefaultMaxQueuedNodes
+	}
+	if limits.MaxEnqueued <= 0 {
+		limits.MaxEnqueued = DefaultMaxEnqueuedNodes
+	}
+	return limits
+}
+
+// validateWorkflow checks a workflow definition before it is executed
+func (s *Service) validateWorkflow(workflow api.Workflow) error {
+	limits := s.workflowLimits()
+
+	var nodeCount, edgeCount int
+	if workflow.Nodes != nil {
+		nodeCount = len(*workflow.Nodes)
+	}
+	if workflow.Edges != nil {
+		edgeCount = len(*workflow.Edges)
+	}
+
+	if nodeCount > limits.MaxNodes || edgeCount > limits.MaxEdges {
+		return ErrWorkflowTooLarge{
+			Nodes:    nodeCount,
+			Edges:    edgeCount,
+			MaxNodes: limits.MaxNodes,
+			MaxEdges: limits.MaxEdges,
+		}
+	}
+
+	if err := validateConnected(workflow, edgeCount); err != nil {
+		return err
+	}
+
+	if err := validateEdgeHandles(workflow); err != nil {
+		return err
+	}
+
+	if err := validateExecutionOrder(workflow); err != nil {
+		return err
+	}
+
+	if err := validateLoops(workflow); err != nil {
+		return err
+	}
+
+	return validateDataflow(workflow)
+}
+
+// validateConnected rejects a workflow whose nodes were never connected. Note and comment
+// nodes are annotations and never connected, so they aren't counted.
+func validateConnected(workflow api.Workflow, edgeCount int) error {
+	if workflow.Nodes == nil || edgeCount > 0 {
+		return nil
+	}
+
+	var nodeCount int
+	for _, node := range *workflow.Nodes {
+		if !isAnnotationNode(node.Type) {
+			nodeCount++
+		}
+	}
+	if nodeCount > 1 {
+		return ErrUnconnectedNodes{Nodes: nodeCount}
+	}
+	return nil
+}
+
+// validateEdgeHandles checks each edge's sourceHandle against the handles its source node declares.
+// Only nodes declaring an explicit list in hasHandles.source are checked.
+func validateEdgeHandles(workflow api.Workflow) error {
+	if workflow.Nodes == nil || workflow.Edges == nil {
+		return nil
+	}
+
+	declared := make(map[string][]string)
+	for _, node := range *workflow.Nodes {
+		if handles, ok := declaredSourceHandles(node); ok {
+			declared[node.Id] = handles
+		}
+	}
+
+	for _, edge := range *workflow.Edges {
+		handles, ok := declared[edge.Source]
+		if !ok || edge.SourceHandle == nil {
+			continue
+		}
+		if !slices.Contains(handles, *edge.SourceHandle) {
+			return ErrInvalidEdgeHandle{
+				EdgeID:   edge.Id,
+				Source:   edge.Source,
+				Handle:   *edge.SourceHandle,
+				Declared: handles,
+			}
+		}
+	}
+
+	return nil
+}
+
+// declaredSourceHandles returns the handle names listed in a node's hasHandles.source metadata
+func declaredSourceHandles(node api.WorkflowNode) ([]string, bool) {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return nil, false
+	}
+
+	hasHandles, ok := (*node.Data.Metadata)["hasHandles"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	// A boolean source means the node has a single unnamed handle
+	source, ok := hasHandles["source"].([]any)
+	if !ok {
+		return nil, false
+	}
+
+	handles := make([]string, 0, len(source))
+	for _, handle := range source {
+		if name, ok := handle.(string); ok {
+			handles = append(handles, name)
+		}
+	}
+	return handles, true
+}
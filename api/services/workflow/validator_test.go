@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWorkflowLimits(t *testing.T) {
+	// buildWorkflow creates a workflow with the given number of nodes and edges
+	buildWorkflow := func(nodeCount, edgeCount int) api.Workflow {
+		nodes := make([]api.WorkflowNode, nodeCount)
+		for i := range nodes {
+			nodes[i] = api.WorkflowNode{Id: fmt.Sprintf("node-%d", i), Type: api.WorkflowNodeTypeForm}
+		}
+		edges := make([]api.WorkflowEdge, edgeCount)
+		for i := range edges {
+			edges[i] = api.WorkflowEdge{Id: fmt.Sprintf("edge-%d", i)}
+		}
+		return api.Workflow{Nodes: &nodes, Edges: &edges}
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		limits   WorkflowLimits
+		workflow api.Workflow
+
+		expectedError bool
+		errorContains string
+	}{
+		"within_configured_limits": {
+			limits:   WorkflowLimits{MaxNodes: 3, MaxEdges: 2},
+			workflow: buildWorkflow(3, 2),
+		},
+		"nil_nodes_and_edges": {
+			limits:   WorkflowLimits{MaxNodes: 3, MaxEdges: 2},
+			workflow: api.Workflow{},
+		},
+		"too_many_nodes": {
+			limits:        WorkflowLimits{MaxNodes: 3, MaxEdges: 2},
+			workflow:      buildWorkflow(4, 1),
+			expectedError: true,
+			errorContains: "4 nodes (max 3), 1 edges (max 2)",
+		},
+		"too_many_edges": {
+			limits:        WorkflowLimits{MaxNodes: 3, MaxEdges: 2},
+			workflow:      buildWorkflow(2, 3),
+			expectedError: true,
+			errorContains: "2 nodes (max 3), 3 edges (max 2)",
+		},
+		"unset_limits_use_defaults": {
+			workflow: buildWorkflow(DefaultMaxWorkflowNodes, DefaultMaxWorkflowEdges),
+		},
+		"unset_limits_reject_above_defaults": {
+			workflow:      buildWorkflow(DefaultMaxWorkflowNodes+1, 0),
+			expectedError: true,
+			errorContains: fmt.Sprintf("%d nodes (max %d)", DefaultMaxWorkflowNodes+1, DefaultMaxWorkflowNodes),
+		},
+	}
+
+	// Run test cases
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{limits: tt.limits}
+
+			err := service.validateWorkflow(tt.workflow)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrWorkflowTooLarge{})
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateEdgeHandles(t *testing.T) {
+	conditionNode := api.WorkflowNode{
+		Id:   "condition",
+		Type: api.WorkflowNodeTypeCondition,
+		Data: &api.NodeData{Metadata: &map[string]any{
+			"hasHandles": map[string]any{"source": []any{"true", "false"}, "target": true},
+		}},
+	}
+	formNode := api.WorkflowNode{
+		Id:   "form",
+		Type: api.WorkflowNodeTypeForm,
+		Data: &api.NodeData{Metadata: &map[string]any{
+			"hasHandles": map[string]any{"source": true, "target": true},
+		}},
+	}
+	endNode := api.WorkflowNode{Id: "end", Type: api.WorkflowNodeTypeEnd}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		edges []api.WorkflowEdge
+
+		expectedError bool
+		errorContains string
+	}{
+		"declared_handles": {
+			edges: []api.WorkflowEdge{
+				{Id: "e1", Source: "condition", Target: "end", SourceHandle: strPtr("true")},
+				{Id: "e2", Source: "condition", Target: "end", SourceHandle: strPtr("false")},
+			},
+		},
+		"typo_in_handle": {
+			edges: []api.WorkflowEdge{
+				{Id: "e1", Source: "condition", Target: "end", SourceHandle: strPtr("tru")},
+			},
+			expectedError: true,
+			errorContains: "edge 'e1' uses sourceHandle 'tru' but node 'condition' declares [true, false]",
+		},
+		"edge_without_handle": {
+			edges: []api.WorkflowEdge{
+				{Id: "e1", Source: "condition", Target: "end"},
+			},
+		},
+		"boolean_source_handles_are_not_checked": {
+			edges: []api.WorkflowEdge{
+				{Id: "e1", Source: "form", Target: "end", SourceHandle: strPtr("anything")},
+			},
+		},
+		"node_without_metadata_is_not_checked": {
+			edges: []api.WorkflowEdge{
+				{Id: "e1", Source: "end", Target: "form", SourceHandle: strPtr("anything")},
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{}
+			nodes := []api.WorkflowNode{conditionNode, formNode, endNode}
+			workflow := api.Workflow{Nodes: &nodes, Edges: &tt.edges}
+
+			err := service.validateWorkflow(workflow)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrInvalidEdgeHandle{})
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateConnected(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		nodes []api.WorkflowNode
+		edges *[]api.WorkflowEdge
+
+		expectedError bool
+		errorContains string
+	}{
+		"connected_nodes": {
+			nodes: []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "end", Type: api.WorkflowNodeTypeEnd}},
+			edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "end"}},
+		},
+		"single_node_without_edges": {
+			nodes: []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}},
+		},
+		"notes_are_not_counted": {
+			nodes: []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "note", Type: api.WorkflowNodeTypeNote}},
+			edges: &[]api.WorkflowEdge{},
+		},
+		"comments_are_not_counted": {
+			nodes: []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "comment", Type: api.WorkflowNodeTypeComment}},
+			edges: &[]api.WorkflowEdge{},
+		},
+		"nil_edges": {
+			nodes:         []api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, {Id: "end", Type: api.WorkflowNodeTypeEnd}},
+			expectedError: true,
+			errorContains: "workflow has 2 nodes but no edges connecting them",
+		},
+		"empty_edges": {
+			nodes: []api.WorkflowNode{
+				{Id: "start", Type: api.WorkflowNodeTypeStart},
+				{Id: "form", Type: api.WorkflowNodeTypeForm},
+				{Id: "note", Type: api.WorkflowNodeTypeNote},
+				{Id: "comment", Type: api.WorkflowNodeTypeComment},
+				{Id: "end", Type: api.WorkflowNodeTypeEnd},
+			},
+			edges:         &[]api.WorkflowEdge{},
+			expectedError: true,
+			errorContains: "workflow has 3 nodes but no edges connecting them",
+		},
+	}
+
+	// Run test cases
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{}
+
+			err := service.validateWorkflow(api.Workflow{Nodes: &tt.nodes, Edges: tt.edges})
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.ErrorAs(t, err, &ErrUnconnectedNodes{})
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
+	"workflow-code-test/api/pkg/db/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// encodeVersion encodes a workflow as stored in a version snapshot. Nil means version
+// history is not configured or the workflow could not be encoded, and no version is recorded.
+func (s *Service) encodeVersion(workflow *api.Workflow) []byte {
+	if s.versions == nil {
+		return nil
+	}
+	definition, err := json.Marshal(workflow)
+	if err != nil {
+		slog.Warn("Failed to encode workflow version", "error", err, "id", workflow.Id)
+		return nil
+	}
+	return definition
+}
+
+// nodeVersion returns the version stored along with a node update, preceded by previous when
+// the workflow has no versions yet. Nil means no version is recorded.
+func nodeVersion(previous []byte) *db.NodeVersion {
+	if previous == nil {
+		return nil
+	}
+	return &db.NodeVersion{
+		Previous: previous,
+		Snapshot: func(workflow *models.Workflow) ([]byte, error) {
+			apiWorkflow, err := MapDBWorkflowToAPI(workflow)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrWorkflowMapping, err)
+			}
+			return json.Marshal(apiWorkflow)
+		},
+	}
+}
+
+// DiffWorkflowVersions compares two stored versions of a workflow
+func (s *Service) DiffWorkflowVersions(ctx context.Context, workflowID string, from int, to int) (*api.WorkflowDiff, error) {
+	if s.versions == nil {
+		return nil, fmt.Errorf("workflow version history not configured")
+	}
+
+	before, err := s.loadVersion(ctx, workflowID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.loadVersion(ctx, workflowID, to)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow ID format: %v", err)
+	}
+
+	diff := &api.WorkflowDiff{
+		WorkflowId: openapi_types.UUID(id),
+		From:       from,
+		To:         to,
+	}
+	diff.Nodes.Added, diff.Nodes.Removed, diff.Nodes.Changed = diffByID(derefSlice(before.Nodes), derefSlice(after.Nodes),
+		func(node api.WorkflowNode) string { return node.Id },
+		func(before, after api.WorkflowNode) api.WorkflowNodeChange {
+			return api.WorkflowNodeChange{Id: after.Id, Before: before, After: after}
+		})
+	diff.Edges.Added, diff.Edges.Removed, diff.Edges.Changed = diffByID(derefSlice(before.Edges), derefSlice(after.Edges),
+		func(edge api.WorkflowEdge) string { return edge.Id },
+		func(before, after api.WorkflowEdge) api.WorkflowEdgeChange {
+			return api.WorkflowEdgeChange{Id: after.Id, Before: before, After: after}
+		})
+
+	return diff, nil
+}
+
+// loadVersion retrieves and decodes one stored version of a workflow
+func (s *Service) loadVersion(ctx context.Context, workflowID string, version int) (*api.Workflow, error) {
+	record, err := s.versions.GetWorkflowVersion(ctx, workflowID, version)
+	if err != nil {
+		return nil, err
+	}
+	var workflow api.Workflow
+	if err := json.Unmarshal(record.Definition, &workflow); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow version %d: %w", version, err)
+	}
+	return &workflow, nil
+}
+
+// diffByID matches items by ID and returns those only in after, those only in before, and
+// a change for each item in both whose encoding differs. Each list is ordered by ID.
+func diffByID[T any, C any](before []T, after []T, id func(T) string, change func(before, after T) C) ([]T, []T, []C) {
+	beforeByID := make(map[string]T, len(before))
+	for _, item := range before {
+		beforeByID[id(item)] = item
+	}
+	afterByID := make(map[string]T, len(after))
+	for _, item := range after {
+		afterByID[id(item)] = item
+	}
+
+	added, removed, changed := []T{}, []T{}, []C{}
+	for _, itemID := range slices.Sorted(maps.Keys(afterByID)) {
+		item := afterByID[itemID]
+		previous, existed := beforeByID[itemID]
+		if !existed {
+			added = append(added, item)
+			continue
+		}
+		// Encoded values compare metadata maps by content, with keys in sorted order
+		previousJSON, _ := json.Marshal(previous)
+		itemJSON, _ := json.Marshal(item)
+		if !bytes.Equal(previousJSON, itemJSON) {
+			changed = append(changed, change(previous, item))
+		}
+	}
+	for _, itemID := range slices.Sorted(maps.Keys(beforeByID)) {
+		if _, kept := afterByID[itemID]; !kept {
+			removed = append(removed, beforeByID[itemID])
+		}
+	}
+	return added, removed, changed
+}
+
+// derefSlice returns the slice items points at, or nil
+func derefSlice[T any](items *[]T) []T {
+	if items == nil {
+		return nil
+	}
+	return *items
+}
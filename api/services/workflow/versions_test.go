@@ -0,0 +1,346 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	"workflow-code-test/api/pkg/db"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+	"workflow-code-test/api/pkg/db/models"
+
+	"github.com/aarondl/null/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetWorkflowDiff(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	before := []byte(`{
+		"id": "550e8400-e29b-41d4-a716-446655440000",
+		"nodes": [
+			{"id": "start", "type": "start", "position": {"x": 0, "y": 0}},
+			{"id": "condition", "type": "condition", "position": {"x": 100, "y": 0}, "data": {"metadata": {"threshold": 25}}},
+			{"id": "email", "type": "email", "position": {"x": 200, "y": 0}},
+			{"id": "end", "type": "end", "position": {"x": 300, "y": 0}}
+		],
+		"edges": [
+			{"id": "e1", "source": "start", "target": "condition"},
+			{"id": "e2", "source": "condition", "target": "email", "sourceHandle": "true"},
+			{"id": "e3", "source": "email", "target": "end"}
+		]
+	}`)
+	after := []byte(`{
+		"id": "550e8400-e29b-41d4-a716-446655440000",
+		"nodes": [
+			{"id": "start", "type": "start", "position": {"x": 0, "y": 0}},
+			{"id": "condition", "type": "condition", "position": {"x": 100, "y": 0}, "data": {"metadata": {"threshold": 30}}},
+			{"id": "end", "type": "end", "position": {"x": 300, "y": 0}},
+			{"id": "alert", "type": "email", "position": {"x": 200, "y": 0}}
+		],
+		"edges": [
+			{"id": "e1", "source": "start", "target": "condition"},
+			{"id": "e2", "source": "condition", "target": "alert", "sourceHandle": "true"},
+			{"id": "e4", "source": "alert", "target": "end"}
+		]
+	}`)
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID string
+		query      string
+
+		// Mock setup
+		setupMock func(mockVersions *dbmocks.MockVersionDB)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"lists_added_removed_and_changed": {
+			query: "from=1&to=2",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 1).
+					Return(&db.WorkflowVersion{WorkflowID: workflowID, Version: 1, Definition: before}, nil)
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 2).
+					Return(&db.WorkflowVersion{WorkflowID: workflowID, Version: 2, Definition: after}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowDiff
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, workflowID, response.WorkflowId.String())
+				assert.Equal(t, 1, response.From)
+				assert.Equal(t, 2, response.To)
+
+				require.Len(t, response.Nodes.Added, 1)
+				assert.Equal(t, "alert", response.Nodes.Added[0].Id)
+				require.Len(t, response.Nodes.Removed, 1)
+				assert.Equal(t, "email", response.Nodes.Removed[0].Id)
+				require.Len(t, response.Nodes.Changed, 1)
+				assert.Equal(t, "condition", response.Nodes.Changed[0].Id)
+				assert.Equal(t, 25.0, (*response.Nodes.Changed[0].Before.Data.Metadata)["threshold"])
+				assert.Equal(t, 30.0, (*response.Nodes.Changed[0].After.Data.Metadata)["threshold"])
+
+				require.Len(t, response.Edges.Added, 1)
+				assert.Equal(t, "e4", response.Edges.Added[0].Id)
+				require.Len(t, response.Edges.Removed, 1)
+				assert.Equal(t, "e3", response.Edges.Removed[0].Id)
+				require.Len(t, response.Edges.Changed, 1)
+				assert.Equal(t, "e2", response.Edges.Changed[0].Id)
+				assert.Equal(t, "email", response.Edges.Changed[0].Before.Target)
+				assert.Equal(t, "alert", response.Edges.Changed[0].After.Target)
+			},
+		},
+
+		"same_version_has_empty_lists": {
+			query: "from=2&to=2",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 2).
+					Return(&db.WorkflowVersion{WorkflowID: workflowID, Version: 2, Definition: after}, nil).
+					Times(2)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				assert.JSONEq(t, `{
+					"workflowId": "550e8400-e29b-41d4-a716-446655440000",
+					"from": 2,
+					"to": 2,
+					"nodes": {"added": [], "removed": [], "changed": []},
+					"edges": {"added": [], "removed": [], "changed": []}
+				}`, string(body))
+			},
+		},
+
+		"version_not_found": {
+			query: "from=1&to=9",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 1).
+					Return(&db.WorkflowVersion{WorkflowID: workflowID, Version: 1, Definition: before}, nil)
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 9).
+					Return(nil, fmt.Errorf("%w: %d", db.ErrWorkflowVersionNotFound, 9))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Workflow version not found", response.Error)
+			},
+		},
+
+		"database_error": {
+			query: "from=1&to=2",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				mockVersions.EXPECT().
+					GetWorkflowVersion(gomock.Any(), workflowID, 1).
+					Return(nil, errors.New("database connection lost"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Failed to compare workflow versions", response.Error)
+			},
+		},
+
+		"missing_to_version": {
+			query: "from=1",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				// No DB call expected for invalid versions
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "to must be a positive integer", response.Error)
+			},
+		},
+
+		"zero_from_version": {
+			query: "from=0&to=2",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				// No DB call expected for invalid versions
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "from must be a positive integer", response.Error)
+			},
+		},
+
+		"invalid_workflow_id": {
+			workflowID: "not-a-uuid",
+			query:      "from=1&to=2",
+			setupMock: func(mockVersions *dbmocks.MockVersionDB) {
+				// No DB call expected for invalid IDs
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		if tc.workflowID == "" {
+			tc.workflowID = workflowID
+		}
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockVersions := dbmocks.NewMockVersionDB(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockVersions)
+
+			// Create service with mock
+			service := &Service{
+				versions: mockVersions,
+			}
+
+			// Create test request
+			url := fmt.Sprintf("/workflows/%s/diff?%s", tc.workflowID, tc.query)
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleGetWorkflowDiff(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestPatchNodeRecordsVersion(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// buildWorkflow returns the stored workflow with the condition's threshold
+	buildWorkflow := func(threshold int) *models.Workflow {
+		workflow := &models.Workflow{ID: workflowID, Name: "Test Workflow"}
+		workflow.R = workflow.R.NewStruct()
+		workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+			&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+			&models.WorkflowNode{
+				NodeID:   "condition",
+				Type:     "condition",
+				Position: []byte(`{"x":100,"y":0}`),
+				Data:     null.JSONFrom([]byte(fmt.Sprintf(`{"metadata": {"threshold": %d}}`, threshold))),
+			},
+			&models.WorkflowNode{NodeID: "end", Type: "end", Position: []byte(`{"x":200,"y":0}`)},
+		}
+		workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+			&models.WorkflowEdge{EdgeID: "e1", Source: "start", Target: "condition"},
+			&models.WorkflowEdge{EdgeID: "e2", Source: "condition", Target: "end"},
+		}
+		return workflow
+	}
+
+	// thresholdOf decodes a snapshot and returns the condition's threshold
+	thresholdOf := func(t *testing.T, definition []byte) any {
+		var workflow api.Workflow
+		require.NoError(t, json.Unmarshal(definition, &workflow))
+		for _, node := range *workflow.Nodes {
+			if node.Id == "condition" {
+				return (*node.Data.Metadata)["threshold"]
+			}
+		}
+		return nil
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		updateErr error
+
+		errorContains string
+	}{
+		"records_previous_and_patched_definitions": {},
+		// The version is written in the node update's transaction, so its failure fails the patch
+		"version_failure_fails_patch": {
+			updateErr:     errors.New("failed to create workflow version: database connection lost"),
+			errorContains: "failed to create workflow version",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+			mockVersions := dbmocks.NewMockVersionDB(ctrl)
+
+			// Setup expectations
+			mockDB.EXPECT().GetWorkflowByID(gomock.Any(), workflowID).Return(buildWorkflow(25), nil)
+			mockDB.EXPECT().
+				UpdateNodeData(gomock.Any(), workflowID, "condition", gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ string, _ []byte, _ null.Time, version *db.NodeVersion) error {
+					require.NotNil(t, version)
+					assert.Equal(t, 25.0, thresholdOf(t, version.Previous))
+					definition, err := version.Snapshot(buildWorkflow(30))
+					require.NoError(t, err)
+					assert.Equal(t, 30.0, thresholdOf(t, definition))
+					return tc.updateErr
+				})
+			if tc.updateErr == nil {
+				mockCache.EXPECT().Delete(gomock.Any(), "workflow:"+workflowID).Return(nil)
+			}
+
+			// Create service with mocks
+			service := &Service{db: mockDB, cache: mockCache, versions: mockVersions}
+
+			node, err := service.PatchNode(context.Background(), workflowID, "condition", map[string]any{
+				"metadata": map[string]any{"threshold": 30},
+			})
+			if tc.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 30.0, (*node.Data.Metadata)["threshold"])
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"workflow-code-test/api/pkg/secrets"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultWebhookSignatureHeader carries the request signature when no header is configured
+const DefaultWebhookSignatureHeader = "X-Signature-256"
+
+// webhookSecretPrefix prefixes the per-workflow secret name, e.g. WEBHOOK_550E8400_E29B_...
+const webhookSecretPrefix = "WEBHOOK_"
+
+// webhookSecretName returns the secret store name holding a workflow's webhook secret
+func webhookSecretName(workflowID string) string {
+	return webhookSecretPrefix + strings.ToUpper(strings.ReplaceAll(workflowID, "-", "_"))
+}
+
+// webhookSignatureHeader returns the configured signature header, falling back to the default
+func (s *Service) webhookSignatureHeader() string {
+	if s.signatureHeader == "" {
+		return DefaultWebhookSignatureHeader
+	}
+	return s.signatureHeader
+}
+
+// verifyWebhookSignature rejects requests whose body does not match the HMAC-SHA256 signature
+// header, for workflows that have a webhook secret. Workflows without one are not checked.
+func (s *Service) verifyWebhookSignature(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.secrets == nil {
+			next(w, r)
+			return
+		}
+
+		id := mux.Vars(r)["id"]
+		secret, err := s.secrets.Get(r.Context(), webhookSecretName(id))
+		if err != nil {
+			var notFound secrets.ErrSecretNotFound
+			if errors.As(err, &notFound) {
+				next(w, r)
+				return
+			}
+			slog.Error("Failed to load webhook secret", "error", err, "id", id)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to verify webhook signature")
+			return
+		}
+
+		// Read the raw body for verification, then hand it back to the JSON decoder
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			slog.Error("Failed to read request body", "error", err, "id", id)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !validSignature(body, secret, r.Header.Get(s.webhookSignatureHeader())) {
+			slog.Warn("Rejected webhook with invalid signature", "id", id)
+			writeErrorResponse(w, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// validSignature reports whether signature is the hex HMAC-SHA256 of body under secret.
+// An optional "sha256=" prefix, as sent by GitHub, is accepted.
+func validSignature(body []byte, secret string, signature string) bool {
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/secrets"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSecretStore fails every lookup
+type failingSecretStore struct{}
+
+func (failingSecretStore) Get(_ context.Context, _ string) (string, error) {
+	return "", errors.New("vault unavailable")
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	body := `{"formData":{"city":"Sydney"}}`
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	withSecret := staticSecretStore{"WEBHOOK_550E8400_E29B_41D4_A716_446655440000": "webhook-secret"}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		store           secrets.SecretStore
+		signatureHeader string
+		headers         map[string]string
+
+		// Expected response
+		expectedStatus int
+		expectedError  string
+	}{
+		"valid_signature": {
+			store:          withSecret,
+			headers:        map[string]string{DefaultWebhookSignatureHeader: sign("webhook-secret")},
+			expectedStatus: http.StatusOK,
+		},
+		"valid_prefixed_signature": {
+			store:          withSecret,
+			headers:        map[string]string{DefaultWebhookSignatureHeader: "sha256=" + sign("webhook-secret")},
+			expectedStatus: http.StatusOK,
+		},
+		"custom_signature_header": {
+			store:           withSecret,
+			signatureHeader: "X-Hub-Signature-256",
+			headers:         map[string]string{"X-Hub-Signature-256": "sha256=" + sign("webhook-secret")},
+			expectedStatus:  http.StatusOK,
+		},
+		"wrong_secret": {
+			store:          withSecret,
+			headers:        map[string]string{DefaultWebhookSignatureHeader: sign("other-secret")},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Invalid webhook signature",
+		},
+		"malformed_signature": {
+			store:          withSecret,
+			headers:        map[string]string{DefaultWebhookSignatureHeader: "not-hex"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Invalid webhook signature",
+		},
+		"missing_signature": {
+			store:          withSecret,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Invalid webhook signature",
+		},
+		"workflow_without_secret": {
+			store:          staticSecretStore{},
+			expectedStatus: http.StatusOK,
+		},
+		"no_secret_store": {
+			expectedStatus: http.StatusOK,
+		},
+		"secret_store_error": {
+			store:          failingSecretStore{},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to verify webhook signature",
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := &Service{
+				secrets:         tc.store,
+				signatureHeader: tc.signatureHeader,
+			}
+
+			// The wrapped handler must still see the full body
+			var received string
+			handler := service.verifyWebhookSignature(func(w http.ResponseWriter, r *http.Request) {
+				data, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				received = string(data)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			// Create test request
+			req, err := http.NewRequest("POST", "/workflows/"+workflowID+"/execute", strings.NewReader(body))
+			require.NoError(t, err)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": workflowID})
+
+			// Call the handler
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			// Check response
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedError != "" {
+				var response api.Error
+				err := json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedError, response.Error)
+				return
+			}
+			assert.Equal(t, body, received)
+		})
+	}
+}
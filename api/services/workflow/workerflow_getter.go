@@ -2,15 +2,38 @@ package workflow
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"time"
 	api "workflow-code-test/api/openapi"
 	"workflow-code-test/api/pkg/cache"
+	"workflow-code-test/api/pkg/db"
 )
 
 const workflowCachePrefix = "workflow"
 
+// GetWorkflow wraps its failures in one of these, so callers can tell them apart with errors.Is
+var (
+	// ErrWorkflowNotFound means no workflow has the requested ID
+	ErrWorkflowNotFound = db.ErrWorkflowNotFound
+	// ErrWorkflowMapping means the stored workflow could not be converted to the API model
+	ErrWorkflowMapping = errors.New("failed to map workflow")
+	// ErrDatabase means the workflow could not be read from the database
+	ErrDatabase = errors.New("failed to read workflow from database")
+)
+
+// DefaultMaxCacheEntryBytes is the largest encoded workflow cached when no limit is configured
+const DefaultMaxCacheEntryBytes = 1 << 20
+
+// maxCacheEntryBytes returns the configured cache entry limit, falling back to the default
+func (s *Service) maxCacheEntryBytes() int {
+	if s.maxCacheEntry <= 0 {
+		return DefaultMaxCacheEntryBytes
+	}
+	return s.maxCacheEntry
+}
+
 // GetWorkflow retrieves a workflow by ID from cache or database
 func (s *Service) GetWorkflow(ctx context.Context, workflowID string) (*api.Workflow, error) {
 	// Generate cache key
@@ -30,19 +53,38 @@ func (s *Service) GetWorkflow(ctx context.Context, workflowID string) (*api.Work
 
 	// Get workflow from database using repository
 	workflow, err := s.db.GetWorkflowByID(ctx, workflowID)
-	if err != nil {
+	if errors.Is(err, ErrWorkflowNotFound) {
 		return nil, err
 	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
 
 	// Convert DB model to API model using mapper
 	apiWorkflowPtr, err := MapDBWorkflowToAPI(workflow)
 	if err != nil {
-		return nil, fmt.Errorf("failed to map workflow: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrWorkflowMapping, err)
+	}
+
+	// Rarely read workflows are left out of the cache so they don't evict frequently read ones
+	if !s.shouldCache(apiWorkflowPtr, workflowID) {
+		slog.Debug("Workflow not read often enough to cache", "id", workflowID)
+		return apiWorkflowPtr, nil
+	}
+
+	// Encode up front so oversized workflows are served from the database instead of cached
+	data, err := json.Marshal(apiWorkflowPtr)
+	if err != nil {
+		slog.Warn("Failed to encode workflow for cache", "error", err, "id", workflowID)
+		return apiWorkflowPtr, nil
+	}
+	if limit := s.maxCacheEntryBytes(); len(data) > limit {
+		slog.Warn("Workflow too large to cache", "id", workflowID, "bytes", len(data), "maxBytes", limit)
+		return apiWorkflowPtr, nil
 	}
 
-	// Store in cache (cache will handle JSON marshaling)
 	// Cache for 5 minutes
-	if err := s.cache.Set(ctx, cacheKey, apiWorkflowPtr, 5*time.Minute); err != nil {
+	if err := s.cache.Set(ctx, cacheKey, json.RawMessage(data), workflowCacheTTL); err != nil {
 		slog.Warn("Failed to cache workflow", "error", err, "id", workflowID)
 		// Continue even if caching fails
 	} else {
@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"workflow-code-test/api/pkg/cache"
+	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	"workflow-code-test/api/pkg/db"
+	dbmocks "workflow-code-test/api/pkg/db/mocks"
+	"workflow-code-test/api/pkg/db/models"
+
+	"github.com/aarondl/null/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWorkflowCacheEntryLimit(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	cacheKey := "workflow:" + workflowID
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		maxCacheEntry int
+
+		// Expected results
+		expectCached bool
+	}{
+		"cached_under_default_limit": {
+			expectCached: true,
+		},
+		"skipped_over_configured_limit": {
+			maxCacheEntry: 64,
+			expectCached:  false,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
+			mockCache.EXPECT().
+				Get(gomock.Any(), cacheKey, gomock.Any()).
+				Return(cache.ErrCacheMiss{Key: cacheKey})
+			mockDB.EXPECT().
+				GetWorkflowByID(gomock.Any(), workflowID).
+				Return(&models.Workflow{
+					ID:          workflowID,
+					Name:        "Test Workflow",
+					Description: null.StringFrom("A description long enough to push the encoded workflow past the limit"),
+				}, nil)
+			if tc.expectCached {
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, value any, _ any) error {
+						// The workflow is handed over already encoded
+						assert.True(t, json.Valid(value.(json.RawMessage)))
+						return nil
+					})
+			}
+
+			// Create service with mocks
+			service := &Service{
+				db:            mockDB,
+				cache:         mockCache,
+				maxCacheEntry: tc.maxCacheEntry,
+			}
+
+			// Execute the function
+			workflow, err := service.GetWorkflow(context.Background(), workflowID)
+
+			// Assert results
+			require.NoError(t, err)
+			require.NotNil(t, workflow.Name)
+			assert.Equal(t, "Test Workflow", *workflow.Name)
+		})
+	}
+}
+
+func TestGetWorkflowErrors(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	cacheKey := "workflow:" + workflowID
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflow *models.Workflow
+		dbErr    error
+
+		// Expected results
+		expectedErr error
+		otherErrs   []error
+	}{
+		"not_found": {
+			dbErr:       fmt.Errorf("%w: %s", db.ErrWorkflowNotFound, workflowID),
+			expectedErr: ErrWorkflowNotFound,
+			otherErrs:   []error{ErrWorkflowMapping, ErrDatabase},
+		},
+		"database_down": {
+			dbErr:       errors.New("connection refused"),
+			expectedErr: ErrDatabase,
+			otherErrs:   []error{ErrWorkflowNotFound, ErrWorkflowMapping},
+		},
+		"mapping_failed": {
+			workflow:    &models.Workflow{ID: "invalid-uuid", Name: "Test Workflow"},
+			expectedErr: ErrWorkflowMapping,
+			otherErrs:   []error{ErrWorkflowNotFound, ErrDatabase},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
+			mockCache.EXPECT().
+				Get(gomock.Any(), cacheKey, gomock.Any()).
+				Return(cache.ErrCacheMiss{Key: cacheKey})
+			mockDB.EXPECT().
+				GetWorkflowByID(gomock.Any(), workflowID).
+				Return(tc.workflow, tc.dbErr)
+
+			// Create service with mocks
+			service := &Service{db: mockDB, cache: mockCache}
+
+			// Execute the function
+			_, err := service.GetWorkflow(context.Background(), workflowID)
+
+			// Assert results
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			for _, other := range tc.otherErrs {
+				assert.NotErrorIs(t, err, other)
+			}
+		})
+	}
+}
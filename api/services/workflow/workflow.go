@@ -1,13 +1,18 @@
 package workflow
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 
 	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/db"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -19,17 +24,29 @@ func (s *Service) HandleGetWorkflow(w http.ResponseWriter, r *http.Request) {
 	// Set Content-Type header for all responses
 	w.Header().Set("Content-Type", "application/json")
 
+	// Validate workflow ID before the cache or database is consulted
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
 	// Use the GetWorkflow function to retrieve the workflow
 	apiWorkflow, err := s.GetWorkflow(r.Context(), id)
 	if err != nil {
 		slog.Error("Failed to get workflow", "error", err, "id", id)
 
 		// Check if workflow not found
-		if err.Error() == fmt.Sprintf("workflow not found: %s", id) {
+		if isNotFound(err) {
 			writeErrorResponse(w, http.StatusNotFound, "Workflow not found")
 			return
 		}
 
+		// Check if the stored definition could not be read
+		if errors.Is(err, ErrWorkflowMapping) {
+			writeErrorResponse(w, http.StatusInternalServerError, "Stored workflow definition is invalid")
+			return
+		}
+
 		// Other errors
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve workflow")
 		return
@@ -42,6 +59,68 @@ func (s *Service) HandleGetWorkflow(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleGetWorkflowDiff returns the nodes and edges that changed between two stored versions of a workflow
+func (s *Service) HandleGetWorkflowDiff(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.Debug("Comparing workflow versions", "id", id)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Validate workflow ID and versions before querying
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+	from, to, err := parseDiffVersions(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diff, err := s.DiffWorkflowVersions(r.Context(), id, from, to)
+	if err != nil {
+		slog.Error("Failed to diff workflow versions", "error", err, "id", id, "from", from, "to", to)
+
+		// Check if either version is missing
+		if errors.Is(err, db.ErrWorkflowVersionNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Workflow version not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to compare workflow versions")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleGetDemoWorkflow returns the built-in demo workflow, which needs no database
+func (s *Service) HandleGetDemoWorkflow(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("Returning demo workflow definition")
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	apiWorkflow, err := DemoWorkflow()
+	if err != nil {
+		slog.Error("Failed to load demo workflow", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve workflow")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(apiWorkflow); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
 // HandleExecuteWorkflow executes a workflow with the provided input data
 func (s *Service) HandleExecuteWorkflow(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
@@ -50,21 +129,103 @@ func (s *Service) HandleExecuteWorkflow(w http.ResponseWriter, r *http.Request)
 	// Set Content-Type header for all responses
 	w.Header().Set("Content-Type", "application/json")
 
+	// CI smoke tests post the definition itself; it is run without touching the database or cache
+	switch source := api.ExecuteWorkflowParamsSource(r.URL.Query().Get("source")); source {
+	case "", api.Stored:
+	case api.Inline:
+		s.HandleExecuteWorkflowDefinition(w, r)
+		return
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid source '%s'", source))
+		return
+	}
+
 	// Parse request body
 	var input api.WorkflowExecutionInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+	var raw struct {
+		Condition rawCondition `json:"condition"`
+	}
+	data, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(data, &input)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
 		slog.Error("Failed to parse request body", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	// Reject a condition that parses but can't be evaluated, e.g. an unknown operator
+	conditionCtx, fields := validateConditionInput(r.Context(), input.Condition, raw.Condition)
+	if len(fields) > 0 {
+		writeValidationErrorResponse(w, ErrInvalidInput{Fields: fields})
+		return
+	}
+	r = r.WithContext(conditionCtx)
+
+	// Reject labels that could not be filtered on later
+	if err := validateLabels(input.Labels); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Reject run-time overrides outside their allowed range
+	if err := validateExecutionOptions(input.ExecutionOptions); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Parse response filtering options
+	filter, err := parseResponseFilter(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Bound the execution by the client's X-Request-Timeout, capped at the server's maximum
+	ctx, cancel, err := s.withRequestTimeout(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer cancel()
+
+	// Expose allow-listed request headers, e.g. a webhook's tenant ID, as reserved variables
+	if len(s.headers) > 0 {
+		ctx = withRequestHeaders(ctx, r.Header, s.headers)
+	}
+
 	// Execute workflow
-	result, err := s.ExecuteWorkflow(r.Context(), id, input)
+	result, err := s.ExecuteWorkflow(ctx, id, input)
+
+	// Reaching the deadline stops the remaining nodes, so the partial result is not returned
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.Warn("Workflow execution timed out", "id", id, "timeout", r.Header.Get(RequestTimeoutHeader))
+		writeErrorResponse(w, http.StatusGatewayTimeout, "Workflow execution timed out")
+		return
+	}
+
 	if err != nil {
 		slog.Error("Failed to execute workflow", "error", err, "id", id)
 
+		// Check if the workflow or its input was rejected before any node ran
+		if status, invalid := validationStatus(err); invalid != nil {
+			writeValidationError(w, status, invalid)
+			return
+		}
+
+		// Check if the workflow already has too many executions running
+		var tooMany ErrTooManyExecutions
+		if errors.As(err, &tooMany) {
+			writeErrorResponse(w, http.StatusTooManyRequests, tooMany.Error())
+			return
+		}
+
 		// Check if workflow not found
-		if err.Error() == fmt.Sprintf("workflow not found: workflow not found: %s", id) {
+		if isNotFound(err) {
 			writeErrorResponse(w, http.StatusNotFound, "Workflow not found")
 			return
 		}
@@ -74,9 +235,387 @@ func (s *Service) HandleExecuteWorkflow(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Send response
+	body, err := filter.apply(result)
+	if err != nil {
+		slog.Error("Failed to rename response keys", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+	if err := writeResultResponse(w, http.StatusOK, body); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleExecuteWorkflowDefinition executes a workflow definition posted in the body without persisting it
+func (s *Service) HandleExecuteWorkflowDefinition(w http.ResponseWriter, r *http.Request) {
+	slog.Debug("Handling inline workflow execution")
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse request body
+	var body api.WorkflowDefinitionExecutionInput
+	var raw struct {
+		Input struct {
+			Condition rawCondition `json:"condition"`
+		} `json:"input"`
+	}
+	data, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(data, &body)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		slog.Error("Failed to parse request body", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var input api.WorkflowExecutionInput
+	if body.Input != nil {
+		input = *body.Input
+	}
+
+	// Reject a condition that parses but can't be evaluated, e.g. an unknown operator
+	ctx, fields := validateConditionInput(r.Context(), input.Condition, raw.Input.Condition)
+	if len(fields) > 0 {
+		writeValidationErrorResponse(w, ErrInvalidInput{Fields: fields})
+		return
+	}
+
+	// Reject labels that stored executions would not accept either
+	if err := validateLabels(input.Labels); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Reject run-time overrides outside their allowed range
+	if err := validateExecutionOptions(input.ExecutionOptions); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Expose allow-listed request headers as reserved variables, as for stored workflows
+	if len(s.headers) > 0 {
+		ctx = withRequestHeaders(ctx, r.Header, s.headers)
+	}
+
+	// Execute workflow
+	result, err := s.ExecuteWorkflowDefinition(ctx, body.Workflow, input)
+	if err != nil {
+		slog.Error("Failed to execute inline workflow", "error", err)
+
+		// Check if the workflow or its input was rejected before any node ran
+		if status, invalid := validationStatus(err); invalid != nil {
+			writeValidationError(w, status, invalid)
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to execute workflow")
+		return
+	}
+
+	// Send response
+	if err := writeResultResponse(w, http.StatusOK, result); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleListExecutions returns a page of a workflow's stored executions, optionally filtered by label
+func (s *Service) HandleListExecutions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.Debug("Listing executions", "id", id)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Validate workflow ID before querying
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	// Parse label filters and pagination
+	filter, err := parseExecutionFilter(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := s.ListExecutions(r.Context(), id, filter)
+	if err != nil {
+		slog.Error("Failed to list executions", "error", err, "id", id)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list executions")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleGetExecution returns a stored execution result
+func (s *Service) HandleGetExecution(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	execID := mux.Vars(r)["execId"]
+	slog.Debug("Returning execution", "id", id, "executionID", execID)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Validate workflow and execution IDs before querying
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+	if _, err := uuid.Parse(execID); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid execution ID")
+		return
+	}
+
+	// Parse response filtering options
+	filter, err := parseResponseFilter(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Parse step filters and pagination
+	stepFilter, err := parseStepFilter(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.GetExecution(r.Context(), id, execID, stepFilter)
+	if err != nil {
+		slog.Error("Failed to get execution", "error", err, "id", id, "executionID", execID)
+
+		// Check if execution not found
+		if errors.Is(err, db.ErrExecutionNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Execution not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve execution")
+		return
+	}
+
+	// Send response
+	body, err := filter.apply(result)
+	if err != nil {
+		slog.Error("Failed to rename response keys", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+	if err := writeResultResponse(w, http.StatusOK, body); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleGetExecutionLogs returns the log entries written while a stored execution ran
+func (s *Service) HandleGetExecutionLogs(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	execID := mux.Vars(r)["execId"]
+	slog.Debug("Returning execution logs", "id", id, "executionID", execID)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Validate workflow and execution IDs before querying
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+	if _, err := uuid.Parse(execID); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid execution ID")
+		return
+	}
+
+	logs, err := s.GetExecutionLogs(r.Context(), id, execID)
+	if err != nil {
+		slog.Error("Failed to get execution logs", "error", err, "id", id, "executionID", execID)
+
+		// Check if execution not found
+		if errors.Is(err, db.ErrExecutionNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Execution not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve execution logs")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(logs); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleReplayExecution re-runs a stored execution's input and returns the new execution
+func (s *Service) HandleReplayExecution(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	execID := mux.Vars(r)["execId"]
+	slog.Debug("Handling execution replay", "id", id, "executionID", execID)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Validate workflow and execution IDs before querying
+	if _, err := uuid.Parse(id); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+	if _, err := uuid.Parse(execID); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid execution ID")
+		return
+	}
+
+	result, err := s.ReplayExecution(r.Context(), id, execID)
+	if err != nil {
+		slog.Error("Failed to replay execution", "error", err, "id", id, "executionID", execID)
+
+		// Check if execution not found
+		if errors.Is(err, db.ErrExecutionNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Execution not found")
+			return
+		}
+
+		// Check if the current workflow definition no longer validates or the input can't be run
+		if status, invalid := validationStatus(err); invalid != nil {
+			writeValidationError(w, status, invalid)
+			return
+		}
+
+		// Check if the workflow already has too many executions running
+		var tooMany ErrTooManyExecutions
+		if errors.As(err, &tooMany) {
+			writeErrorResponse(w, http.StatusTooManyRequests, tooMany.Error())
+			return
+		}
+
+		// Check if workflow not found
+		if isNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Workflow not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to replay execution")
+		return
+	}
+
+	// Send response
+	if err := writeResultResponse(w, http.StatusOK, result); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandlePatchNode merges a partial node data object into a single stored node
+func (s *Service) HandlePatchNode(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	nodeID := mux.Vars(r)["nodeId"]
+	slog.Debug("Patching node", "id", id, "nodeID", nodeID)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse request body
+	var patch map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		slog.Error("Failed to parse request body", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	node, err := s.PatchNode(r.Context(), id, nodeID, patch)
+	if err != nil {
+		slog.Error("Failed to patch node", "error", err, "id", id, "nodeID", nodeID)
+
+		// Check if the patched workflow is invalid
+		if status, invalid := validationStatus(err); invalid != nil {
+			writeErrorResponse(w, status, err.Error())
+			return
+		}
+
+		// Check if another request updated the node first
+		var conflict db.ErrNodeConflict
+		if errors.As(err, &conflict) {
+			writeErrorResponse(w, http.StatusConflict, "Node was modified by another request, retry the patch")
+			return
+		}
+
+		// Check if workflow or node not found
+		if isNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Workflow not found")
+			return
+		}
+		if errors.Is(err, db.ErrNodeNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Node not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update node")
+		return
+	}
+
+	// Send response
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(node); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// HandleExecuteNode runs a single node in isolation and returns its step result
+func (s *Service) HandleExecuteNode(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	nodeID := mux.Vars(r)["nodeId"]
+	slog.Debug("Handling node execution", "id", id, "nodeID", nodeID)
+
+	// Set Content-Type header for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse request body
+	// The body is optional; an empty body runs the node with no variables
+	var input api.NodeExecutionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("Failed to parse request body", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	step, err := s.ExecuteNode(r.Context(), id, nodeID, input)
+	if err != nil {
+		slog.Error("Failed to execute node", "error", err, "id", id, "nodeID", nodeID)
+
+		// Check if workflow or node not found
+		if isNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Workflow not found")
+			return
+		}
+		if errors.Is(err, db.ErrNodeNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Node not found")
+			return
+		}
+
+		// Other errors
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to execute node")
+		return
+	}
+
 	// Send response
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(result); err != nil {
+	if err := json.NewEncoder(w).Encode(step); err != nil {
 		slog.Error("Failed to encode response", "error", err)
 	}
 }
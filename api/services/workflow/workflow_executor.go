@@ -7,49 +7,174 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	api "workflow-code-test/api/openapi"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 const StartNodeID = "start"
 
+// ErrorHandle is the sourceHandle of a condition edge followed when the condition itself fails
+const ErrorHandle = "error"
+
+// emailSenderAddress is the From address of every email node message
+const emailSenderAddress = "weather-alerts@example.com"
+
+// DefaultConditionEpsilon is the tolerance used by equals/not_equals when none is configured
+const DefaultConditionEpsilon = 1e-9
+
+// DefaultConditionField is the variable a condition node compares when no field is configured
+const DefaultConditionField = "temperature"
+
+// Condition numeric modes; float is the default
+const (
+	NumericModeFloat   = "float"
+	NumericModeDecimal = "decimal"
+)
+
+// Comparison bases reported with a condition's result. Numeric conditions compare numbers,
+// in float or decimal mode; truthy conditions evaluate the field as a boolean.
+const (
+	ComparisonNumeric = "numeric"
+	ComparisonBoolean = "boolean"
+)
+
+// DefaultConditionMessage is the condition node message used when metadata has no messageTemplate
+const DefaultConditionMessage = "{{actualValue}} {{operator}} {{threshold}} is {{conditionMet}}"
+
+// DefaultTruthyConditionMessage is the condition message template for the truthy operator, which has no threshold
+const DefaultTruthyConditionMessage = "{{actualValue}} {{operator}} is {{conditionMet}}"
+
 // ExecuteWorkflow handles the actual workflow execution
 func (s *Service) ExecuteWorkflow(ctx context.Context, workflowID string, input api.WorkflowExecutionInput) (*api.WorkflowExecutionResult, error) {
+	return s.runWorkflow(ctx, workflowID, input, nil)
+}
+
+// ExecuteWorkflowDefinition runs an unsaved workflow definition through the same validation and
+// execution as stored workflows. Nothing is cached or persisted and no execution ID is assigned.
+func (s *Service) ExecuteWorkflowDefinition(ctx context.Context, workflow api.Workflow, input api.WorkflowExecutionInput) (*api.WorkflowExecutionResult, error) {
+	result := &api.WorkflowExecutionResult{
+		ExecutedAt: s.now(),
+		Status:     api.WorkflowExecutionResultStatusCompleted,
+		Steps:      []api.ExecutionStep{},
+		Labels:     input.Labels,
+	}
+
+	if err := s.executeDefinition(ctx, workflow, input, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runWorkflow executes the workflow and records the execution; replayOf is set when re-running a stored execution
+func (s *Service) runWorkflow(ctx context.Context, workflowID string, input api.WorkflowExecutionInput, replayOf *openapi_types.UUID) (*api.WorkflowExecutionResult, error) {
 	// Initialize results
+	executionID := openapi_types.UUID(uuid.New())
 	result := &api.WorkflowExecutionResult{
-		ExecutedAt: time.Now(),
+		ExecutedAt: s.now(),
 		Status:     api.WorkflowExecutionResultStatusCompleted,
 		Steps:      []api.ExecutionStep{},
+		ReplayOf:   replayOf,
+		Labels:     input.Labels,
+	}
+	// Only expose an execution ID to templates when it will be returned with the result,
+	// and only collect logs for executions that are stored
+	if s.executions != nil {
+		ctx = withExecutionID(ctx, executionID)
+		ctx = withExecutionLogs(ctx)
 	}
 
 	// Get workflow using the GetWorkflow function (with caching)
 	apiWorkflow, err := s.GetWorkflow(ctx, workflowID)
 	if err != nil {
-		return nil, fmt.Errorf("workflow not found: %w", err)
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
+	ctx = withStoredWorkflow(ctx)
 
-	// Execute workflow steps
-	steps, err := s.executeWorkflowSteps(ctx, *apiWorkflow, input)
+	// Bound concurrent executions of this workflow to protect its downstream APIs
+	release, err := s.concurrency.acquire(workflowID)
 	if err != nil {
-		result.Status = api.WorkflowExecutionResultStatusFailed
-		slog.Error("Workflow execution failed", "error", err, "workflowID", workflowID)
+		return nil, err
 	}
+	defer release()
 
-	result.Steps = steps
+	if err := s.executeDefinition(ctx, *apiWorkflow, input, result); err != nil {
+		return nil, err
+	}
+
+	// Persist the execution so it can be fetched or replayed later
+	s.recordExecution(ctx, workflowID, executionID, input, result)
+
+	// Hand the final result to audit, notification and metrics hooks without waiting for them
+	s.runExecutionHooks(ctx, workflowID, result)
 
 	return result, nil
 }
 
-// executeWorkflowSteps executes all steps in the workflow
-func (s *Service) executeWorkflowSteps(ctx context.Context, workflow api.Workflow, input api.WorkflowExecutionInput) ([]api.ExecutionStep, error) {
-	steps := []api.ExecutionStep{}
+// executeDefinition validates and executes a workflow definition, filling in the result's
+// status and redacted steps. Only an invalid definition is returned as an error.
+func (s *Service) executeDefinition(ctx context.Context, workflow api.Workflow, input api.WorkflowExecutionInput, result *api.WorkflowExecutionResult) error {
+	// Reject oversized or misrouted definitions before any node runs
+	if err := s.validateWorkflow(workflow); err != nil {
+		return err
+	}
+
+	// Reject input the workflow can't run, e.g. a missing required form field, before any node runs
+	if err := validateExecutionInput(ctx, workflow, input); err != nil {
+		return err
+	}
+
+	// Execute workflow steps, drawing retry jitter from a source of this execution's own
+	ctx = withExecutionOptions(ctx, input.ExecutionOptions)
+	ctx = withJitterSource(ctx, s.newJitterSource())
+	steps, traversedEdges, err := s.executeWorkflowSteps(ctx, workflow, input)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The client went away or the request timed out; not a failure of the workflow itself
+		result.Status = api.WorkflowExecutionResultStatusCancelled
+		slog.WarnContext(ctx, "Workflow execution cancelled", "error", err, "workflowID", workflow.Id)
+	case err != nil:
+		result.Status = api.WorkflowExecutionResultStatusFailed
+		slog.ErrorContext(ctx, "Workflow execution failed", "error", err, "workflowID", workflow.Id)
+	}
+
+	// Redact sensitive values so they never leave the executor in step output
+	redactor := s.outputRedactor()
+	for i := range steps {
+		if steps[i].Output != nil {
+			redacted := redactor.Map(*steps[i].Output)
+			steps[i].Output = &redacted
+		}
+	}
+
+	result.Steps = steps
+	result.TraversedEdges = &traversedEdges
+	result.Result = workflowResult(steps)
+	return nil
+}
 
-	// Extract values from input for use in execution
-	var executeVars = make(map[string]any)
+// executeWorkflowSteps executes all steps in the workflow, returning them with the edges followed between them
+func (s *Service) executeWorkflowSteps(ctx context.Context, workflow api.Workflow, input api.WorkflowExecutionInput) ([]api.ExecutionStep, []api.TraversedEdge, error) {
+	// Seed executeVars with workflow defaults, then let form input override them
+	executeVars := NewExecutionContext(nil)
+	if workflow.Variables != nil {
+		executeVars.Merge(withoutHeaderVariables(*workflow.Variables))
+	}
 	if input.FormData != nil {
-		executeVars = *input.FormData
+		executeVars.Merge(withoutHeaderVariables(*input.FormData))
+	}
+
+	// Reserved variables are seeded last so neither defaults nor input can overwrite them
+	for key, value := range reservedVariables(ctx, workflow, s.now()) {
+		if _, exists := executeVars.Get(key); exists {
+			slog.DebugContext(ctx, "Ignoring input for reserved variable", "variable", key)
+		}
+		executeVars.Set(key, value)
 	}
 
 	// Build a map of nodes by ID for quick lookup
@@ -68,13 +193,31 @@ func (s *Service) executeWorkflowSteps(ctx context.Context, workflow api.Workflo
 		}
 	}
 
+	// Loop nodes look their body up while running
+	ctx = withWorkflowNodes(ctx, nodeMap)
+
+	// An explicit executionOrder replaces the traversal
+	if workflow.ExecutionOrder != nil {
+		return s.executeInOrder(ctx, *workflow.ExecutionOrder, nodeMap, adjacencyList, executeVars, input)
+	}
+
+	steps := []api.ExecutionStep{}
+	traversedEdges := []api.TraversedEdge{}
+
 	// Track visited nodes to avoid cycles
 	visited := make(map[string]bool)
 
-	// Execute nodes using BFS traversal from start node
+	// Execute nodes using BFS traversal from start node, bounding how broad it may grow
+	limits := s.workflowLimits()
 	queue := []string{StartNodeID}
+	enqueued := 1
 
 	for len(queue) > 0 {
+		// Stop once the caller has gone or the request deadline has passed
+		if err := ctx.Err(); err != nil {
+			return steps, traversedEdges, fmt.Errorf("execution stopped: %w", err)
+		}
+
 		currentNodeId := queue[0]
 		queue = queue[1:]
 
@@ -87,46 +230,99 @@ func (s *Service) executeWorkflowSteps(ctx context.Context, workflow api.Workflo
 		// Get the node
 		node, exists := nodeMap[currentNodeId]
 		if !exists {
-			slog.Warn("Node not found in nodeMap", "nodeId", currentNodeId)
+			slog.WarnContext(ctx, "Node not found in nodeMap", "nodeId", currentNodeId)
 			continue
 		}
 
 		// Execute the single node
 		step := s.executeSingleNode(ctx, node, executeVars, input)
-		if step.Error != nil {
-			return steps, fmt.Errorf("step error: %s,%v", step.NodeId, step.Error)
-		}
+		ensureStepOutput(&step)
 		steps = append(steps, step)
 
 		// Find next nodes to execute based on edges
-		edges := adjacencyList[currentNodeId]
-		for _, edge := range edges {
-			// For conditional nodes, check the sourceHandle
-			if node.Type == api.WorkflowNodeTypeCondition {
-				// Get conditionMet from executeVars
-				conditionMet, _ := executeVars["conditionMet"].(bool)
-
-				// Check if this edge should be followed based on condition result
-				if edge.SourceHandle != nil {
-					if (*edge.SourceHandle == "true" && conditionMet) || (*edge.SourceHandle == "false" && !conditionMet) {
-						queue = append(queue, edge.Target)
-					}
-				} else {
-					// No sourceHandle specified, follow the edge
-					queue = append(queue, edge.Target)
+		next := outgoingEdges(node, adjacencyList[currentNodeId], executeVars, step.Error != nil)
+		if currentNodeId == StartNodeID && len(adjacencyList[currentNodeId]) == 0 {
+			slog.WarnContext(ctx, "Traversal ended: start node has no outgoing edges", "nodes", len(nodeMap))
+			addStepWarning(&steps[len(steps)-1], "Traversal ended: start node has no outgoing edges")
+		}
+
+		// Stop at the first failed step unless it routes to an error path; it stays in steps so
+		// callers can see why
+		if step.Error != nil {
+			if len(next) == 0 {
+				return steps, traversedEdges, fmt.Errorf("step error: %s, %s", step.NodeId, *step.Error)
+			}
+			slog.WarnContext(ctx, "Condition failed, following its error handle", "nodeID", node.Id, "error", *step.Error)
+			addStepWarning(&steps[len(steps)-1], "Condition failed, following its error handle")
+		}
+
+		// A stop node ends the run successfully, dropping anything still queued
+		if node.Type == api.WorkflowNodeTypeStop {
+			break
+		}
+
+		for _, edge := range next {
+			enqueued++
+			if len(queue) >= limits.MaxQueued || enqueued > limits.MaxEnqueued {
+				return steps, traversedEdges, ErrTraversalTooBroad{
+					Queued:      len(queue) + 1,
+					Enqueued:    enqueued,
+					MaxQueued:   limits.MaxQueued,
+					MaxEnqueued: limits.MaxEnqueued,
 				}
-			} else {
-				// For non-conditional nodes, follow all outgoing edges
-				queue = append(queue, edge.Target)
 			}
+			queue = append(queue, edge.Target)
+			traversedEdges = append(traversedEdges, s.traversedEdge(edge, executeVars))
+		}
+	}
+
+	return steps, traversedEdges, nil
+}
+
+// outgoingEdges returns the edges execution follows out of node once it has run. A failed
+// condition follows only its edges from the error handle; any other failed node follows none.
+func outgoingEdges(node api.WorkflowNode, edges []api.WorkflowEdge, executeVars *ExecutionContext, failed bool) []api.WorkflowEdge {
+	var followed []api.WorkflowEdge
+	for _, edge := range edges {
+		if failed {
+			if node.Type == api.WorkflowNodeTypeCondition && edge.SourceHandle != nil && *edge.SourceHandle == ErrorHandle {
+				followed = append(followed, edge)
+			}
+			continue
+		}
+		if edgeFollowed(node, edge, executeVars) {
+			followed = append(followed, edge)
+		}
+	}
+	return followed
+}
+
+// edgeFollowed reports whether execution continues along one of node's outgoing edges once the
+// node has run. Most nodes follow all their edges; conditions and splits pick by sourceHandle,
+// and a condition's error handle is only followed when the condition fails.
+func edgeFollowed(node api.WorkflowNode, edge api.WorkflowEdge, executeVars *ExecutionContext) bool {
+	switch node.Type {
+	case api.WorkflowNodeTypeCondition:
+		// Check if this edge should be followed based on condition result;
+		// an edge without a sourceHandle is always followed
+		if edge.SourceHandle == nil {
+			return true
 		}
+		conditionMet, _ := executeVars.GetBool("conditionMet")
+		return (*edge.SourceHandle == "true" && conditionMet) || (*edge.SourceHandle == "false" && !conditionMet)
+
+	case api.WorkflowNodeTypeSplit:
+		// Follow only the edge matching the branch chosen by the split node
+		branch, _ := executeVars.GetString("splitBranch")
+		return edge.SourceHandle == nil || *edge.SourceHandle == branch
 	}
 
-	return steps, nil
+	return true
 }
 
 // executeSingleNode executes a single node and returns the execution step
-func (s *Service) executeSingleNode(ctx context.Context, node api.WorkflowNode, executeVars map[string]any, input api.WorkflowExecutionInput) api.ExecutionStep {
+func (s *Service) executeSingleNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, input api.WorkflowExecutionInput) api.ExecutionStep {
+	ctx, warnings := withStepWarnings(withLogNode(ctx, node.Id))
 	output := make(map[string]any)
 
 	// Get label and description from node data
@@ -149,13 +345,21 @@ func (s *Service) executeSingleNode(ctx context.Context, node api.WorkflowNode,
 		Output:      &output,
 	}
 
+	// Nodes flagged with debug log what they read and produced, whatever the log level
+	if nodeDebugEnabled(node) {
+		inputs := nodeDebugInputs(node, executeVars)
+		defer func() {
+			logNodeDebug(ctx, s.outputRedactor(), node, inputs, step)
+		}()
+	}
+
 	switch node.Type {
 	case api.WorkflowNodeTypeStart:
 		output["message"] = "Workflow started successfully"
 
 	case api.WorkflowNodeTypeForm:
 		// Execute form fields based on metadata
-		if err := s.executeFormNode(node, executeVars, output); err != nil {
+		if err := s.executeFormNode(ctx, node, executeVars, output); err != nil {
 			step.Status = api.ExecutionStepStatusFailed
 			errorMsg := err.Error()
 			step.Error = &errorMsg
@@ -165,22 +369,42 @@ func (s *Service) executeSingleNode(ctx context.Context, node api.WorkflowNode,
 		}
 
 	case api.WorkflowNodeTypeIntegration:
+		// Skip the API call when the node's skipIf rule matches; outgoing edges are still followed
+		skip, err := integrationSkipped(node, executeVars)
+		if err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output[NodeMessageKey] = "Failed to execute integration"
+			break
+		}
+		if skip {
+			step.Status = api.ExecutionStepStatusSkipped
+			output[NodeMessageKey] = "Integration skipped - skipIf matched"
+			break
+		}
+
 		// Execute integration node based on metadata
-		if err := s.executeIntegrationNode(ctx, node, executeVars, output); err != nil {
+		err = s.executeIntegrationNode(ctx, node, executeVars, output)
+		step.RawResponse = takeRawResponse(output)
+		if err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output[NodeMessageKey] = "Failed to execute integration"
+		} else if err := s.checkDuplicateOutputs(ctx, node, executeVars, output); err != nil {
 			step.Status = api.ExecutionStepStatusFailed
 			errorMsg := err.Error()
 			step.Error = &errorMsg
-			output["message"] = "Failed to execute integration"
+			output[NodeMessageKey] = "Failed to execute integration"
 		} else {
 			// Update executeVars with output values for subsequent steps
-			for k, v := range output {
-				executeVars[k] = v
-			}
+			mergeNodeOutput(executeVars, output)
 
 			// Replace placeholders in description with actual values
 			if node.Data != nil && node.Data.Description != nil {
 				updatedDesc := *node.Data.Description
-				for key, value := range executeVars {
+				for key, value := range executeVars.Snapshot() {
 					placeholder := fmt.Sprintf("{{%s}}", key)
 					updatedDesc = strings.ReplaceAll(updatedDesc, placeholder, fmt.Sprintf("%v", value))
 				}
@@ -191,355 +415,992 @@ func (s *Service) executeSingleNode(ctx context.Context, node api.WorkflowNode,
 
 	case api.WorkflowNodeTypeCondition:
 		// Execute condition node based on metadata
-		if err := s.executeConditionNode(executeVars, output, input.Condition); err != nil {
+		if err := s.executeConditionNode(ctx, node, executeVars, output, input.Condition); err != nil {
 			step.Status = api.ExecutionStepStatusFailed
 			errorMsg := err.Error()
 			step.Error = &errorMsg
-			output["message"] = "Failed to evaluate condition"
+			output[NodeMessageKey] = "Failed to evaluate condition"
 		} else {
 			// Update executeVars with output values
-			for k, v := range output {
-				executeVars[k] = v
-			}
+			mergeNodeOutput(executeVars, output)
+			executeVars.MarkConditionEvaluated()
+		}
+
+	case api.WorkflowNodeTypeSplit:
+		// Execute split node based on metadata
+		if err := s.executeSplitNode(node, executeVars, output); err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to evaluate split"
+		} else {
+			// Store the chosen branch for edge routing
+			executeVars.Set("splitBranch", output["branch"])
+		}
+
+	case api.WorkflowNodeTypeAggregate:
+		// Execute aggregation node based on metadata
+		if err := s.executeAggregateNode(node, executeVars, output); err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to aggregate values"
+		} else if err := s.checkDuplicateOutputs(ctx, node, executeVars, output); err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to aggregate values"
+		} else {
+			// Update executeVars with the aggregated value
+			mergeNodeOutput(executeVars, output)
+		}
+
+	case api.WorkflowNodeTypeLoop:
+		// Run the loop's body once per element of its array
+		if err := s.executeLoopNode(ctx, node, executeVars, input, output); err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to run loop"
+		} else if err := s.checkDuplicateOutputs(ctx, node, executeVars, output); err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to run loop"
+		} else {
+			// Update executeVars with the collected iteration outputs
+			mergeNodeOutput(executeVars, output)
 		}
 
 	case api.WorkflowNodeTypeEmail:
+		// Check the condition before anything is handed to the sender, unless no condition
+		// ran in this run or the node has a subjectNotMet variant to send when it fails
+		conditionMet, _ := executeVars.GetBool("conditionMet")
+		if executeVars.ConditionEvaluated() && !conditionMet && !hasNotMetSubject(node, executeVars) {
+			step.Status = api.ExecutionStepStatusSkipped
+			output["emailSent"] = false
+			output[NodeMessageKey] = "Email alert skipped - condition not met"
+			break
+		}
+
 		// Execute email node based on metadata
-		if err := s.executeEmailNode(node, executeVars, output); err != nil {
+		if err := s.executeEmailNode(ctx, node, executeVars, output); err != nil {
 			step.Status = api.ExecutionStepStatusFailed
 			errorMsg := err.Error()
 			step.Error = &errorMsg
-			output["message"] = "Failed to execute email"
+			output[NodeMessageKey] = "Failed to execute email"
 		} else {
-			// Check if email should be sent based on condition
-			conditionMet, _ := executeVars["conditionMet"].(bool)
-			if !conditionMet {
-				step.Status = api.ExecutionStepStatusSkipped
-				output["message"] = "Email alert skipped - condition not met"
-			}
+			output[NodeMessageKey] = emailNodeMessage(output)
 		}
 
 	case api.WorkflowNodeTypeEnd:
+		// Project the workflow's return value from the variables named by resultVariables
+		result, err := endNodeResult(ctx, node, executeVars)
+		if err != nil {
+			step.Status = api.ExecutionStepStatusFailed
+			errorMsg := err.Error()
+			step.Error = &errorMsg
+			output["message"] = "Failed to build workflow result"
+			break
+		}
+		if result != nil {
+			output["result"] = result
+		}
 		output["message"] = "Workflow completed successfully"
+
+	case api.WorkflowNodeTypeStop:
+		output["message"] = "Workflow stopped early"
+
+	default:
+		// Annotation nodes only document the workflow and pass straight through
+		if isAnnotationNode(node.Type) {
+			output["message"] = "note"
+			break
+		}
+
+		// Fail loudly so a typo in the node type doesn't silently do nothing
+		step.Status = api.ExecutionStepStatusFailed
+		errorMsg := fmt.Sprintf("unknown node type: %s", node.Type)
+		step.Error = &errorMsg
+		output["message"] = "Unknown node type"
+	}
+
+	// Return soft problems the node ran into with its step
+	if messages := warnings.list(); len(messages) > 0 {
+		step.Warnings = &messages
 	}
 
 	return step
 }
 
 // executeIntegrationNode executes integration node based on its metadata configuration
-func (s *Service) executeIntegrationNode(ctx context.Context, node api.WorkflowNode, executeVars map[string]any, output map[string]any) error {
+func (s *Service) executeIntegrationNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
 	// Check if node has metadata
 	if node.Data == nil || node.Data.Metadata == nil {
 		return fmt.Errorf("integration node missing metadata")
 	}
 
-	metadata := *node.Data.Metadata
+	metadata := nodeMetadata(node)
 
 	// Get inputVariables from metadata
-	inputVariables, hasInputVars := metadata["inputVariables"]
-	if !hasInputVars {
+	if !metadata.Has("inputVariables") {
 		return fmt.Errorf("integration node missing inputVariables in metadata")
 	}
-
-	inputVarsList, ok := inputVariables.([]any)
-	if !ok {
-		return fmt.Errorf("inputVariables must be an array")
+	inputVarsList, err := metadata.StringSlice("inputVariables")
+	if err != nil {
+		return err
 	}
 
 	// Check that all required input variables exist in executeVars
 	inputValues := make(map[string]any)
 	for _, varName := range inputVarsList {
-		varNameStr, ok := varName.(string)
-		if !ok {
-			continue
+		value, exists := executeVars.Get(varName)
+		if !exists {
+			return fmt.Errorf("required input variable '%s' not found in executeVars", varName)
 		}
+		inputValues[varName] = value
+	}
 
-		value, exists := executeVars[varNameStr]
-		if !exists {
-			return fmt.Errorf("required input variable '%s' not found in executeVars", varNameStr)
+	// Get options from metadata to find matching configuration. Without options the input
+	// values themselves fill the endpoint placeholders, e.g. coordinates from a geocoding node.
+	optionless := !metadata.Has("options")
+	var matchedOptions []map[string]any
+	if !optionless {
+		optionsList, err := metadata.Slice("options")
+		if err != nil {
+			return err
+		}
+
+		// Get whether option strings match input regardless of case
+		caseInsensitive, err := metadata.Bool("caseInsensitiveMatch")
+		if err != nil {
+			return err
+		}
+
+		// Find the options matching the input values; only the first is called unless fanning out
+		matchedOptions = matchingOptions(optionsList, inputValues, caseInsensitive)
+		if len(matchedOptions) == 0 {
+			return fmt.Errorf("no matching option found for input values")
 		}
-		inputValues[varNameStr] = value
 	}
 
-	// Get options from metadata to find matching configuration
-	options, hasOptions := metadata["options"]
-	if !hasOptions {
-		return fmt.Errorf("integration node missing options in metadata")
+	// Get the optional fan-out configuration
+	fanOut, err := parseFanOutSettings(metadata)
+	if err != nil {
+		return err
+	}
+	if fanOut != nil && optionless {
+		return fmt.Errorf("fanOut requires options")
 	}
 
-	optionsList, ok := options.([]any)
-	if !ok {
-		return fmt.Errorf("options must be an array")
+	// Get API endpoint templates from metadata
+	endpoints, strategy, err := parseIntegrationEndpoints(metadata)
+	if err != nil {
+		return err
 	}
+	multipleEndpoints := len(endpoints) > 1
 
-	// Find the matching option based on input values
-	var selectedOption map[string]any
-	for _, opt := range optionsList {
-		option, ok := opt.(map[string]any)
-		if !ok {
-			continue
-		}
+	// An execution can point the node at another backend, e.g. a staging API
+	if override, exists := endpointOverride(ctx, node.Id); exists {
+		slog.DebugContext(ctx, "Using endpoint override", "nodeID", node.Id)
+		endpoints = []weightedEndpoint{{url: override, weight: 1}}
+	}
 
-		// Check if this option matches our input values
-		matches := true
-		for key, value := range inputValues {
-			if optValue, exists := option[key]; !exists || optValue != value {
-				matches = false
-				break
-			}
-		}
+	// Replace placeholders in each API endpoint, in the order the strategy tries them
+	workflowID, _ := executeVars.GetString(ReservedVarWorkflowID)
+	apiTemplates := s.endpoints.order(workflowID+"/"+node.Id, endpoints, strategy)
+	var apiURLs []string
+	if optionless {
+		apiURLs = fillEndpoints(apiTemplates, inputValues, executeVars, true)
+	} else if apiURLs, err = s.optionURLs(ctx, apiTemplates, matchedOptions[0], executeVars); err != nil {
+		return err
+	}
 
-		if matches {
-			selectedOption = option
-			break
-		}
+	// Get the optional variable that receives the whole decoded response
+	responseVar, err := metadata.NonEmptyString("responseVar")
+	if err != nil {
+		return err
+	}
+	if isReservedVariable(responseVar) {
+		return fmt.Errorf("responseVar '%s' is reserved", responseVar)
 	}
 
-	if selectedOption == nil {
-		return fmt.Errorf("no matching option found for input values")
+	// Get the optional variable that receives a bare number, string or boolean response
+	scalarOutputVar, err := metadata.NonEmptyString("scalarOutputVar")
+	if err != nil {
+		return err
+	}
+	if isReservedVariable(scalarOutputVar) {
+		return fmt.Errorf("scalarOutputVar '%s' is reserved", scalarOutputVar)
 	}
 
-	// Get API endpoint template from metadata
-	apiEndpoint, hasEndpoint := metadata["apiEndpoint"]
-	if !hasEndpoint {
-		return fmt.Errorf("integration node missing apiEndpoint in metadata")
+	// Get the optional streaming configuration
+	stream, err := parseStreamSettings(metadata)
+	if err != nil {
+		return err
 	}
 
-	apiEndpointStr, ok := apiEndpoint.(string)
-	if !ok {
-		return fmt.Errorf("apiEndpoint must be a string")
+	// Get whether the raw response body is kept with the execution for audit
+	persistResponse, err := metadata.Bool("persistResponse")
+	if err != nil {
+		return err
+	}
+	if persistResponse && stream != nil {
+		return fmt.Errorf("persistResponse can't be used with streamMode")
 	}
 
-	// Replace placeholders in API endpoint with values from selectedOption
-	apiURL := apiEndpointStr
-	for key, value := range selectedOption {
-		placeholder := fmt.Sprintf("{%s}", key)
-		apiURL = strings.ReplaceAll(apiURL, placeholder, fmt.Sprintf("%v", value))
+	// Get outputVariables and the optional types they must have
+	outputVarsList, err := metadata.StringSlice("outputVariables")
+	if err != nil {
+		return err
+	}
+	outputTypes, err := parseOutputTypes(metadata)
+	if err != nil {
+		return err
 	}
 
-	// Make HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	// Get the API call timeout
+	timeout, err := integrationTimeout(ctx, metadata)
 	if err != nil {
-		slog.Error("Failed to create request", "error", err, "url", apiURL)
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Apply request headers, resolving {{secret.NAME}} and {{env.NAME}} references at request time, then
+	// {{variable}} references. Resolved values are never logged.
+	header := http.Header{}
+	headersMap, err := metadata.Object("headers")
 	if err != nil {
-		slog.Error("Failed to call API", "error", err, "url", apiURL)
-		return fmt.Errorf("failed to call API: %w", err)
+		return err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Warn("Failed to close response body", "error", err)
+	var headerVars map[string]any
+	for name, value := range headersMap {
+		valueStr, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("header '%s' must be a string", name)
 		}
-	}()
+		resolved, err := s.resolveSecrets(ctx, valueStr)
+		if err != nil {
+			return fmt.Errorf("failed to resolve header '%s': %w", name, err)
+		}
+		if strings.Contains(resolved, "{{") {
+			if headerVars == nil {
+				headerVars = executeVars.Snapshot()
+			}
+			resolved = renderTemplate(resolved, headerVars)
+		}
+		header.Set(name, resolved)
+	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Send the optional idempotency key so the API can recognise a repeated call
+	idempotency, err := parseIdempotencySettings(metadata, executeVars)
 	if err != nil {
-		slog.Error("Failed to read API response", "error", err)
-		return fmt.Errorf("failed to read API response: %w", err)
+		return err
+	}
+	if idempotency != nil {
+		// Every fan-out call would share the key, so the API would only honour the first
+		if fanOut != nil {
+			return fmt.Errorf("idempotencyKey can't be used with fanOut")
+		}
+		header.Set(IdempotencyKeyHeader, idempotency.key)
 	}
 
-	// Check HTTP status code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		slog.Error("API returned non-2xx status code",
-			"status", resp.StatusCode,
-			"url", apiURL,
-			"body", string(body))
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	// Call the endpoint once per matching option and collect the results
+	if fanOut != nil {
+		requestURLs := make([][]string, len(matchedOptions))
+		for i, option := range matchedOptions {
+			if requestURLs[i], err = s.optionURLs(ctx, apiTemplates, option, executeVars); err != nil {
+				return err
+			}
+		}
+		if isDryRun(ctx) {
+			return dryRunFanOut(ctx, requestURLs, output)
+		}
+		client := &http.Client{Timeout: timeout}
+		return s.executeIntegrationFanOut(ctx, fanOut, matchedOptions, requestURLs, client, header, outputVarsList, outputTypes, output)
 	}
 
-	// Parse JSON response with proper number handling
-	var responseData any
-	decoder := json.NewDecoder(strings.NewReader(string(body)))
-	decoder.UseNumber() // This ensures numbers are preserved properly
-	if err := decoder.Decode(&responseData); err != nil {
-		slog.Error("Failed to parse API response", "error", err, "body", string(body))
-		return fmt.Errorf("failed to parse API response: %w", err)
+	// Dry runs report the request that would be made first without calling the API
+	if isDryRun(ctx) {
+		if _, err := http.NewRequestWithContext(ctx, "GET", apiURLs[0], nil); err != nil {
+			slog.ErrorContext(ctx, "Failed to create request", "error", err, "url", apiURLs[0])
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		request := map[string]any{"method": "GET", "url": apiURLs[0]}
+		if idempotency != nil {
+			request["idempotencyKey"] = idempotency.key
+		}
+		output["request"] = request
+		output[NodeMessageKey] = fmt.Sprintf("Dry run: GET %s not sent", apiURLs[0])
+		return nil
 	}
 
-	// Convert to map if it's a map
-	responseMap, ok := responseData.(map[string]any)
-	if !ok {
-		return fmt.Errorf("API response is not a JSON object")
+	// Answer a call already made with the same idempotency key from the cache
+	var dedupKey string
+	if idempotency != nil && idempotency.dedupTTL > 0 {
+		// An unsaved definition chooses its own workflow ID, so it could read or overwrite a stored workflow's entries
+		if isStoredWorkflow(ctx) {
+			dedupKey = dedupCacheKey(workflowID, node.Id, idempotency.key)
+			if s.replayDeduplicated(ctx, dedupKey, output) {
+				return nil
+			}
+		} else {
+			warnStep(ctx, "Unsaved workflow, idempotent call not deduplicated")
+		}
 	}
 
-	// Log the response for debugging
-	slog.Debug("API response received", "url", apiURL, "response", responseMap)
+	// Make HTTP request with context, moving on to the next endpoint when one fails
+	client := &http.Client{Timeout: timeout}
+	resp, apiURL, attempts, err := callEndpoints(ctx, client, apiURLs, header)
+	if multipleEndpoints {
+		output["endpoint"] = apiURL
+		output["endpointAttempts"] = attempts
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.WarnContext(ctx, "Failed to close response body", "error", err)
+		}
+	}()
 
-	// Get outputVariables from metadata
-	outputVariables, hasOutputVars := metadata["outputVariables"]
-	if hasOutputVars {
-		outputVarsList, ok := outputVariables.([]any)
-		if ok {
-			// Extract specified output variables from response using recursive search
-			for _, varName := range outputVarsList {
-				varNameStr, ok := varName.(string)
-				if !ok {
-					continue
-				}
+	var responseMap map[string]any
+	if stream != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		// Decode straight off the wire so a large document is never held in memory
+		responseMap, err = streamIntegrationResponse(resp.Body, stream, metadata, output)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to stream API response", "error", err, "url", apiURL)
+			return fmt.Errorf("failed to stream API response: %w", err)
+		}
+		slog.DebugContext(ctx, "API response streamed", "url", apiURL, "mode", stream.mode)
+	} else {
+		// Read response body
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to read API response", "error", err)
+			return fmt.Errorf("failed to read API response: %w", err)
+		}
+
+		// Keep the body for the step's rawResponse, failed calls included
+		if persistResponse {
+			if raw, ok := s.persistableBody(body); ok {
+				output[rawResponseOutputKey] = raw
+			} else {
+				warnStep(ctx, "API response too large to persist", "nodeID", node.Id, "bytes", len(body), "maxBytes", maxPersistedResponseBytes)
+			}
+		}
+
+		// Check HTTP status code
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			slog.ErrorContext(ctx, "API returned non-2xx status code",
+				"status", resp.StatusCode,
+				"url", apiURL,
+				"body", s.redactBody(body))
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, s.redactBody(body))
+		}
+
+		// Parse JSON response with proper number handling
+		var responseData any
+		decoder := json.NewDecoder(strings.NewReader(string(body)))
+		decoder.UseNumber() // This ensures numbers are preserved properly
+		if err := decoder.Decode(&responseData); err != nil {
+			slog.ErrorContext(ctx, "Failed to parse API response", "error", err, "body", s.redactBody(body))
+			return fmt.Errorf("failed to parse API response: %w", err)
+		}
+
+		// Store the whole response when requested; arrays are only supported this way
+		if responseVar != "" {
+			output[responseVar] = responseData
+		}
 
-				// Search for the variable in the response (up to 2 levels deep)
-				if value := findValueInMap(responseMap, varNameStr, 0, 2); value != nil {
-					output[varNameStr] = value
-					slog.Debug("Found output variable", "variable", varNameStr, "value", value)
-				} else {
-					slog.Debug("Output variable not found in response", "variable", varNameStr)
+		// Store a scalar response whole; numbers stay json.Number so no precision is lost
+		scalar := scalarOutputVar != "" && isScalarResponse(responseData)
+		if scalar {
+			value := responseData
+			if expected, declared := outputTypes[scalarOutputVar]; declared {
+				coerced, err := coerceOutputValue(value, expected)
+				if err != nil {
+					return fmt.Errorf("output variable '%s': %w", scalarOutputVar, err)
 				}
+				value = coerced
 			}
+			output[scalarOutputVar] = value
 		}
+
+		// Convert to map if it's a map
+		var ok bool
+		responseMap, ok = responseData.(map[string]any)
+		if !ok {
+			if responseVar == "" && !scalar {
+				return fmt.Errorf("API response is not a JSON object")
+			}
+			responseMap = map[string]any{}
+		}
+
+		// Log the response for debugging
+		slog.DebugContext(ctx, "API response received", "url", apiURL, "response", responseData)
+	}
+
+	// Extract specified output variables from response using recursive search
+	if err := extractOutputVariables(ctx, responseMap, outputVarsList, outputTypes, output); err != nil {
+		return err
 	}
 
 	// Add a success message if we got temperature
-	if temp, ok := output["temperature"].(float64); ok {
+	if temp, ok := toFloat64(output["temperature"]); ok {
 		if city, ok := inputValues["city"].(string); ok {
-			output["message"] = fmt.Sprintf("Weather data fetched for %s: %.1f°C", city, temp)
+			output[NodeMessageKey] = fmt.Sprintf("Weather data fetched for %s: %.1f°C", city, temp)
 		}
 	}
 
 	// Copy input values to output if they're also listed in outputVariables
 	// This handles cases where we want to pass through input values
-	if outputVarsList, ok := outputVariables.([]any); ok {
-		for _, varName := range outputVarsList {
-			varNameStr, ok := varName.(string)
-			if !ok {
-				continue
+	for _, varName := range outputVarsList {
+		// If not already in output and exists in input, copy it
+		if _, exists := output[varName]; !exists {
+			if value, exists := inputValues[varName]; exists {
+				output[varName] = value
 			}
-			// If not already in output and exists in input, copy it
-			if _, exists := output[varNameStr]; !exists {
-				if value, exists := inputValues[varNameStr]; exists {
-					output[varNameStr] = value
-				}
+		}
+	}
+
+	// Keep the output so repeats of this call within the TTL aren't sent again
+	if dedupKey != "" {
+		declared := append([]string{responseVar, scalarOutputVar}, outputVarsList...)
+		s.storeDeduplicated(ctx, dedupKey, output, declared, idempotency.dedupTTL)
+	}
+
+	return nil
+}
+
+// matchingOptions returns the options whose values match every input value, in order
+func matchingOptions(optionsList []any, inputValues map[string]any, caseInsensitive bool) []map[string]any {
+	var matched []map[string]any
+	for _, opt := range optionsList {
+		option, ok := opt.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// Check if this option matches our input values
+		matches := true
+		for key, value := range inputValues {
+			if optValue, exists := option[key]; !exists || !optionValueMatches(optValue, value, caseInsensitive) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			matched = append(matched, option)
+		}
+	}
+	return matched
+}
+
+// optionURLs fills the placeholders of each API endpoint template with an option's values.
+// String values may reference {{env.NAME}} and {{variable}}; they are resolved here rather
+// than before matching, which compares the raw values against the input.
+func (s *Service) optionURLs(ctx context.Context, apiTemplates []string, option map[string]any, executeVars *ExecutionContext) ([]string, error) {
+	values, err := s.resolveOptionValues(ctx, option, executeVars)
+	if err != nil {
+		return nil, err
+	}
+
+	return fillEndpoints(apiTemplates, values, executeVars, false), nil
+}
+
+// fillEndpoints renders the {{variable}} references of each API endpoint template from
+// executeVars, then fills its {key} placeholders with values. Input values are substituted
+// as they are, so a variable spelling out a secret reference is never resolved. Variables are
+// always URL-encoded, as they may hold form input that must not change the URL's shape. With
+// escapeValues set the placeholder values are encoded too; options leave it unset, as their
+// values are written by the workflow's author.
+func fillEndpoints(apiTemplates []string, values map[string]any, executeVars *ExecutionContext, escapeValues bool) []string {
+	if escapeValues {
+		values = escapeURLValues(values)
+	}
+
+	var vars map[string]any
+	apiURLs := make([]string, len(apiTemplates))
+	for i, apiURL := range apiTemplates {
+		// {{key}} contains {key}, so variables are rendered before the placeholders
+		if strings.Contains(apiURL, "{{") {
+			if vars == nil {
+				vars = escapeURLValues(executeVars.Snapshot())
 			}
+			apiURL = renderTemplate(apiURL, vars)
 		}
+		for key, value := range values {
+			placeholder := fmt.Sprintf("{%s}", key)
+			apiURL = strings.ReplaceAll(apiURL, placeholder, fmt.Sprintf("%v", value))
+		}
+		apiURLs[i] = apiURL
 	}
+	return apiURLs
+}
+
+// escapeURLValues returns a copy of values with each value formatted and URL-encoded. Spaces
+// become %20 rather than +, so a value is encoded correctly in the path as well as the query.
+func escapeURLValues(values map[string]any) map[string]any {
+	escaped := make(map[string]any, len(values))
+	for key, value := range values {
+		escaped[key] = strings.ReplaceAll(url.QueryEscape(fmt.Sprintf("%v", value)), "+", "%20")
+	}
+	return escaped
+}
 
+// resolveOptionValues returns a copy of option with the references in its string values
+// resolved. Env references are resolved first, so a variable whose value spells out one is
+// substituted as written. Variables are URL-encoded, so form input can't change the URL's
+// shape through an option. Unknown variables are left in place, as in other templates.
+// Secret references are rejected: the resolved URL is logged, returned in dry runs and
+// stored with the step, so a secret there would leak. Secrets belong in headers.
+func (s *Service) resolveOptionValues(ctx context.Context, option map[string]any, executeVars *ExecutionContext) (map[string]any, error) {
+	var vars map[string]any
+	values := make(map[string]any, len(option))
+	for key, value := range option {
+		template, ok := value.(string)
+		if !ok || !strings.Contains(template, "{{") {
+			values[key] = value
+			continue
+		}
+
+		if referencesSecret(template) {
+			return nil, fmt.Errorf("option value '%s' can't reference secrets, use headers instead", key)
+		}
+		resolved, err := s.resolveSecrets(ctx, template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve option value '%s': %w", key, err)
+		}
+		if vars == nil {
+			vars = escapeURLValues(executeVars.Snapshot())
+		}
+		values[key] = renderTemplate(resolved, vars)
+	}
+	return values, nil
+}
+
+// extractOutputVariables copies each output variable found in the response, searching up to
+// 2 levels deep, into output after checking it against its declared type
+func extractOutputVariables(ctx context.Context, responseMap map[string]any, outputVarsList []string, outputTypes map[string]string, output map[string]any) error {
+	for _, varName := range outputVarsList {
+		if value := findValueInMap(responseMap, varName, 0, 2); value != nil {
+			// Catch API contract changes here rather than in a later node
+			if expected, declared := outputTypes[varName]; declared {
+				coerced, err := coerceOutputValue(value, expected)
+				if err != nil {
+					return fmt.Errorf("output variable '%s': %w", varName, err)
+				}
+				value = coerced
+			}
+			output[varName] = value
+			slog.DebugContext(ctx, "Found output variable", "variable", varName, "value", value)
+		} else {
+			warnStep(ctx, "Output variable not found in response", "variable", varName)
+		}
+	}
 	return nil
 }
 
 // executeConditionNode executes condition node based on its metadata and executeVars
-func (s *Service) executeConditionNode(executeVars map[string]any, output map[string]any, condition *api.Condition) error {
-	// Check if condition configuration is provided
-	if condition == nil {
-		return fmt.Errorf("condition configuration is missing")
+func (s *Service) executeConditionNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any, condition *api.Condition) error {
+	// The execution input's condition wins; without one the node's own metadata applies,
+	// and an input condition without an operator takes the node's default operator
+	switch {
+	case condition == nil:
+		fallback, err := conditionFromMetadata(node)
+		if err != nil {
+			return err
+		}
+		if fallback == nil {
+			return fmt.Errorf("condition configuration is missing")
+		}
+		condition = fallback
+	case condition.Operator == "":
+		merged, err := withDefaultOperator(node, *condition)
+		if err != nil {
+			return err
+		}
+		condition = merged
 	}
 
-	// Get the value to evaluate (e.g., temperature) from executeVars
-	// This should be configurable in metadata, but for now we'll use temperature
-	temperature, ok := executeVars["temperature"].(float64)
-	if !ok {
-		return fmt.Errorf("temperature not found in executeVars or invalid type")
+	// An unknown operator fails the step rather than being evaluated as greater_than
+	if _, err := parseConditionOperator(string(condition.Operator)); err != nil {
+		return err
 	}
 
-	// Evaluate the condition
-	conditionMet := evaluateCondition(temperature, string(condition.Operator), float64(condition.Threshold))
+	// Field, tolerance for equals/not_equals, message template, how long to wait for the
+	// field to arrive and how numbers are compared, configurable via metadata
+	field := DefaultConditionField
+	var awaitField time.Duration
+	epsilon := DefaultConditionEpsilon
+	messageTemplate := DefaultConditionMessage
+	if condition.Operator == api.Truthy {
+		messageTemplate = DefaultTruthyConditionMessage
+	}
+	numericMode := NumericModeFloat
+	metadata := nodeMetadata(node)
+	configuredField, err := metadata.NonEmptyString("field")
+	if err != nil {
+		return err
+	}
+	if configuredField != "" {
+		field = configuredField
+	}
+	if rawAwait, exists := metadata["awaitFieldMs"]; exists {
+		awaitMs, ok := toFloat64(rawAwait)
+		if !ok || awaitMs < 0 {
+			return fmt.Errorf("awaitFieldMs must be a non-negative number")
+		}
+		if awaitMs > maxAwaitFieldMs {
+			return fmt.Errorf("awaitFieldMs must not exceed %d", maxAwaitFieldMs)
+		}
+		awaitField = time.Duration(awaitMs) * time.Millisecond
+	}
+	if rawEpsilon, exists := metadata["epsilon"]; exists {
+		value, ok := toFloat64(rawEpsilon)
+		if !ok || value < 0 {
+			return fmt.Errorf("epsilon must be a non-negative number")
+		}
+		epsilon = value
+	}
+	if metadata.Has("messageTemplate") {
+		template, ok := metadata.String("messageTemplate")
+		if !ok {
+			return fmt.Errorf("messageTemplate must be a string")
+		}
+		messageTemplate = template
+	}
+	if metadata.Has("numericMode") {
+		mode, _ := metadata.String("numericMode")
+		if mode != NumericModeFloat && mode != NumericModeDecimal {
+			return fmt.Errorf("numericMode must be one of float or decimal")
+		}
+		numericMode = mode
+	}
+	if metadata.Has("epsilon") && numericMode == NumericModeDecimal {
+		return fmt.Errorf("epsilon can't be used with numericMode decimal")
+	}
+
+	// Get the value to evaluate (e.g., temperature) from executeVars.
+	// Dot-paths reach into objects stored by an integration's responseVar.
+	rawValue, _ := awaitConditionField(ctx, executeVars, field, awaitField)
+	slog.DebugContext(ctx, "Evaluating condition value", "key", field, "type", fmt.Sprintf("%T", rawValue))
+
+	// Evaluate the condition. Decimal mode compares exactly and reports both sides as decimal
+	// strings, so an amount of 19.99 equals a threshold of 19.99 even though the float32
+	// threshold and float64 value differ.
+	var actualValue, threshold any
+	var conditionMet bool
+	comparison := ComparisonNumeric
+	if condition.Operator == api.Truthy {
+		// Truthy branches on the field itself; an absent field is false rather than an error
+		conditionMet = isTruthy(rawValue)
+		actualValue = rawValue
+		comparison = ComparisonBoolean
+	} else if numericMode == NumericModeDecimal {
+		value, valueText, ok := toDecimal(rawValue)
+		if !ok {
+			return fmt.Errorf("%s not found in executeVars or invalid type", field)
+		}
+		thresholdValue, thresholdText, ok := toDecimal(condition.Threshold)
+		if !ok {
+			return fmt.Errorf("threshold is not a valid decimal")
+		}
+		conditionMet = evaluateDecimalCondition(ctx, value, string(condition.Operator), thresholdValue)
+		actualValue, threshold = valueText, thresholdText
+	} else {
+		value, ok := toFloat64(rawValue)
+		if !ok {
+			return fmt.Errorf("%s not found in executeVars or invalid type", field)
+		}
+		// Compare against the threshold as reported, so output and message never contradict conditionMet.
+		// Both sides are reported as float64 so clients decode them to the same type.
+		thresholdValue := thresholdFloat64(condition.Threshold)
+		conditionMet = evaluateCondition(ctx, value, string(condition.Operator), thresholdValue, epsilon)
+		actualValue, threshold = value, thresholdValue
+	}
 
 	// Store results in output
 	output["conditionMet"] = conditionMet
-	output["threshold"] = condition.Threshold
+	output["threshold"] = threshold
 	output["operator"] = string(condition.Operator)
-	output["actualValue"] = temperature
-	output["message"] = fmt.Sprintf("Temperature %.1f°C is %s %.1f°C - condition %s",
-		temperature, condition.Operator, condition.Threshold,
-		map[bool]string{true: "met", false: "not met"}[conditionMet])
+	output["actualValue"] = actualValue
+	// actualValue may be a number, a decimal string or, for truthy, any value; say which
+	output["valueType"] = valueType(actualValue)
+	output["comparison"] = comparison
+	output[NodeMessageKey] = renderTemplate(messageTemplate, map[string]any{
+		"actualValue":  actualValue,
+		"operator":     condition.Operator,
+		"threshold":    threshold,
+		"conditionMet": conditionMet,
+	})
 
 	return nil
 }
 
-// executeEmailNode executes email node based on its metadata configuration
-func (s *Service) executeEmailNode(node api.WorkflowNode, executeVars map[string]any, output map[string]any) error {
+// executeSplitNode routes a deterministic percentage of executions to the variant branch.
+// The same key value (e.g. an email) always hashes to the same bucket, so a user sees a stable branch.
+func (s *Service) executeSplitNode(node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
 	// Check if node has metadata
 	if node.Data == nil || node.Data.Metadata == nil {
-		return fmt.Errorf("email node missing metadata")
+		return fmt.Errorf("split node missing metadata")
 	}
 
 	metadata := *node.Data.Metadata
 
-	// Get inputVariables from metadata
-	inputVariables, hasInputVars := metadata["inputVariables"]
-	var inputValues map[string]any
-
-	if hasInputVars {
-		inputVarsList, ok := inputVariables.([]any)
-		if ok {
-			inputValues = make(map[string]any)
-			for _, varName := range inputVarsList {
-				varNameStr, ok := varName.(string)
-				if !ok {
-					continue
-				}
+	// Get the variable whose value is hashed
+	key, ok := metadata["key"].(string)
+	if !ok || key == "" {
+		return fmt.Errorf("split node missing key in metadata")
+	}
 
-				// Get value from executeVars
-				if value, exists := executeVars[varNameStr]; exists {
-					inputValues[varNameStr] = value
-				} else {
-					slog.Debug("Input variable not found in executeVars", "variable", varNameStr)
-				}
+	value, exists := executeVars.Get(key)
+	if !exists {
+		return fmt.Errorf("split key '%s' not found in executeVars", key)
+	}
+
+	// Get percentage routed to the variant branch
+	percentage, ok := toFloat64(metadata["percentage"])
+	if !ok {
+		return fmt.Errorf("split node percentage must be a number")
+	}
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("split node percentage must be between 0 and 100")
+	}
+
+	// Handles default to "variant" and "control"
+	variantHandle, _ := metadata["variantHandle"].(string)
+	if variantHandle == "" {
+		variantHandle = "variant"
+	}
+	controlHandle, _ := metadata["controlHandle"].(string)
+	if controlHandle == "" {
+		controlHandle = "control"
+	}
+
+	// Salt the hash with the node ID so separate experiments bucket independently
+	bucket := splitBucket(node.Id, fmt.Sprintf("%v", value))
+	branch := controlHandle
+	if float64(bucket) < percentage {
+		branch = variantHandle
+	}
+
+	output["branch"] = branch
+	output["bucket"] = bucket
+	output["message"] = fmt.Sprintf("Routed to %s (bucket %d, %.1f%% variant)", branch, bucket, percentage)
+
+	return nil
+}
+
+// executeAggregateNode combines several numeric variables with avg/min/max/sum
+// and stores the result in the variable named by outputVariable
+func (s *Service) executeAggregateNode(node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
+	// Check if node has metadata
+	if node.Data == nil || node.Data.Metadata == nil {
+		return fmt.Errorf("aggregate node missing metadata")
+	}
+
+	metadata := *node.Data.Metadata
+
+	// Get the variables to combine
+	inputVarsList, ok := metadata["inputVariables"].([]any)
+	if !ok || len(inputVarsList) == 0 {
+		return fmt.Errorf("aggregate node inputVariables must be a non-empty array")
+	}
+
+	values := make([]float64, 0, len(inputVarsList))
+	for _, varName := range inputVarsList {
+		varNameStr, ok := varName.(string)
+		if !ok || varNameStr == "" {
+			return fmt.Errorf("aggregate node inputVariables must contain variable names")
+		}
+
+		rawValue, exists := executeVars.Get(varNameStr)
+		if !exists {
+			return fmt.Errorf("aggregate variable '%s' not found in executeVars", varNameStr)
+		}
+		value, ok := toFloat64(rawValue)
+		if !ok {
+			return fmt.Errorf("aggregate variable '%s' is not numeric: %v", varNameStr, rawValue)
+		}
+		values = append(values, value)
+	}
+
+	// Get the variable that receives the result
+	outputVariable, ok := metadata["outputVariable"].(string)
+	if !ok || outputVariable == "" {
+		return fmt.Errorf("aggregate node outputVariable must be a non-empty string")
+	}
+	if isReservedVariable(outputVariable) {
+		return fmt.Errorf("aggregate node outputVariable '%s' is reserved", outputVariable)
+	}
+
+	// Optional weights turn avg into a weighted average
+	var weights []float64
+	if rawWeights, exists := metadata["weights"]; exists {
+		weightsList, ok := rawWeights.([]any)
+		if !ok || len(weightsList) != len(values) {
+			return fmt.Errorf("aggregate node weights must have one entry per input variable")
+		}
+		for _, rawWeight := range weightsList {
+			weight, ok := toFloat64(rawWeight)
+			if !ok || weight < 0 {
+				return fmt.Errorf("aggregate node weights must be non-negative numbers")
 			}
+			weights = append(weights, weight)
 		}
 	}
 
-	// Get email template from metadata
-	emailTemplate, hasTemplate := metadata["emailTemplate"]
-	if !hasTemplate {
-		return fmt.Errorf("email node missing emailTemplate in metadata")
+	operation, _ := metadata["operation"].(string)
+	result, err := aggregateValues(operation, values, weights)
+	if err != nil {
+		return err
 	}
 
-	templateMap, ok := emailTemplate.(map[string]any)
-	if !ok {
-		return fmt.Errorf("emailTemplate must be an object")
+	output[outputVariable] = result
+	output["message"] = fmt.Sprintf("Computed %s of %d values: %g", operation, len(values), result)
+
+	return nil
+}
+
+// executeEmailNode executes email node based on its metadata configuration
+func (s *Service) executeEmailNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
+	// Check if node has metadata
+	if node.Data == nil || node.Data.Metadata == nil {
+		return fmt.Errorf("email node missing metadata")
 	}
 
-	// Execute email template - replace placeholders with values
-	subject, _ := templateMap["subject"].(string)
-	body, _ := templateMap["body"].(string)
+	metadata := nodeMetadata(node)
+
+	// Get inputVariables from metadata
+	inputVarsList, err := metadata.StringSlice("inputVariables")
+	if err != nil {
+		return err
+	}
+	inputValues := make(map[string]any, len(inputVarsList))
+	for _, varName := range inputVarsList {
+		// Get value from executeVars
+		if value, exists := executeVars.Get(varName); exists {
+			inputValues[varName] = value
+		} else {
+			slog.DebugContext(ctx, "Input variable not found in executeVars", "variable", varName)
+		}
+	}
 
-	// Replace placeholders in subject and body
-	for key, value := range executeVars {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-		subject = strings.ReplaceAll(subject, placeholder, fmt.Sprintf("%v", value))
-		body = strings.ReplaceAll(body, placeholder, fmt.Sprintf("%v", value))
+	// Get outputVariables from metadata
+	outputVarsList, err := metadata.StringSlice("outputVariables")
+	if err != nil {
+		return err
 	}
 
-	// Get recipient email
-	email := ""
-	if emailValue, exists := executeVars["email"]; exists {
-		email, _ = emailValue.(string)
+	// Get email template from metadata, picking one of several by the selector variable
+	templateMap, templateKey, err := selectEmailTemplate(metadata, executeVars)
+	if err != nil {
+		return err
+	}
+	if templateKey != "" {
+		output["template"] = templateKey
 	}
 
-	// Build email draft
-	output["emailDraft"] = map[string]any{
-		"to":        email,
-		"from":      "weather-alerts@example.com", // This could also come from metadata
-		"subject":   subject,
-		"body":      body,
-		"timestamp": time.Now().Format(time.RFC3339),
+	// Execute email template - placeholders are replaced per message.
+	// Conditional subjects only apply when a condition ran in this run.
+	subjectTemplate, _ := templateMap["subject"].(string)
+	if executeVars.ConditionEvaluated() {
+		conditionMet, _ := executeVars.GetBool("conditionMet")
+		subjectTemplate = emailSubjectTemplate(templateMap, conditionMet)
 	}
+	bodyTemplate, _ := templateMap["body"].(string)
+	bodyHtmlTemplate, _ := templateMap["bodyHtml"].(string)
 
-	// Set delivery status
-	output["deliveryStatus"] = "sent"
-	output["messageId"] = fmt.Sprintf("msg_%d", time.Now().Unix())
-	output["emailSent"] = true
+	cc, err := emailAddressList(templateMap, "cc")
+	if err != nil {
+		return err
+	}
+	bcc, err := emailAddressList(templateMap, "bcc")
+	if err != nil {
+		return err
+	}
 
-	// Get outputVariables from metadata and set them
-	if outputVariables, hasOutputVars := metadata["outputVariables"]; hasOutputVars {
-		if outputVarsList, ok := outputVariables.([]any); ok {
-			for _, varName := range outputVarsList {
-				varNameStr, ok := varName.(string)
-				if !ok {
-					continue
-				}
+	// Attach content produced by earlier nodes
+	attachmentList, err := parseEmailAttachments(metadata)
+	if err != nil {
+		return err
+	}
+	attachments, err := resolveAttachments(ctx, attachmentList, executeVars)
+	if err != nil {
+		return err
+	}
 
-				// Set the output variable if it's already defined above
-				if _, exists := output[varNameStr]; !exists {
-					// If the output variable is not set yet, check if it should come from input
-					if value, exists := inputValues[varNameStr]; exists {
-						output[varNameStr] = value
-					}
-				}
+	draftTemplate := emailDraftTemplate{
+		subject:     subjectTemplate,
+		body:        bodyTemplate,
+		bodyHtml:    bodyHtmlTemplate,
+		cc:          cc,
+		bcc:         bcc,
+		attachments: attachments,
+	}
+
+	// Get retry policy for the send
+	retry, err := parseEmailRetry(metadata)
+	if err != nil {
+		return err
+	}
+
+	// Execution options take precedence over the node's retry settings
+	if retries := executionOptions(ctx).Retries; retries != nil && !*retries {
+		retry.maxAttempts = 1
+	}
+
+	// Get the variable holding the recipient when there is a single one
+	recipientVar, err := emailRecipientVar(metadata)
+	if err != nil {
+		return err
+	}
+
+	// Fan out one message per recipient when toVar names a recipient list
+	if toVar, exists := metadata["toVar"]; exists {
+		if err := s.executeEmailFanOut(ctx, toVar, draftTemplate, executeVars, retry, output); err != nil {
+			return err
+		}
+	} else {
+		// Get recipient email
+		email, _ := executeVars.GetString(recipientVar)
+		if email == "" && recipientVar != DefaultRecipientVar {
+			return fmt.Errorf("recipientVar '%s' not found in executeVars", recipientVar)
+		}
+
+		// Build email draft
+		draft := draftTemplate.render(email, executeVars.Snapshot(), s.now())
+		output["emailDraft"] = draft.Map()
+
+		// Deliver the email, retrying transient failures
+		messageID, attempts, err := s.sendEmail(ctx, draft, retry)
+		output["attempts"] = attempts
+		if err != nil {
+			output["deliveryStatus"] = "failed"
+			output["emailSent"] = false
+			return fmt.Errorf("failed to send email after %d attempt(s): %w", attempts, err)
+		}
+
+		// Set delivery status
+		if isDryRun(ctx) {
+			output["deliveryStatus"] = "dry_run"
+			output["emailSent"] = false
+		} else {
+			output["deliveryStatus"] = "sent"
+			output["messageId"] = messageID
+			output["emailSent"] = true
+		}
+	}
+
+	// Set the outputVariables that aren't already defined above
+	for _, varName := range outputVarsList {
+		if _, exists := output[varName]; !exists {
+			// If the output variable is not set yet, check if it should come from input
+			if value, exists := inputValues[varName]; exists {
+				output[varName] = value
 			}
 		}
 	}
@@ -547,67 +1408,121 @@ func (s *Service) executeEmailNode(node api.WorkflowNode, executeVars map[string
 	return nil
 }
 
+// executeEmailFanOut sends one message per recipient listed in the toVar array variable.
+// Every recipient is attempted; the step fails if any message could not be delivered.
+func (s *Service) executeEmailFanOut(ctx context.Context, toVar any, draftTemplate emailDraftTemplate, executeVars *ExecutionContext, retry emailRetry, output map[string]any) error {
+	recipients, err := resolveRecipients(toVar, executeVars.Snapshot())
+	if err != nil {
+		return err
+	}
+
+	drafts := make([]map[string]any, 0, len(recipients))
+	sentCount := 0
+	for _, recipient := range recipients {
+		message := draftTemplate.render(recipient.address, recipient.vars, s.now())
+		draft := message.Map()
+
+		messageID, attempts, err := s.sendEmail(ctx, message, retry)
+		draft["attempts"] = attempts
+		if err != nil {
+			draft["deliveryStatus"] = "failed"
+			draft["error"] = err.Error()
+		} else if isDryRun(ctx) {
+			draft["deliveryStatus"] = "dry_run"
+		} else {
+			draft["deliveryStatus"] = "sent"
+			draft["messageId"] = messageID
+			sentCount++
+		}
+		drafts = append(drafts, draft)
+	}
+
+	output["emailDrafts"] = drafts
+	output["recipientCount"] = len(recipients)
+	output["sentCount"] = sentCount
+	output["emailSent"] = sentCount > 0
+
+	if isDryRun(ctx) {
+		output["deliveryStatus"] = "dry_run"
+		return nil
+	}
+
+	switch {
+	case len(recipients) == 0:
+		output["deliveryStatus"] = "none"
+	case sentCount == len(recipients):
+		output["deliveryStatus"] = "sent"
+	case sentCount == 0:
+		output["deliveryStatus"] = "failed"
+	default:
+		output["deliveryStatus"] = "partial"
+	}
+
+	if failed := len(recipients) - sentCount; failed > 0 {
+		return fmt.Errorf("failed to send %d of %d emails", failed, len(recipients))
+	}
+
+	return nil
+}
+
 // executeFormNode executes form node data based on its metadata configuration
-func (s *Service) executeFormNode(node api.WorkflowNode, executeVars map[string]any, output map[string]any) error {
+func (s *Service) executeFormNode(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext, output map[string]any) error {
 	// Check if node has metadata
 	if node.Data == nil || node.Data.Metadata == nil {
 		// No metadata, just copy all executeVars to output
-		for k, v := range executeVars {
-			output[k] = v
+		for k, v := range executeVars.Snapshot() {
+			if !isReservedVariable(k) {
+				output[k] = v
+			}
 		}
 		return nil
 	}
 
-	metadata := *node.Data.Metadata
+	metadata := nodeMetadata(node)
+
+	// Fail when a field required by another field's value is missing
+	if err := checkRequiredWhen(metadata, executeVars); err != nil {
+		return err
+	}
 
 	// Check for outputVariables in metadata
-	outputVariables, hasOutputVars := metadata["outputVariables"]
-	if !hasOutputVars {
+	if !metadata.Has("outputVariables") {
 		// No outputVariables specified, copy all executeVars
-		for k, v := range executeVars {
-			output[k] = v
+		for k, v := range executeVars.Snapshot() {
+			if !isReservedVariable(k) {
+				output[k] = v
+			}
 		}
 		return nil
 	}
 
 	// Parse outputVariables
-	outputVarsList, ok := outputVariables.([]any)
-	if !ok {
-		return fmt.Errorf("outputVariables must be an array")
+	outputVarsList, err := metadata.StringSlice("outputVariables")
+	if err != nil {
+		return err
 	}
 
 	// Loop through outputVariables and copy values from executeVars
 	for _, varName := range outputVarsList {
-		varNameStr, ok := varName.(string)
-		if !ok {
-			continue
-		}
-
 		// Check if this variable exists in executeVars
-		if value, exists := executeVars[varNameStr]; exists {
-			output[varNameStr] = value
+		if value, exists := executeVars.Get(varName); exists {
+			output[varName] = value
 		} else {
 			// Variable not found in executeVars, set as null or skip
-			slog.Debug("Variable not found in executeVars", "variable", varNameStr)
-			output[varNameStr] = nil
+			slog.Debug("Variable not found in executeVars", "variable", varName)
+			output[varName] = nil
 		}
 	}
 
 	// Also check for inputFields to validate if all required fields are present
-	if inputFields, hasInputFields := metadata["inputFields"]; hasInputFields {
-		inputFieldsList, ok := inputFields.([]any)
-		if ok {
-			for _, field := range inputFieldsList {
-				fieldStr, ok := field.(string)
-				if !ok {
-					continue
-				}
-
-				// Log if an expected input field is missing
-				if _, exists := executeVars[fieldStr]; !exists {
-					slog.Warn("Expected input field not found in executeVars", "field", fieldStr)
-				}
-			}
+	inputFieldsList, err := metadata.StringSlice("inputFields")
+	if err != nil {
+		return err
+	}
+	for _, field := range inputFieldsList {
+		// Log if an expected input field is missing
+		if _, exists := executeVars.Get(field); !exists {
+			warnStep(ctx, "Expected input field not found in executeVars", "field", field)
 		}
 	}
 
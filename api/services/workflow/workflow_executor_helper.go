@@ -1,17 +1,45 @@
 package workflow
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"maps"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	api "workflow-code-test/api/openapi"
+	"workflow-code-test/api/pkg/mailer"
+	"workflow-code-test/api/pkg/redact"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// secretPlaceholder matches {{secret.NAME}} and {{env.NAME}} references in templates
+var secretPlaceholder = regexp.MustCompile(`\{\{(secret|env)\.([A-Za-z0-9_\-]+)\}\}`)
+
 // findValueInMap recursively searches for a key in a map up to maxDepth levels
 // It collects all matching values and returns the first numeric one if available
 func findValueInMap(data map[string]any, key string, currentDepth int, maxDepth int) any {
 	var candidates []any
 	findValueInMapHelper(data, key, currentDepth, maxDepth, &candidates)
 
-	// Prefer numeric values over strings
+	return preferNumeric(candidates)
+}
+
+// preferNumeric returns the first numeric candidate, or the first candidate if none is numeric
+func preferNumeric(candidates []any) any {
 	for _, candidate := range candidates {
 		switch v := candidate.(type) {
 		case float64:
@@ -33,21 +61,21 @@ func findValueInMap(data map[string]any, key string, currentDepth int, maxDepth
 	return nil
 }
 
+// normalizeJSONNumber converts a json.Number to float64 when it fits, leaving other values as they are
+func normalizeJSONNumber(value any) any {
+	if number, ok := value.(json.Number); ok {
+		if floatVal, err := number.Float64(); err == nil {
+			return floatVal
+		}
+	}
+	return value
+}
+
 // findValueInMapHelper is a helper that collects all values for a given key
 func findValueInMapHelper(data map[string]any, key string, currentDepth int, maxDepth int, candidates *[]any) {
 	// Check if the key exists at the current level
 	if value, exists := data[key]; exists {
-		// Handle JSON number type
-		switch v := value.(type) {
-		case json.Number:
-			if floatVal, err := v.Float64(); err == nil {
-				*candidates = append(*candidates, floatVal)
-			} else {
-				*candidates = append(*candidates, v)
-			}
-		default:
-			*candidates = append(*candidates, value)
-		}
+		*candidates = append(*candidates, normalizeJSONNumber(value))
 	}
 
 	// If we've reached max depth, stop searching
@@ -64,21 +92,1227 @@ func findValueInMapHelper(data map[string]any, key string, currentDepth int, max
 	}
 }
 
-// evaluateCondition evaluates a condition based on operator and threshold
-func evaluateCondition(value float64, operator string, threshold float64) bool {
+// evaluateCondition evaluates a condition based on operator and threshold.
+// equals and not_equals treat values within epsilon of the threshold as equal.
+func evaluateCondition(ctx context.Context, value float64, operator string, threshold float64, epsilon float64) bool {
 	switch operator {
 	case "greater_than":
 		return value > threshold
 	case "less_than":
 		return value < threshold
 	case "equals":
-		return value == threshold
+		return math.Abs(value-threshold) <= epsilon
+	case "not_equals":
+		return math.Abs(value-threshold) > epsilon
 	case "greater_than_or_equal":
 		return value >= threshold
 	case "less_than_or_equal":
 		return value <= threshold
 	default:
-		slog.Warn("Unknown operator, defaulting to greater_than", "operator", operator)
+		warnStep(ctx, "Unknown operator, defaulting to greater_than", "operator", operator)
 		return value > threshold
 	}
 }
+
+// conditionFromMetadata returns the default condition stored in a condition node's operator and
+// threshold metadata, or nil when the node defines neither. The truthy operator needs no threshold.
+func conditionFromMetadata(node api.WorkflowNode) (*api.Condition, error) {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return nil, nil
+	}
+	metadata := *node.Data.Metadata
+
+	rawOperator, hasOperator := metadata["operator"]
+	rawThreshold, hasThreshold := metadata["threshold"]
+	if !hasOperator && !hasThreshold {
+		return nil, nil
+	}
+	if hasOperator && rawOperator == string(api.Truthy) && !hasThreshold {
+		return &api.Condition{Operator: api.Truthy}, nil
+	}
+	if !hasOperator || !hasThreshold {
+		return nil, fmt.Errorf("condition metadata needs both operator and threshold")
+	}
+
+	operator, err := parseConditionOperator(rawOperator)
+	if err != nil {
+		return nil, err
+	}
+	threshold, ok := toFloat64(rawThreshold)
+	if !ok {
+		return nil, fmt.Errorf("threshold must be a number")
+	}
+
+	return &api.Condition{Operator: operator, Threshold: float32(threshold)}, nil
+}
+
+// isAnnotationNode reports whether nodes of this type, notes and comments, only document the
+// workflow. They pass straight through when run and are never expected to be connected.
+func isAnnotationNode(nodeType api.WorkflowNodeType) bool {
+	return nodeType == api.WorkflowNodeTypeNote || nodeType == api.WorkflowNodeTypeComment
+}
+
+// withDefaultOperator fills in the operator of an input condition that omits one from the
+// node's operator metadata. The input condition itself is shared by every condition node of
+// the execution, so a copy is returned.
+func withDefaultOperator(node api.WorkflowNode, condition api.Condition) (*api.Condition, error) {
+	rawOperator, hasOperator := nodeMetadata(node)["operator"]
+	if !hasOperator {
+		return nil, fmt.Errorf("condition operator is missing")
+	}
+	operator, err := parseConditionOperator(rawOperator)
+	if err != nil {
+		return nil, err
+	}
+	condition.Operator = operator
+	return &condition, nil
+}
+
+// parseConditionOperator checks that a metadata operator is one of the supported operators
+func parseConditionOperator(raw any) (api.ConditionOperator, error) {
+	operator, _ := raw.(string)
+	switch api.ConditionOperator(operator) {
+	case api.GreaterThan, api.LessThan, api.Equals, api.NotEquals, api.GreaterThanOrEqual, api.LessThanOrEqual, api.Truthy:
+		return api.ConditionOperator(operator), nil
+	}
+	return "", fmt.Errorf("operator must be one of greater_than, less_than, equals, not_equals, greater_than_or_equal, less_than_or_equal or truthy")
+}
+
+// isTruthy reports whether a value counts as true for the truthy operator: a true bool, a
+// non-zero number, or a non-empty string, array or object. nil and other types are false.
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+	if number, ok := toFloat64(value); ok {
+		return number != 0
+	}
+	return false
+}
+
+// valueType names the JSON type a condition's actualValue is returned as: number, string,
+// boolean, array, object or null
+func valueType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	if _, ok := toFloat64(value); ok {
+		return "number"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// evaluateDecimalCondition compares exact decimal values; equals and not_equals have no tolerance
+func evaluateDecimalCondition(ctx context.Context, value *big.Rat, operator string, threshold *big.Rat) bool {
+	cmp := value.Cmp(threshold)
+	switch operator {
+	case "greater_than":
+		return cmp > 0
+	case "less_than":
+		return cmp < 0
+	case "equals":
+		return cmp == 0
+	case "not_equals":
+		return cmp != 0
+	case "greater_than_or_equal":
+		return cmp >= 0
+	case "less_than_or_equal":
+		return cmp <= 0
+	default:
+		warnStep(ctx, "Unknown operator, defaulting to greater_than", "operator", operator)
+		return cmp > 0
+	}
+}
+
+// toDecimal parses a number, json.Number or numeric string as an exact decimal. It returns the
+// decimal text that was parsed so outputs can show the value without float rounding. Floats are
+// read from their shortest representation, which gives back the literal they were decoded from.
+func toDecimal(value any) (*big.Rat, string, bool) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = string(v)
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		text = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		text = fmt.Sprintf("%d", v)
+	default:
+		return nil, "", false
+	}
+
+	// big.Rat also accepts fractions such as "1/3", which are not decimals
+	if text == "" || strings.Contains(text, "/") {
+		return nil, "", false
+	}
+	decimal, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, "", false
+	}
+	return decimal, text, true
+}
+
+// thresholdFloat64 widens a float32 threshold through its shortest decimal, so a threshold of
+// 0.1 is compared and reported as 0.1 rather than 0.10000000149011612
+func thresholdFloat64(threshold float32) float64 {
+	value, err := strconv.ParseFloat(strconv.FormatFloat(float64(threshold), 'g', -1, 32), 64)
+	if err != nil {
+		return float64(threshold)
+	}
+	return value
+}
+
+// toFloat64 coerces any Go numeric type (and json.Number) to float64
+func toFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// splitBucket deterministically maps a value to a bucket in [0, 100)
+func splitBucket(salt string, value string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(salt + ":" + value))
+	return h.Sum32() % 100
+}
+
+// referencesSecret reports whether a template contains a {{secret.NAME}} reference
+func referencesSecret(template string) bool {
+	for _, match := range secretPlaceholder.FindAllStringSubmatch(template, -1) {
+		if match[1] == "secret" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSecrets replaces {{secret.NAME}} references in a template with values from the secret
+// store, and {{env.NAME}} references with environment variables on the template env allow-list.
+// An env reference outside the allow-list fails rather than resolving.
+func (s *Service) resolveSecrets(ctx context.Context, template string) (string, error) {
+	matches := secretPlaceholder.FindAllStringSubmatch(template, -1)
+	if len(matches) == 0 {
+		return template, nil
+	}
+
+	values := make(map[string]string, len(matches))
+	for _, match := range matches {
+		kind, name := match[1], match[2]
+		var value string
+		var err error
+		switch {
+		case kind == "env":
+			value, err = s.templateEnv.Get(name)
+		case s.secrets == nil:
+			err = fmt.Errorf("secret store not configured")
+		default:
+			value, err = s.secrets.Get(ctx, name)
+		}
+		if err != nil {
+			return "", err
+		}
+		values[match[0]] = value
+	}
+
+	// Replace in one pass, so a resolved value that spells out a reference is used as is
+	return secretPlaceholder.ReplaceAllStringFunc(template, func(reference string) string {
+		return values[reference]
+	}), nil
+}
+
+// aggregateValues combines values with the named operation.
+// Weights are only accepted for avg, where they produce a weighted average.
+func aggregateValues(operation string, values []float64, weights []float64) (float64, error) {
+	if weights != nil && operation != "avg" {
+		return 0, fmt.Errorf("aggregate node weights are only supported for avg")
+	}
+
+	switch operation {
+	case "sum":
+		var sum float64
+		for _, value := range values {
+			sum += value
+		}
+		return sum, nil
+	case "avg":
+		if weights == nil {
+			var sum float64
+			for _, value := range values {
+				sum += value
+			}
+			return sum / float64(len(values)), nil
+		}
+
+		var weightedSum, totalWeight float64
+		for i, value := range values {
+			weightedSum += value * weights[i]
+			totalWeight += weights[i]
+		}
+		if totalWeight == 0 {
+			return 0, fmt.Errorf("aggregate node weights must not all be zero")
+		}
+		return weightedSum / totalWeight, nil
+	case "min":
+		return slices.Min(values), nil
+	case "max":
+		return slices.Max(values), nil
+	default:
+		return 0, fmt.Errorf("unsupported aggregate operation '%s', expected avg, min, max or sum", operation)
+	}
+}
+
+// requiredWhenRule is one entry of a form node's "requiredWhen" metadata: field must be
+// present and non-empty when the "when" variable equals the "equals" value
+type requiredWhenRule struct {
+	field  string
+	when   string
+	equals any
+}
+
+// parseRequiredWhen reads a form node's "requiredWhen" rules
+func parseRequiredWhen(metadata map[string]any) ([]requiredWhenRule, error) {
+	rawRules, exists := metadata["requiredWhen"]
+	if !exists {
+		return nil, nil
+	}
+
+	rules, ok := rawRules.([]any)
+	if !ok {
+		return nil, fmt.Errorf("requiredWhen must be an array")
+	}
+
+	parsed := make([]requiredWhenRule, 0, len(rules))
+	for i, rawRule := range rules {
+		rule, ok := rawRule.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("requiredWhen[%d] must be an object", i)
+		}
+		field, _ := rule["field"].(string)
+		when, _ := rule["when"].(string)
+		if field == "" || when == "" {
+			return nil, fmt.Errorf("requiredWhen[%d] needs non-empty field and when", i)
+		}
+		parsed = append(parsed, requiredWhenRule{field: field, when: when, equals: rule["equals"]})
+	}
+	return parsed, nil
+}
+
+// missing reports whether the rule applies to executeVars and its field is missing or empty
+func (r requiredWhenRule) missing(executeVars *ExecutionContext) bool {
+	actual, exists := executeVars.Get(r.when)
+	if !exists || !formValuesEqual(actual, r.equals) {
+		return false
+	}
+	value, exists := executeVars.Get(r.field)
+	return !exists || value == nil || value == ""
+}
+
+// checkRequiredWhen applies a form node's "requiredWhen" rules, failing on the first field
+// that is required but missing
+func checkRequiredWhen(metadata map[string]any, executeVars *ExecutionContext) error {
+	rules, err := parseRequiredWhen(metadata)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.missing(executeVars) {
+			return fmt.Errorf("field '%s' is required when '%s' is %v", rule.field, rule.when, rule.equals)
+		}
+	}
+	return nil
+}
+
+// integrationSkipped applies an integration node's "skipIf" rule, reporting whether the call
+// should be skipped because the "when" variable equals the "equals" value. A missing variable
+// never matches, so the call is made.
+func integrationSkipped(node api.WorkflowNode, executeVars *ExecutionContext) (bool, error) {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return false, nil
+	}
+	rawRule, exists := (*node.Data.Metadata)["skipIf"]
+	if !exists {
+		return false, nil
+	}
+
+	rule, ok := rawRule.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("skipIf must be an object")
+	}
+	when, _ := rule["when"].(string)
+	if when == "" {
+		return false, fmt.Errorf("skipIf needs a non-empty when")
+	}
+
+	actual, exists := executeVars.Get(when)
+	return exists && formValuesEqual(actual, rule["equals"]), nil
+}
+
+// formValuesEqual compares a submitted form value with a rule value, treating numbers of
+// different Go types as equal when their values match
+func formValuesEqual(actual any, expected any) bool {
+	if a, ok := toFloat64(actual); ok {
+		b, ok := toFloat64(expected)
+		return ok && a == b
+	}
+	switch expected.(type) {
+	case string, bool, nil:
+		return actual == expected
+	}
+	return false
+}
+
+// endNodeResult builds the result object of an end node from the variables named by its
+// "resultVariables" metadata. Variables never set during the execution, e.g. on a branch not
+// taken, are left out. Without resultVariables the node has no result.
+func endNodeResult(ctx context.Context, node api.WorkflowNode, executeVars *ExecutionContext) (map[string]any, error) {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return nil, nil
+	}
+	rawNames, exists := (*node.Data.Metadata)["resultVariables"]
+	if !exists {
+		return nil, nil
+	}
+
+	names, ok := rawNames.([]any)
+	if !ok {
+		return nil, fmt.Errorf("resultVariables must be an array of strings")
+	}
+	result := make(map[string]any, len(names))
+	for _, rawName := range names {
+		name, ok := rawName.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("resultVariables must be an array of strings")
+		}
+		value, exists := executeVars.Get(name)
+		if !exists {
+			slog.DebugContext(ctx, "Result variable not set, leaving it out", "variable", name, "nodeID", node.Id)
+			continue
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// workflowResult returns the result object produced by the end node reached, if any
+func workflowResult(steps []api.ExecutionStep) *map[string]any {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Type != string(api.WorkflowNodeTypeEnd) || steps[i].Output == nil {
+			continue
+		}
+		if result, ok := (*steps[i].Output)["result"].(map[string]any); ok {
+			return &result
+		}
+	}
+	return nil
+}
+
+// optionValueMatches reports whether an integration option's value matches an input value.
+// Numbers match by value whatever their type, as for form rules, and strings match
+// regardless of case when caseInsensitive is set.
+func optionValueMatches(optValue, value any, caseInsensitive bool) bool {
+	if optString, ok := optValue.(string); ok && caseInsensitive {
+		str, ok := value.(string)
+		return ok && strings.EqualFold(optString, str)
+	}
+	return formValuesEqual(value, optValue)
+}
+
+// Types an integration node can declare for its output variables
+const (
+	OutputTypeNumber  = "number"
+	OutputTypeString  = "string"
+	OutputTypeBoolean = "boolean"
+)
+
+// parseOutputTypes reads the optional "outputTypes" metadata of an integration node,
+// mapping output variable names to the type their values must have
+func parseOutputTypes(metadata map[string]any) (map[string]string, error) {
+	rawTypes, exists := metadata["outputTypes"]
+	if !exists {
+		return nil, nil
+	}
+
+	typesMap, ok := rawTypes.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("outputTypes must be an object")
+	}
+
+	outputTypes := make(map[string]string, len(typesMap))
+	for name, rawType := range typesMap {
+		typeName, _ := rawType.(string)
+		switch typeName {
+		case OutputTypeNumber, OutputTypeString, OutputTypeBoolean:
+			outputTypes[name] = typeName
+		default:
+			return nil, fmt.Errorf("outputTypes.%s must be one of number, string or boolean", name)
+		}
+	}
+	return outputTypes, nil
+}
+
+// coerceOutputValue converts an API response value to the declared type.
+// Numeric and boolean strings are parsed; anything else that doesn't match fails.
+func coerceOutputValue(value any, expected string) (any, error) {
+	switch expected {
+	case OutputTypeNumber:
+		if number, ok := toFloat64(value); ok {
+			return number, nil
+		}
+		if str, ok := value.(string); ok {
+			if number, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+				return number, nil
+			}
+		}
+	case OutputTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		}
+	case OutputTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		if str, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(str)); err == nil {
+				return b, nil
+			}
+		}
+	}
+	if str, ok := value.(string); ok {
+		return nil, fmt.Errorf("expected %s, got string %q", expected, str)
+	}
+	return nil, fmt.Errorf("expected %s, got %T %v", expected, value, value)
+}
+
+// maxAwaitFieldMs is the longest a condition node may wait for its field to be set
+const maxAwaitFieldMs = 10000
+
+// conditionFieldPollInterval is how often a waiting condition node rechecks executeVars
+const conditionFieldPollInterval = 10 * time.Millisecond
+
+// awaitConditionField reads field from executeVars, rechecking until it is set or wait has
+// elapsed. With no wait the field is read once, so a missing field fails immediately.
+func awaitConditionField(ctx context.Context, executeVars *ExecutionContext, field string, wait time.Duration) (any, bool) {
+	deadline := time.Now().Add(wait)
+	for {
+		value, exists := executeVars.GetPath(field)
+		if exists || !time.Now().Before(deadline) {
+			return value, exists
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(conditionFieldPollInterval):
+		}
+	}
+}
+
+// Upper bounds on email retries so a single node can't hold an execution open indefinitely
+const (
+	maxEmailAttempts  = 10
+	maxEmailBackoffMs = 30000
+	maxEmailDelay     = 30 * time.Second
+)
+
+// emailRetry is the retry policy of an email node
+type emailRetry struct {
+	maxAttempts int
+	backoff     time.Duration
+	jitter      bool
+}
+
+// parseEmailRetry reads the optional "retry" metadata of an email node.
+// Without it the email is attempted once.
+func parseEmailRetry(metadata map[string]any) (emailRetry, error) {
+	rawRetry, exists := metadata["retry"]
+	if !exists {
+		return emailRetry{maxAttempts: 1}, nil
+	}
+
+	retry, ok := rawRetry.(map[string]any)
+	if !ok {
+		return emailRetry{}, fmt.Errorf("retry must be an object")
+	}
+
+	maxAttempts := 1
+	if rawAttempts, exists := retry["maxAttempts"]; exists {
+		attempts, ok := toFloat64(rawAttempts)
+		if !ok || attempts < 1 || attempts != math.Trunc(attempts) {
+			return emailRetry{}, fmt.Errorf("retry.maxAttempts must be a positive integer")
+		}
+		if attempts > maxEmailAttempts {
+			return emailRetry{}, fmt.Errorf("retry.maxAttempts must not exceed %d", maxEmailAttempts)
+		}
+		maxAttempts = int(attempts)
+	}
+
+	var backoff time.Duration
+	if rawBackoff, exists := retry["backoffMs"]; exists {
+		backoffMs, ok := toFloat64(rawBackoff)
+		if !ok || backoffMs < 0 {
+			return emailRetry{}, fmt.Errorf("retry.backoffMs must be a non-negative number")
+		}
+		if backoffMs > maxEmailBackoffMs {
+			return emailRetry{}, fmt.Errorf("retry.backoffMs must not exceed %d", maxEmailBackoffMs)
+		}
+		backoff = time.Duration(backoffMs) * time.Millisecond
+	}
+
+	var jitter bool
+	if rawJitter, exists := retry["jitter"]; exists {
+		if jitter, ok = rawJitter.(bool); !ok {
+			return emailRetry{}, fmt.Errorf("retry.jitter must be a boolean")
+		}
+	}
+
+	return emailRetry{maxAttempts: maxAttempts, backoff: backoff, jitter: jitter}, nil
+}
+
+// DefaultIntegrationTimeout bounds an integration API call when no timeout is configured
+const DefaultIntegrationTimeout = 30 * time.Second
+
+// maxIntegrationTimeoutMs is the longest timeout a node or execution may configure
+const maxIntegrationTimeoutMs = 300000
+
+// integrationTimeout resolves the API call timeout of an integration node. The execution's
+// timeoutMs takes precedence over the node's timeoutMs, which overrides the default.
+func integrationTimeout(ctx context.Context, metadata map[string]any) (time.Duration, error) {
+	if timeoutMs := executionOptions(ctx).TimeoutMs; timeoutMs != nil {
+		return time.Duration(*timeoutMs) * time.Millisecond, nil
+	}
+
+	rawTimeout, exists := metadata["timeoutMs"]
+	if !exists {
+		return DefaultIntegrationTimeout, nil
+	}
+
+	timeoutMs, ok := toFloat64(rawTimeout)
+	if !ok || timeoutMs < 1 || timeoutMs != math.Trunc(timeoutMs) {
+		return 0, fmt.Errorf("timeoutMs must be a positive integer")
+	}
+	if timeoutMs > maxIntegrationTimeoutMs {
+		return 0, fmt.Errorf("timeoutMs must not exceed %d", maxIntegrationTimeoutMs)
+	}
+	return time.Duration(timeoutMs) * time.Millisecond, nil
+}
+
+// sendEmail delivers a message, retrying transient failures with exponential backoff.
+// It returns the message ID and the number of attempts made.
+func (s *Service) sendEmail(ctx context.Context, draft mailer.EmailDraft, retry emailRetry) (string, int, error) {
+	// Dry runs render the draft but never hand it to the sender
+	if isDryRun(ctx) {
+		slog.DebugContext(ctx, "Dry run: email not sent", "subject", draft.Subject)
+		return "", 0, nil
+	}
+
+	sender := s.emailSender()
+
+	var lastErr error
+	for attempt := 1; attempt <= retry.maxAttempts; attempt++ {
+		messageID, err := sender.Send(ctx, draft)
+		if err == nil {
+			return messageID, attempt, nil
+		}
+		lastErr = err
+
+		// Permanent failures such as an unknown recipient are not retried
+		if !mailer.IsTransient(err) || attempt == retry.maxAttempts {
+			return "", attempt, lastErr
+		}
+
+		delay := retryDelay(ctx, retry.backoff, attempt, maxEmailDelay, retry.jitter)
+		warnStep(ctx, "Transient email failure, retrying", "error", err, "attempt", attempt, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return "", attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return "", retry.maxAttempts, lastErr
+}
+
+// emailRecipient is a fan-out target together with the values used for its placeholders
+type emailRecipient struct {
+	address string
+	vars    map[string]any
+}
+
+// DefaultRecipientVar holds the recipient of an email node without recipientVar
+const DefaultRecipientVar = "email"
+
+// emailRecipientVar returns the variable holding a single-recipient email node's address
+func emailRecipientVar(metadata map[string]any) (string, error) {
+	rawVar, exists := metadata["recipientVar"]
+	if !exists {
+		return DefaultRecipientVar, nil
+	}
+	if _, hasToVar := metadata["toVar"]; hasToVar {
+		return "", fmt.Errorf("recipientVar can't be used with toVar")
+	}
+	varName, ok := rawVar.(string)
+	if !ok || varName == "" {
+		return "", fmt.Errorf("recipientVar must be a non-empty string")
+	}
+	return varName, nil
+}
+
+// resolveRecipients reads the recipient list named by an email node's toVar.
+// Entries may be addresses or objects with an "email" key whose other keys override executeVars.
+func resolveRecipients(toVar any, executeVars map[string]any) ([]emailRecipient, error) {
+	varName, ok := toVar.(string)
+	if !ok || varName == "" {
+		return nil, fmt.Errorf("toVar must be a non-empty string")
+	}
+
+	rawList, exists := executeVars[varName]
+	if !exists {
+		return nil, fmt.Errorf("toVar '%s' not found in executeVars", varName)
+	}
+
+	var entries []any
+	switch list := rawList.(type) {
+	case []any:
+		entries = list
+	case []string:
+		for _, address := range list {
+			entries = append(entries, address)
+		}
+	default:
+		return nil, fmt.Errorf("toVar '%s' must be an array", varName)
+	}
+
+	recipients := make([]emailRecipient, 0, len(entries))
+	for i, entry := range entries {
+		// Each recipient sees the shared variables plus its own values
+		vars := make(map[string]any, len(executeVars))
+		for key, value := range executeVars {
+			vars[key] = value
+		}
+
+		var address string
+		switch recipient := entry.(type) {
+		case string:
+			address = recipient
+		case map[string]any:
+			address, _ = recipient["email"].(string)
+			for key, value := range recipient {
+				vars[key] = value
+			}
+		default:
+			return nil, fmt.Errorf("recipient %d in '%s' must be a string or an object", i, varName)
+		}
+		vars["email"] = address
+
+		if address == "" {
+			return nil, fmt.Errorf("recipient %d in '%s' is missing an email", i, varName)
+		}
+		recipients = append(recipients, emailRecipient{address: address, vars: vars})
+	}
+
+	return recipients, nil
+}
+
+// emailSubjectTemplate picks subjectMet or subjectNotMet based on conditionMet,
+// falling back to the plain subject when that variant is not provided
+func emailSubjectTemplate(templateMap map[string]any, conditionMet bool) string {
+	variant := "subjectNotMet"
+	if conditionMet {
+		variant = "subjectMet"
+	}
+	if subject, ok := templateMap[variant].(string); ok && subject != "" {
+		return subject
+	}
+	subject, _ := templateMap["subject"].(string)
+	return subject
+}
+
+// hasNotMetSubject reports whether the template an email node would use declares a
+// subjectNotMet variant, meaning it should still send when the condition is not met
+func hasNotMetSubject(node api.WorkflowNode, executeVars *ExecutionContext) bool {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return false
+	}
+	templateMap, _, err := selectEmailTemplate(*node.Data.Metadata, executeVars)
+	if err != nil {
+		return false
+	}
+	subject, ok := templateMap["subjectNotMet"].(string)
+	return ok && subject != ""
+}
+
+// DefaultEmailTemplateKey names the template used when the selector value has no template of its own
+const DefaultEmailTemplateKey = "default"
+
+// selectEmailTemplate returns an email node's template and the key it was chosen by. With
+// templates and templateSelector set, the template keyed by the selector variable's value is
+// used, falling back to the default template; otherwise the single emailTemplate is used.
+func selectEmailTemplate(metadata map[string]any, executeVars *ExecutionContext) (map[string]any, string, error) {
+	rawTemplates, hasTemplates := metadata["templates"]
+	if !hasTemplates {
+		emailTemplate, hasTemplate := metadata["emailTemplate"]
+		if !hasTemplate {
+			return nil, "", fmt.Errorf("email node missing emailTemplate in metadata")
+		}
+		templateMap, ok := emailTemplate.(map[string]any)
+		if !ok {
+			return nil, "", fmt.Errorf("emailTemplate must be an object")
+		}
+		return templateMap, "", nil
+	}
+
+	if _, hasTemplate := metadata["emailTemplate"]; hasTemplate {
+		return nil, "", fmt.Errorf("emailTemplate can't be used with templates")
+	}
+	templates, ok := rawTemplates.(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf("templates must be an object")
+	}
+	selector, ok := metadata["templateSelector"].(string)
+	if !ok || selector == "" {
+		return nil, "", fmt.Errorf("templateSelector must be a non-empty string")
+	}
+
+	key := DefaultEmailTemplateKey
+	if value, exists := executeVars.Get(selector); exists {
+		if _, matched := templates[fmt.Sprintf("%v", value)]; matched {
+			key = fmt.Sprintf("%v", value)
+		}
+	}
+	rawTemplate, exists := templates[key]
+	if !exists {
+		value, _ := executeVars.Get(selector)
+		return nil, "", fmt.Errorf("no template matches %s '%v' and there is no default template", selector, value)
+	}
+	templateMap, ok := rawTemplate.(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf("templates.%s must be an object", key)
+	}
+	return templateMap, key, nil
+}
+
+// emailDraftTemplate holds the unrendered parts of an email node's emailTemplate
+type emailDraftTemplate struct {
+	subject     string
+	body        string
+	bodyHtml    string
+	cc          []string
+	bcc         []string
+	attachments []mailer.Attachment
+}
+
+// render builds the draft sent to one recipient, replacing placeholders with vars
+func (t emailDraftTemplate) render(to string, vars map[string]any, now time.Time) mailer.EmailDraft {
+	draft := mailer.EmailDraft{
+		To:          to,
+		From:        emailSenderAddress,
+		Subject:     renderTemplate(t.subject, vars),
+		Body:        renderTemplate(t.body, vars),
+		BodyHtml:    renderTemplate(t.bodyHtml, vars),
+		Attachments: t.attachments,
+		Timestamp:   now.UTC().Truncate(time.Second),
+	}
+	for _, address := range t.cc {
+		draft.Cc = append(draft.Cc, renderTemplate(address, vars))
+	}
+	for _, address := range t.bcc {
+		draft.Bcc = append(draft.Bcc, renderTemplate(address, vars))
+	}
+	return draft
+}
+
+// emailAddressList reads an optional array of addresses, such as cc or bcc, from an emailTemplate
+func emailAddressList(templateMap map[string]any, key string) ([]string, error) {
+	raw, exists := templateMap[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("emailTemplate.%s must be an array of strings", key)
+	}
+
+	addresses := make([]string, 0, len(list))
+	for _, entry := range list {
+		address, ok := entry.(string)
+		if !ok || address == "" {
+			return nil, fmt.Errorf("emailTemplate.%s must be an array of strings", key)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// traversedEdge records an edge taken during execution, with its label's placeholders replaced
+// by the current variables. Sensitive values are redacted before they reach the label.
+func (s *Service) traversedEdge(edge api.WorkflowEdge, executeVars *ExecutionContext) api.TraversedEdge {
+	traversed := api.TraversedEdge{
+		Id:           edge.Id,
+		Source:       edge.Source,
+		Target:       edge.Target,
+		SourceHandle: edge.SourceHandle,
+	}
+	if edge.Label != nil {
+		label := renderTemplate(*edge.Label, s.outputRedactor().Map(executeVars.Snapshot()))
+		traversed.ResolvedLabel = &label
+	}
+	return traversed
+}
+
+// renderTemplate replaces {{key}} placeholders with the matching values
+func renderTemplate(template string, vars map[string]any) string {
+	for key, value := range vars {
+		placeholder := fmt.Sprintf("{{%s}}", key)
+		template = strings.ReplaceAll(template, placeholder, fmt.Sprintf("%v", value))
+	}
+	return template
+}
+
+// Reserved variables seeded into executeVars for every execution
+const (
+	ReservedVarNow          = "_now"
+	ReservedVarWorkflowID   = "_workflowId"
+	ReservedVarWorkflowName = "_workflowName"
+	ReservedVarExecutionID  = "_executionId"
+	// ReservedVarHeaderPrefix namespaces forwarded request headers, e.g. X-Tenant becomes _header_X_Tenant
+	ReservedVarHeaderPrefix = "_header_"
+)
+
+// NodeMessageKey holds the human-readable message of integration, condition and email steps.
+// It is reserved, so the message is shown in the step output but never merged into executeVars,
+// where one node's message would overwrite another's.
+const NodeMessageKey = "_nodeMessage"
+
+// executionIDKey is the context key carrying the current execution ID
+type executionIDKey struct{}
+
+// withExecutionID returns a context carrying the execution ID
+func withExecutionID(ctx context.Context, executionID openapi_types.UUID) context.Context {
+	return context.WithValue(ctx, executionIDKey{}, executionID)
+}
+
+// executionIDFromContext returns the execution ID carried by ctx, if any
+func executionIDFromContext(ctx context.Context) (openapi_types.UUID, bool) {
+	executionID, ok := ctx.Value(executionIDKey{}).(openapi_types.UUID)
+	return executionID, ok
+}
+
+// redactBody renders an API response body for logs and errors with sensitive values masked.
+// Bodies that are not JSON are omitted because their contents can't be inspected.
+func (s *Service) redactBody(body []byte) string {
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("<%d bytes of non-JSON body omitted>", len(body))
+	}
+
+	encoded, err := json.Marshal(s.outputRedactor().Value("", data))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes of body omitted>", len(body))
+	}
+	return string(encoded)
+}
+
+// rawResponseOutputKey carries an integration's persisted response body from the node to its
+// step. The reserved prefix keeps it out of executeVars.
+const rawResponseOutputKey = "_rawResponse"
+
+// maxPersistedResponseBytes is the largest response body kept for a step's rawResponse
+const maxPersistedResponseBytes = 1 << 20
+
+// persistableBody returns the response body to store with the execution. JSON bodies are kept
+// byte for byte unless they hold keys the redactor masks, in which case the redacted JSON is
+// kept instead; numbers are decoded as json.Number so large IDs are re-encoded unchanged.
+// Other bodies, such as text or XML, can't be inspected for secrets, so only their size is
+// kept, as in logs. Bodies over maxPersistedResponseBytes are not kept.
+func (s *Service) persistableBody(body []byte) (string, bool) {
+	if len(body) > maxPersistedResponseBytes {
+		return "", false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil || decoder.More() {
+		return fmt.Sprintf("<%d bytes of non-JSON body omitted>", len(body)), true
+	}
+	redacted := s.outputRedactor().Value("", data)
+	if reflect.DeepEqual(redacted, data) {
+		return string(body), true
+	}
+	encoded, err := json.Marshal(redacted)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// takeRawResponse removes a persisted response body from a node's output so it is returned
+// as the step's rawResponse rather than as an output variable
+func takeRawResponse(output map[string]any) *string {
+	raw, ok := output[rawResponseOutputKey].(string)
+	if !ok {
+		return nil
+	}
+	delete(output, rawResponseOutputKey)
+	return &raw
+}
+
+// dryRunKey is the context key marking an execution that must not cause side effects
+type dryRunKey struct{}
+
+// withDryRun returns a context under which email and integration nodes skip external calls
+func withDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// isDryRun reports whether ctx belongs to a dry run
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// executionOptionsKey is the context key carrying the run-time overrides of an execution
+type executionOptionsKey struct{}
+
+// withExecutionOptions returns a context carrying the caller's overrides for one execution
+func withExecutionOptions(ctx context.Context, options *api.ExecutionOptions) context.Context {
+	if options == nil {
+		return ctx
+	}
+	if options.DryRun != nil && *options.DryRun {
+		ctx = withDryRun(ctx)
+	}
+	return context.WithValue(ctx, executionOptionsKey{}, *options)
+}
+
+// executionOptions returns the overrides the execution was started with, if any
+func executionOptions(ctx context.Context) api.ExecutionOptions {
+	options, _ := ctx.Value(executionOptionsKey{}).(api.ExecutionOptions)
+	return options
+}
+
+// validateExecutionOptions checks run-time overrides before an execution starts
+func validateExecutionOptions(options *api.ExecutionOptions) error {
+	if options == nil {
+		return nil
+	}
+	if options.TimeoutMs != nil && (*options.TimeoutMs < 1 || *options.TimeoutMs > maxIntegrationTimeoutMs) {
+		return fmt.Errorf("executionOptions.timeoutMs must be between 1 and %d", maxIntegrationTimeoutMs)
+	}
+	if options.EndpointOverrides != nil {
+		for nodeID, endpoint := range *options.EndpointOverrides {
+			parsed, err := url.Parse(endpoint)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("executionOptions.endpointOverrides.%s must be an absolute http or https URL", nodeID)
+			}
+		}
+	}
+	return nil
+}
+
+// endpointOverride returns the API endpoint the execution supplied for an integration node, if any
+func endpointOverride(ctx context.Context, nodeID string) (string, bool) {
+	overrides := executionOptions(ctx).EndpointOverrides
+	if overrides == nil {
+		return "", false
+	}
+	endpoint, exists := (*overrides)[nodeID]
+	return endpoint, exists
+}
+
+// requestHeadersKey is the context key carrying forwarded request headers
+type requestHeadersKey struct{}
+
+// withRequestHeaders returns a context carrying the allow-listed headers of the triggering request
+func withRequestHeaders(ctx context.Context, header http.Header, allowList []string) context.Context {
+	vars := map[string]any{}
+	for _, name := range allowList {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		vars[headerVariable(name)] = value
+	}
+	return context.WithValue(ctx, requestHeadersKey{}, vars)
+}
+
+// headerVariable returns the reserved variable name a request header is exposed under
+func headerVariable(name string) string {
+	return ReservedVarHeaderPrefix + strings.ReplaceAll(http.CanonicalHeaderKey(name), "-", "_")
+}
+
+// withoutHeaderVariables drops header variables from caller-supplied values so only
+// allow-listed request headers can populate them
+func withoutHeaderVariables(vars map[string]any) map[string]any {
+	filtered := make(map[string]any, len(vars))
+	for key, value := range vars {
+		if strings.HasPrefix(key, ReservedVarHeaderPrefix) {
+			slog.Debug("Ignoring input for header variable", "variable", key)
+			continue
+		}
+		filtered[key] = value
+	}
+	return filtered
+}
+
+// reservedVariables builds the underscore-prefixed variables available to every template
+func reservedVariables(ctx context.Context, workflow api.Workflow, now time.Time) map[string]any {
+	vars := map[string]any{
+		ReservedVarNow:          now.UTC().Format(time.RFC3339),
+		ReservedVarWorkflowID:   workflow.Id.String(),
+		ReservedVarWorkflowName: "",
+		ReservedVarExecutionID:  "",
+	}
+	if workflow.Name != nil {
+		vars[ReservedVarWorkflowName] = *workflow.Name
+	}
+	if executionID, ok := executionIDFromContext(ctx); ok {
+		vars[ReservedVarExecutionID] = executionID.String()
+	}
+	if headers, ok := ctx.Value(requestHeadersKey{}).(map[string]any); ok {
+		maps.Copy(vars, headers)
+	}
+	return vars
+}
+
+// isReservedVariable reports whether a variable name is in the reserved namespace
+func isReservedVariable(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// mergeNodeOutput copies a node's output into executeVars, leaving reserved variables untouched
+func mergeNodeOutput(executeVars *ExecutionContext, output map[string]any) {
+	for key, value := range output {
+		if isReservedVariable(key) {
+			slog.Debug("Ignoring node output for reserved variable", "variable", key)
+			continue
+		}
+		executeVars.Set(key, value)
+	}
+}
+
+// isScalarResponse reports whether a decoded response is a bare number, string or boolean
+func isScalarResponse(value any) bool {
+	switch value.(type) {
+	case json.Number, string, bool:
+		return true
+	}
+	return false
+}
+
+// emailNodeMessage describes a completed email step from its deliveryStatus
+func emailNodeMessage(output map[string]any) string {
+	switch output["deliveryStatus"] {
+	case "dry_run":
+		return "Dry run: email not sent"
+	case "none":
+		return "No recipients - no email sent"
+	}
+	if recipientCount, ok := output["recipientCount"].(int); ok {
+		return fmt.Sprintf("Email sent to %d of %d recipients", output["sentCount"], recipientCount)
+	}
+	return "Email sent successfully"
+}
+
+// nodeDebugEnabled reports whether a node's metadata sets debug to true
+func nodeDebugEnabled(node api.WorkflowNode) bool {
+	if node.Data == nil || node.Data.Metadata == nil {
+		return false
+	}
+	debug, _ := (*node.Data.Metadata)["debug"].(bool)
+	return debug
+}
+
+// nodeDebugInputs returns the variables a node reads: its inputVariables when declared,
+// otherwise every variable
+func nodeDebugInputs(node api.WorkflowNode, executeVars *ExecutionContext) map[string]any {
+	snapshot := executeVars.Snapshot()
+	names, ok := (*node.Data.Metadata)["inputVariables"].([]any)
+	if !ok {
+		return snapshot
+	}
+	inputs := make(map[string]any, len(names))
+	for _, name := range names {
+		if nameStr, ok := name.(string); ok {
+			if value, exists := snapshot[nameStr]; exists {
+				inputs[nameStr] = value
+			}
+		}
+	}
+	return inputs
+}
+
+// logNodeDebug logs a debug-flagged node's inputs and output at info level. The record goes
+// straight to the default handler, skipping its level check, so it is written even when the
+// service only logs warnings. Values are redacted like step output before they are logged.
+func logNodeDebug(ctx context.Context, redactor *redact.Redactor, node api.WorkflowNode, inputs map[string]any, step api.ExecutionStep) {
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Node debug", 0)
+	record.AddAttrs(
+		slog.String("nodeID", node.Id),
+		slog.String("type", string(node.Type)),
+		slog.String("status", string(step.Status)),
+		slog.Any("input", redactor.Map(inputs)),
+		slog.Any("output", redactor.Map(*step.Output)),
+	)
+	if step.Error != nil {
+		record.AddAttrs(slog.String("error", *step.Error))
+	}
+	if err := slog.Default().Handler().Handle(ctx, record); err != nil {
+		slog.WarnContext(ctx, "Failed to log node debug output", "error", err, "nodeID", node.Id)
+	}
+}
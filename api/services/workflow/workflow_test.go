@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	api "workflow-code-test/api/openapi"
 	"workflow-code-test/api/pkg/cache"
 	cachemocks "workflow-code-test/api/pkg/cache/mocks"
+	"workflow-code-test/api/pkg/db"
 	dbmocks "workflow-code-test/api/pkg/db/mocks"
 	"workflow-code-test/api/pkg/db/models"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -202,17 +205,17 @@ func TestHandleGetWorkflow(t *testing.T) {
 		},
 
 		"workflow_not_found": {
-			workflowID: "non-existent-id",
+			workflowID: "9f1c2d3e-0000-4000-8000-000000000404",
 			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
 				// Mock cache miss so it goes to database
-				cacheKey := "workflow:non-existent-id"
+				cacheKey := "workflow:9f1c2d3e-0000-4000-8000-000000000404"
 				mockCache.EXPECT().
 					Get(gomock.Any(), cacheKey, gomock.Any()).
 					Return(cache.ErrCacheMiss{Key: cacheKey})
 
 				mockDB.EXPECT().
-					GetWorkflowByID(gomock.Any(), "non-existent-id").
-					Return(nil, fmt.Errorf("workflow not found: non-existent-id"))
+					GetWorkflowByID(gomock.Any(), "9f1c2d3e-0000-4000-8000-000000000404").
+					Return(nil, fmt.Errorf("%w: 9f1c2d3e-0000-4000-8000-000000000404", db.ErrWorkflowNotFound))
 			},
 			expectedStatus: http.StatusNotFound,
 			checkResponse: func(t *testing.T, body []byte) {
@@ -247,9 +250,23 @@ func TestHandleGetWorkflow(t *testing.T) {
 
 		"invalid_workflow_id_format": {
 			workflowID: "invalid-uuid",
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// Rejected before the cache or database is consulted
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+
+		"stored_workflow_with_invalid_id": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
 			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
 				// Mock cache miss so it goes to database
-				cacheKey := "workflow:invalid-uuid"
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
 				mockCache.EXPECT().
 					Get(gomock.Any(), cacheKey, gomock.Any()).
 					Return(cache.ErrCacheMiss{Key: cacheKey})
@@ -261,7 +278,7 @@ func TestHandleGetWorkflow(t *testing.T) {
 				}
 
 				mockDB.EXPECT().
-					GetWorkflowByID(gomock.Any(), "invalid-uuid").
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
 					Return(workflow, nil)
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -269,7 +286,7 @@ func TestHandleGetWorkflow(t *testing.T) {
 				var response api.Error
 				err := json.Unmarshal(body, &response)
 				require.NoError(t, err)
-				assert.Equal(t, "Failed to retrieve workflow", response.Error)
+				assert.Equal(t, "Stored workflow definition is invalid", response.Error)
 			},
 		},
 
@@ -510,15 +527,47 @@ func TestHandleGetWorkflow(t *testing.T) {
 	}
 }
 
+func TestHandleGetDemoWorkflow(t *testing.T) {
+	// No database or cache: the demo must be served without either
+	service := &Service{}
+
+	req, err := http.NewRequest("GET", "/workflows/demo", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	service.HandleGetDemoWorkflow(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response api.Workflow
+	err = json.Unmarshal(rr.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	// The ID must stay in step with the seeded sample workflow
+	assert.Equal(t, DemoWorkflowID, response.Id.String())
+	require.NotNil(t, response.Nodes)
+	require.NotNil(t, response.Edges)
+	assert.Len(t, *response.Nodes, 6)
+	assert.Len(t, *response.Edges, 6)
+
+	// The demo must pass the same validation as stored workflows
+	assert.NoError(t, service.validateWorkflow(response))
+}
+
 func TestHandleExecuteWorkflow(t *testing.T) {
 	tests := map[string]struct {
 		// Input
 		workflowID  string
+		query       string
+		headers     map[string]string
 		requestBody interface{}
 
 		// Mock setup
 		setupMock func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache)
 
+		// Executions of the workflow already running, each holding the only slots allowed
+		runningExecutions int
+
 		// Expected response
 		expectedStatus int
 		checkResponse  func(t *testing.T, body []byte)
@@ -617,6 +666,162 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 			},
 		},
 
+		"sensitive_output_is_redacted": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			requestBody: api.WorkflowExecutionInput{
+				FormData: &map[string]interface{}{
+					"name":     "John Doe",
+					"password": "hunter2",
+				},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{
+					ID:   "550e8400-e29b-41d4-a716-446655440000",
+					Name: "Test Workflow",
+				}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{NodeID: "node-form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "edge-1", Source: "start", Target: "node-form"},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
+					Return(workflow, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				require.Len(t, response.Steps, 2)
+
+				formOutput := *response.Steps[1].Output
+				assert.Equal(t, "John Doe", formOutput["name"])
+				assert.Equal(t, "***", formOutput["password"])
+			},
+		},
+
+		"include_summary_only": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			query:      "include=summary",
+			requestBody: api.WorkflowExecutionInput{
+				FormData: &map[string]interface{}{"name": "John Doe"},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{
+					ID:   "550e8400-e29b-41d4-a716-446655440000",
+					Name: "Test Workflow",
+				}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{NodeID: "node-form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "edge-1", Source: "start", Target: "node-form"},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
+					Return(workflow, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response map[string]any
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "completed", response["status"])
+				assert.Contains(t, response, "executedAt")
+				assert.NotContains(t, response, "steps")
+			},
+		},
+
+		"fields_trim_step_output": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			query:      "include=steps&fields=name",
+			requestBody: api.WorkflowExecutionInput{
+				FormData: &map[string]interface{}{
+					"name": "John Doe",
+					"city": "Sydney",
+				},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{
+					ID:   "550e8400-e29b-41d4-a716-446655440000",
+					Name: "Test Workflow",
+				}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{NodeID: "node-form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "edge-1", Source: "start", Target: "node-form"},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
+					Return(workflow, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response map[string]any
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.NotContains(t, response, "status")
+
+				var result api.WorkflowExecutionResult
+				err = json.Unmarshal(body, &result)
+				require.NoError(t, err)
+				require.Len(t, result.Steps, 2)
+				assert.Equal(t, map[string]interface{}{"name": "John Doe"}, *result.Steps[1].Output)
+			},
+		},
+
+		"invalid_include_section": {
+			workflowID:  "550e8400-e29b-41d4-a716-446655440000",
+			query:       "include=steps,everything",
+			requestBody: api.WorkflowExecutionInput{},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for invalid query params
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid include section 'everything'", response.Error)
+			},
+		},
+
 		"invalid_request_body": {
 			workflowID:  "550e8400-e29b-41d4-a716-446655440000",
 			requestBody: "invalid json",
@@ -632,6 +837,58 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 			},
 		},
 
+		"invalid_label_key": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			requestBody: api.WorkflowExecutionInput{
+				Labels: &map[string]string{"env:prod": "true"},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for invalid labels
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid label key 'env:prod': keys must be non-empty and must not contain ':'", response.Error)
+			},
+		},
+
+		"invalid_execution_timeout": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			requestBody: api.WorkflowExecutionInput{
+				ExecutionOptions: &api.ExecutionOptions{TimeoutMs: intPtr(0)},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for invalid execution options
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "executionOptions.timeoutMs must be between 1 and 300000", response.Error)
+			},
+		},
+
+		"invalid_condition_input": {
+			workflowID:  "550e8400-e29b-41d4-a716-446655440000",
+			requestBody: map[string]any{"condition": map[string]any{"operator": "between"}},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for an unrunnable condition
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ValidationError
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, []api.FieldError{
+					{Field: "condition.operator", Message: "operator must be one of greater_than, less_than, equals, not_equals, greater_than_or_equal, less_than_or_equal or truthy"},
+					{Field: "condition.threshold", Message: "threshold is required unless operator is truthy"},
+				}, response.Fields)
+			},
+		},
+
 		"workflow_not_found_during_execution": {
 			workflowID: "non-existent-id",
 			requestBody: api.WorkflowExecutionInput{
@@ -648,7 +905,7 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 
 				mockDB.EXPECT().
 					GetWorkflowByID(gomock.Any(), "non-existent-id").
-					Return(nil, fmt.Errorf("workflow not found: non-existent-id"))
+					Return(nil, fmt.Errorf("%w: non-existent-id", db.ErrWorkflowNotFound))
 			},
 			expectedStatus: http.StatusNotFound,
 			checkResponse: func(t *testing.T, body []byte) {
@@ -659,6 +916,50 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 			},
 		},
 
+		"concurrency_limit_reached": {
+			workflowID:  "550e8400-e29b-41d4-a716-446655440000",
+			requestBody: api.WorkflowExecutionInput{},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// Mock cache miss so it goes to database
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{
+					ID:   "550e8400-e29b-41d4-a716-446655440000",
+					Name: "Test Workflow",
+				}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{
+						ID:         "start",
+						WorkflowID: "550e8400-e29b-41d4-a716-446655440000",
+						NodeID:     "start",
+						Type:       "start",
+						Position:   []byte(`{"x":100,"y":100}`),
+						Data:       null.JSONFrom([]byte(`{"label":"Start"}`)),
+					},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
+					Return(workflow, nil)
+
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			runningExecutions: 2,
+			expectedStatus:    http.StatusTooManyRequests,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "workflow '550e8400-e29b-41d4-a716-446655440000' already has 2 executions running", response.Error)
+			},
+		},
+
 		"execution_error": {
 			workflowID: "550e8400-e29b-41d4-a716-446655440000",
 			requestBody: api.WorkflowExecutionInput{
@@ -685,20 +986,155 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 				assert.Equal(t, "Failed to execute workflow", response.Error)
 			},
 		},
-	}
 
-	// Run test cases
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			// Create mock controller
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+		"inline_source_skips_database": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			query:      "source=inline",
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id: openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")),
+					Nodes: &[]api.WorkflowNode{
+						{Id: "start", Type: api.WorkflowNodeTypeStart},
+						{Id: "form", Type: api.WorkflowNodeTypeForm},
+						{Id: "end", Type: api.WorkflowNodeTypeEnd},
+					},
+					Edges: &[]api.WorkflowEdge{
+						{Id: "e1", Source: "start", Target: "form"},
+						{Id: "e2", Source: "form", Target: "end"},
+					},
+				},
+				Input: &api.WorkflowExecutionInput{
+					FormData: &map[string]interface{}{"name": "John Doe"},
+				},
+			},
+			// No mock expectations: the definition is never loaded, cached or persisted
+			setupMock:      func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, response.Status)
+				assert.Nil(t, response.ExecutionId)
+				require.Len(t, response.Steps, 3)
+				assert.Equal(t, "John Doe", (*response.Steps[1].Output)["name"])
+			},
+		},
 
-			// Create mocks
-			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
-			mockCache := cachemocks.NewMockCache(ctrl)
+		"inline_source_validates_definition": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			query:      "source=inline",
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Nodes: &[]api.WorkflowNode{
+						{Id: "start", Type: api.WorkflowNodeTypeStart},
+						{Id: "condition", Type: api.WorkflowNodeTypeCondition, Data: &api.NodeData{
+							Metadata: &map[string]any{"hasHandles": map[string]any{"source": []any{"true", "false"}}},
+						}},
+					},
+					Edges: &[]api.WorkflowEdge{
+						{Id: "e1", Source: "start", Target: "condition"},
+						{Id: "e2", Source: "condition", Target: "start", SourceHandle: strPtr("maybe")},
+					},
+				},
+			},
+			setupMock:      func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "edge 'e2' uses sourceHandle 'maybe'")
+			},
+		},
 
-			// Setup expectations
+		"invalid_source": {
+			workflowID:     "550e8400-e29b-41d4-a716-446655440000",
+			query:          "source=file",
+			requestBody:    api.WorkflowExecutionInput{},
+			setupMock:      func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid source 'file'", response.Error)
+			},
+		},
+
+		"invalid_request_timeout": {
+			workflowID:     "550e8400-e29b-41d4-a716-446655440000",
+			headers:        map[string]string{RequestTimeoutHeader: "soon"},
+			requestBody:    api.WorkflowExecutionInput{},
+			setupMock:      func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "X-Request-Timeout must be a positive integer of milliseconds", response.Error)
+			},
+		},
+
+		"request_timeout_exceeded": {
+			workflowID: "550e8400-e29b-41d4-a716-446655440000",
+			headers:    map[string]string{RequestTimeoutHeader: "1"},
+			requestBody: api.WorkflowExecutionInput{
+				FormData: &map[string]interface{}{"name": "John Doe"},
+			},
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				cacheKey := "workflow:550e8400-e29b-41d4-a716-446655440000"
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{
+					ID:   "550e8400-e29b-41d4-a716-446655440000",
+					Name: "Test Workflow",
+				}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{NodeID: "node-form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "edge-1", Source: "start", Target: "node-form"},
+				}
+
+				// A slow database load uses up the request's deadline before any node runs
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), "550e8400-e29b-41d4-a716-446655440000").
+					DoAndReturn(func(ctx context.Context, _ string) (*models.Workflow, error) {
+						<-ctx.Done()
+						return workflow, nil
+					})
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil).
+					AnyTimes()
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Workflow execution timed out", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
 			tc.setupMock(mockDB, mockCache)
 
 			// Create service with mock
@@ -706,6 +1142,14 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 				db:    mockDB,
 				cache: mockCache,
 			}
+			if tc.runningExecutions > 0 {
+				service.concurrency = newExecutionLimiter(tc.runningExecutions)
+				for range tc.runningExecutions {
+					release, err := service.concurrency.acquire(tc.workflowID)
+					require.NoError(t, err)
+					defer release()
+				}
+			}
 
 			// Prepare request body
 			var reqBody []byte
@@ -718,9 +1162,16 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 			}
 
 			// Create test request
-			req, err := http.NewRequest("POST", fmt.Sprintf("/workflows/%s/execute", tc.workflowID), bytes.NewBuffer(reqBody))
+			url := fmt.Sprintf("/workflows/%s/execute", tc.workflowID)
+			if tc.query != "" {
+				url += "?" + tc.query
+			}
+			req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
 			require.NoError(t, err)
 			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
 
 			// Add route variables
 			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID})
@@ -745,3 +1196,1718 @@ func TestHandleExecuteWorkflow(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleExecuteWorkflowDefinition(t *testing.T) {
+	workflow := api.Workflow{
+		Id:   openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")),
+		Name: strPtr("Draft Workflow"),
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			{Id: "form", Type: api.WorkflowNodeTypeForm},
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{
+			{Id: "e1", Source: "start", Target: "form"},
+			{Id: "e2", Source: "form", Target: "end"},
+		},
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		requestBody interface{}
+		limits      WorkflowLimits
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"executes_draft": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: workflow,
+				Input: &api.WorkflowExecutionInput{
+					FormData: &map[string]interface{}{"name": "John Doe"},
+					Labels:   &map[string]string{"source": "editor"},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, response.Status)
+				assert.Nil(t, response.ExecutionId)
+				assert.Equal(t, &map[string]string{"source": "editor"}, response.Labels)
+				require.Len(t, response.Steps, 3)
+				assert.Equal(t, "form", response.Steps[1].NodeId)
+			},
+		},
+		"executes_draft_without_input": {
+			requestBody:    api.WorkflowDefinitionExecutionInput{Workflow: workflow},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Len(t, response.Steps, 3)
+			},
+		},
+		"workflow_too_large": {
+			requestBody:    api.WorkflowDefinitionExecutionInput{Workflow: workflow},
+			limits:         WorkflowLimits{MaxNodes: 2},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "workflow exceeds size limits")
+			},
+		},
+		"unproduced_variable": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id: workflow.Id,
+					Nodes: &[]api.WorkflowNode{
+						{Id: "start", Type: api.WorkflowNodeTypeStart},
+						{Id: "form", Type: api.WorkflowNodeTypeForm, Data: &api.NodeData{
+							Metadata: &map[string]any{"outputVariables": []any{"name"}},
+						}},
+						{Id: "email", Type: api.WorkflowNodeTypeEmail, Data: &api.NodeData{
+							Metadata: &map[string]any{"inputVariables": []any{"name", "temperature"}},
+						}},
+					},
+					Edges: &[]api.WorkflowEdge{
+						{Id: "e1", Source: "start", Target: "form"},
+						{Id: "e2", Source: "form", Target: "email"},
+					},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "email node 'email' references {{temperature}} which no upstream node produces", response.Error)
+			},
+		},
+		"default_truthy_operator_without_threshold": {
+			requestBody: map[string]any{
+				"workflow": api.Workflow{
+					Id: workflow.Id,
+					Nodes: &[]api.WorkflowNode{
+						{Id: "start", Type: api.WorkflowNodeTypeStart},
+						{Id: "check", Type: api.WorkflowNodeTypeCondition, Data: &api.NodeData{
+							Metadata: &map[string]any{"operator": "truthy", "field": "approved"},
+						}},
+					},
+					Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "check"}},
+				},
+				"input": map[string]any{"formData": map[string]any{"approved": true}, "condition": map[string]any{}},
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, response.Status)
+			},
+		},
+		"default_operator_without_threshold": {
+			requestBody: map[string]any{
+				"workflow": api.Workflow{
+					Id: workflow.Id,
+					Nodes: &[]api.WorkflowNode{
+						{Id: "start", Type: api.WorkflowNodeTypeStart},
+						{Id: "check", Type: api.WorkflowNodeTypeCondition, Data: &api.NodeData{
+							Metadata: &map[string]any{"operator": "greater_than"},
+						}},
+					},
+					Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "check"}},
+				},
+				"input": map[string]any{"condition": map[string]any{}},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ValidationError
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, []api.FieldError{{
+					Field:   "condition.threshold",
+					Message: "threshold is required unless operator is truthy; condition node 'check' uses greater_than",
+				}}, response.Fields)
+			},
+		},
+		"missing_required_form_field": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id:    workflow.Id,
+					Nodes: &[]api.WorkflowNode{{Id: "start", Type: api.WorkflowNodeTypeStart}, requiredWhenFormNode([]any{map[string]any{"field": "email", "when": "notify", "equals": true}})},
+					Edges: &[]api.WorkflowEdge{{Id: "e1", Source: "start", Target: "form"}},
+				},
+				Input: &api.WorkflowExecutionInput{FormData: &map[string]any{"notify": true}},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ValidationError
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid execution input: formData.email: required when 'notify' is true", response.Error)
+				assert.Equal(t, []api.FieldError{{Field: "formData.email", Message: "required when 'notify' is true"}}, response.Fields)
+			},
+		},
+		"executes_in_execution_order": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id:             workflow.Id,
+					Nodes:          workflow.Nodes,
+					Edges:          workflow.Edges,
+					ExecutionOrder: &[]string{"start", "form"},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				require.Len(t, response.Steps, 2)
+				assert.Equal(t, "form", response.Steps[1].NodeId)
+			},
+		},
+		"invalid_execution_order": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id:             workflow.Id,
+					Nodes:          workflow.Nodes,
+					Edges:          workflow.Edges,
+					ExecutionOrder: &[]string{"start", "end"},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid executionOrder: no edge from 'start' to 'end'", response.Error)
+			},
+		},
+		"unconnected_nodes": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: api.Workflow{
+					Id:    workflow.Id,
+					Nodes: workflow.Nodes,
+					Edges: &[]api.WorkflowEdge{},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "but no edges connecting them")
+			},
+		},
+		"invalid_execution_timeout": {
+			requestBody: api.WorkflowDefinitionExecutionInput{
+				Workflow: workflow,
+				Input: &api.WorkflowExecutionInput{
+					ExecutionOptions: &api.ExecutionOptions{TimeoutMs: intPtr(0)},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "executionOptions.timeoutMs must be between 1 and 300000", response.Error)
+			},
+		},
+		"invalid_json": {
+			requestBody:    "{invalid json}",
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid request body", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// No mock expectations: a draft is never loaded, cached or persisted
+			service := &Service{
+				db:         dbmocks.NewMockWorkFlowDB(ctrl),
+				executions: dbmocks.NewMockExecutionDB(ctrl),
+				cache:      cachemocks.NewMockCache(ctrl),
+				limits:     tc.limits,
+			}
+
+			// Prepare request body
+			var reqBody []byte
+			var err error
+			if str, ok := tc.requestBody.(string); ok {
+				reqBody = []byte(str)
+			} else {
+				reqBody, err = json.Marshal(tc.requestBody)
+				require.NoError(t, err)
+			}
+
+			// Create test request
+			req, err := http.NewRequest("POST", "/workflows/execute", bytes.NewBuffer(reqBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			// Call the handler
+			rr := httptest.NewRecorder()
+			service.HandleExecuteWorkflowDefinition(rr, req)
+
+			// Check response
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleListExecutions(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	executionID := "9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"
+	executedAt := time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC)
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID string
+		query      string
+
+		// Mock setup
+		setupMock func(mockExecutions *dbmocks.MockExecutionDB)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"lists_executions": {
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					ListExecutions(gomock.Any(), workflowID, db.ExecutionFilter{Limit: DefaultExecutionPageSize}).
+					Return([]db.Execution{
+						{ID: executionID, WorkflowID: workflowID, Status: "completed", Labels: []byte(`{"trigger":"cron"}`), ExecutedAt: executedAt},
+					}, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionList
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, 1, response.Total)
+				require.Len(t, response.Executions, 1)
+				assert.Equal(t, executionID, response.Executions[0].ExecutionId.String())
+				assert.Equal(t, "completed", response.Executions[0].Status)
+				require.NotNil(t, response.Executions[0].Labels)
+				assert.Equal(t, map[string]string{"trigger": "cron"}, *response.Executions[0].Labels)
+			},
+		},
+
+		"filters_by_label_and_paginates": {
+			query: "label=trigger:cron&label=env:prod&limit=10&offset=5",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					ListExecutions(gomock.Any(), workflowID, db.ExecutionFilter{
+						Labels: map[string]string{"trigger": "cron", "env": "prod"},
+						Limit:  10,
+						Offset: 5,
+					}).
+					Return([]db.Execution{}, 5, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionList
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, 5, response.Total)
+				assert.Empty(t, response.Executions)
+			},
+		},
+
+		"invalid_label_filter": {
+			query: "label=trigger",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid query params
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid label filter 'trigger', expected key:value", response.Error)
+			},
+		},
+
+		"invalid_workflow_id": {
+			workflowID: "not-a-uuid",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for an invalid ID
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+
+		"database_error": {
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					ListExecutions(gomock.Any(), workflowID, gomock.Any()).
+					Return(nil, 0, errors.New("database connection lost"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Failed to list executions", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		if tc.workflowID == "" {
+			tc.workflowID = workflowID
+		}
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockExecutions)
+
+			// Create service with mock
+			service := &Service{
+				executions: mockExecutions,
+			}
+
+			// Create test request
+			url := fmt.Sprintf("/workflows/%s/executions", tc.workflowID)
+			if tc.query != "" {
+				url += "?" + tc.query
+			}
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleListExecutions(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleGetExecution(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	executionID := "9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID     string
+		executionID    string
+		query          string
+		redactInputPII bool
+
+		// Mock setup
+		setupMock func(mockExecutions *dbmocks.MockExecutionDB)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"successful_retrieval": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:         executionID,
+						WorkflowID: workflowID,
+						Status:     "completed",
+						Input:      []byte(`{}`),
+						Result:     []byte(`{"executedAt":"2024-01-15T14:30:24Z","executionId":"` + executionID + `","status":"completed","steps":[]}`),
+					}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionSteps(gomock.Any(), executionID, db.StepFilter{}).
+					Return([]db.ExecutionStep{
+						{Position: 0, NodeID: "start", NodeType: "start", Status: "completed", Output: []byte(`{"message":"Workflow started"}`)},
+					}, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, response.Status)
+				require.NotNil(t, response.ExecutionId)
+				assert.Equal(t, executionID, response.ExecutionId.String())
+				require.Len(t, response.Steps, 1)
+				assert.Equal(t, "Workflow started", (*response.Steps[0].Output)["message"])
+				require.NotNil(t, response.TotalSteps)
+				assert.Equal(t, 1, *response.TotalSteps)
+			},
+		},
+
+		"returns_redacted_input": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:     executionID,
+						Input:  []byte(`{"formData":{"city":"Sydney","email":"will@example.com","apiToken":"abc123"},"labels":{"trigger":"cron"}}`),
+						Result: []byte(`{"executedAt":"2024-01-15T14:30:24Z","status":"completed","steps":[]}`),
+					}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionSteps(gomock.Any(), executionID, db.StepFilter{}).
+					Return([]db.ExecutionStep{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				require.NotNil(t, response.Input)
+				assert.Equal(t, &map[string]any{"city": "Sydney", "email": "will@example.com", "apiToken": "***"}, response.Input.FormData)
+				assert.Equal(t, &map[string]string{"trigger": "cron"}, response.Input.Labels)
+			},
+		},
+
+		"redacts_input_pii_when_enabled": {
+			executionID:    executionID,
+			query:          "include=summary",
+			redactInputPII: true,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:     executionID,
+						Input:  []byte(`{"formData":{"city":"Sydney","email":"will@example.com"}}`),
+						Result: []byte(`{"executedAt":"2024-01-15T14:30:24Z","status":"completed","steps":[]}`),
+					}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionSteps(gomock.Any(), executionID, db.StepFilter{}).
+					Return([]db.ExecutionStep{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response map[string]any
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, map[string]any{"formData": map[string]any{"city": "Sydney", "email": "***"}}, response["input"])
+			},
+		},
+
+		"filters_and_paginates_steps": {
+			executionID: executionID,
+			query:       "status=failed&nodeType=integration&limit=10&offset=5",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:     executionID,
+						Result: []byte(`{"executedAt":"2024-01-15T14:30:24Z","status":"failed","steps":[]}`),
+					}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionSteps(gomock.Any(), executionID, db.StepFilter{Status: "failed", NodeType: "integration", Limit: 10, Offset: 5}).
+					Return([]db.ExecutionStep{
+						{Position: 7, NodeID: "weather-api", NodeType: "integration", Status: "failed", Error: null.StringFrom("timeout")},
+					}, 6, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				require.Len(t, response.Steps, 1)
+				assert.Equal(t, "weather-api", response.Steps[0].NodeId)
+				require.NotNil(t, response.Steps[0].Error)
+				assert.Equal(t, "timeout", *response.Steps[0].Error)
+				require.NotNil(t, response.TotalSteps)
+				assert.Equal(t, 6, *response.TotalSteps)
+			},
+		},
+
+		"invalid_status_filter": {
+			executionID: executionID,
+			query:       "status=broken",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid query params
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "invalid status 'broken'", response.Error)
+			},
+		},
+
+		"invalid_limit": {
+			executionID: executionID,
+			query:       "limit=1000",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid query params
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "limit must be between 1 and 500", response.Error)
+			},
+		},
+
+		"include_summary_only": {
+			executionID: executionID,
+			query:       "include=summary",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:     executionID,
+						Result: []byte(`{"executedAt":"2024-01-15T14:30:24Z","status":"completed","result":{"temperature":28.5},"steps":[]}`),
+					}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionSteps(gomock.Any(), executionID, db.StepFilter{}).
+					Return([]db.ExecutionStep{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response map[string]any
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "completed", response["status"])
+				assert.Equal(t, map[string]any{"temperature": 28.5}, response["result"])
+				assert.NotContains(t, response, "steps")
+			},
+		},
+
+		"execution_not_found": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrExecutionNotFound, executionID))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Execution not found", response.Error)
+			},
+		},
+
+		"invalid_execution_id": {
+			executionID: "not-a-uuid",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid execution ID
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid execution ID", response.Error)
+			},
+		},
+
+		"database_error": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(nil, errors.New("database connection lost"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Failed to retrieve execution", response.Error)
+			},
+		},
+
+		"invalid_workflow_id": {
+			workflowID:  "not-a-uuid",
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid workflow ID
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		if tc.workflowID == "" {
+			tc.workflowID = workflowID
+		}
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockExecutions)
+
+			// Create service with mock
+			service := &Service{
+				executions:     mockExecutions,
+				redactInputPII: tc.redactInputPII,
+			}
+
+			// Create test request
+			url := fmt.Sprintf("/workflows/%s/executions/%s", tc.workflowID, tc.executionID)
+			if tc.query != "" {
+				url += "?" + tc.query
+			}
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID, "execId": tc.executionID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleGetExecution(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleGetExecutionLogs(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	executionID := "9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"
+	loggedAt := time.Date(2024, 1, 15, 14, 30, 24, 0, time.UTC)
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID  string
+		executionID string
+
+		// Mock setup
+		setupMock func(mockExecutions *dbmocks.MockExecutionDB)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"successful_retrieval": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{ID: executionID, WorkflowID: workflowID}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionLogs(gomock.Any(), executionID).
+					Return([]db.ExecutionLog{
+						{Position: 0, Level: "DEBUG", Message: "Evaluating condition value", Attributes: []byte(`{"key":"temperature"}`), LoggedAt: loggedAt},
+						{Position: 1, Level: "ERROR", NodeID: null.StringFrom("weather-api"), Message: "Failed to call API", Attributes: []byte(`{}`), LoggedAt: loggedAt},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionLogList
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, executionID, response.ExecutionId.String())
+				require.Len(t, response.Logs, 2)
+
+				assert.Equal(t, api.DEBUG, response.Logs[0].Level)
+				assert.Nil(t, response.Logs[0].NodeId)
+				require.NotNil(t, response.Logs[0].Attributes)
+				assert.Equal(t, "temperature", (*response.Logs[0].Attributes)["key"])
+
+				assert.Equal(t, api.ERROR, response.Logs[1].Level)
+				require.NotNil(t, response.Logs[1].NodeId)
+				assert.Equal(t, "weather-api", *response.Logs[1].NodeId)
+				assert.Equal(t, "Failed to call API", response.Logs[1].Message)
+				assert.Nil(t, response.Logs[1].Attributes)
+				assert.True(t, loggedAt.Equal(response.Logs[1].Timestamp))
+			},
+		},
+
+		"execution_not_found": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrExecutionNotFound, executionID))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Execution not found", response.Error)
+			},
+		},
+
+		"database_error": {
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{ID: executionID, WorkflowID: workflowID}, nil)
+				mockExecutions.EXPECT().
+					ListExecutionLogs(gomock.Any(), executionID).
+					Return(nil, errors.New("database connection lost"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Failed to retrieve execution logs", response.Error)
+			},
+		},
+
+		"invalid_execution_id": {
+			executionID: "not-a-uuid",
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid IDs
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid execution ID", response.Error)
+			},
+		},
+
+		"invalid_workflow_id": {
+			workflowID:  "not-a-uuid",
+			executionID: executionID,
+			setupMock: func(mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid IDs
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		if tc.workflowID == "" {
+			tc.workflowID = workflowID
+		}
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockExecutions)
+
+			// Create service with mock
+			service := &Service{
+				executions: mockExecutions,
+			}
+
+			// Create test request
+			url := fmt.Sprintf("/workflows/%s/executions/%s/logs", tc.workflowID, tc.executionID)
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID, "execId": tc.executionID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleGetExecutionLogs(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleReplayExecution(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	executionID := "9b2f1c3e-8a4d-4f7b-9c6e-2d1a5b7e8f90"
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		workflowID  string
+		executionID string
+
+		// Mock setup
+		setupMock func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"replays_original_input": {
+			executionID: executionID,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:         executionID,
+						WorkflowID: workflowID,
+						Status:     "failed",
+						Input:      []byte(`{"formData":{"name":"John Doe"}}`),
+					}, nil)
+
+				cacheKey := "workflow:" + workflowID
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				workflow := &models.Workflow{ID: workflowID, Name: "Test Workflow"}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{NodeID: "node-form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "edge-1", Source: "start", Target: "node-form"},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(workflow, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+
+				mockExecutions.EXPECT().
+					CreateExecution(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, execution *db.Execution) error {
+						assert.Equal(t, workflowID, execution.WorkflowID)
+						assert.Equal(t, null.StringFrom(executionID), execution.ReplayOf)
+						assert.JSONEq(t, `{"formData":{"name":"John Doe"}}`, string(execution.Input))
+						assert.Contains(t, string(execution.Result), `"steps":[]`)
+						require.Len(t, execution.Steps, 2)
+						assert.Equal(t, "node-form", execution.Steps[1].NodeID)
+						assert.Equal(t, 1, execution.Steps[1].Position)
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowExecutionResult
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.WorkflowExecutionResultStatusCompleted, response.Status)
+				require.NotNil(t, response.ReplayOf)
+				assert.Equal(t, executionID, response.ReplayOf.String())
+				require.NotNil(t, response.ExecutionId)
+				assert.NotEqual(t, executionID, response.ExecutionId.String())
+				require.Len(t, response.Steps, 2)
+				assert.Equal(t, "John Doe", (*response.Steps[1].Output)["name"])
+			},
+		},
+
+		"execution_not_found": {
+			executionID: executionID,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrExecutionNotFound, executionID))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Execution not found", response.Error)
+			},
+		},
+
+		"invalid_execution_id": {
+			executionID: "not-a-uuid",
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid execution ID
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid execution ID", response.Error)
+			},
+		},
+
+		"invalid_workflow_id": {
+			workflowID:  "not-a-uuid",
+			executionID: executionID,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB) {
+				// No DB call expected for invalid workflow ID
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid workflow ID", response.Error)
+			},
+		},
+
+		"workflow_no_longer_validates": {
+			executionID: executionID,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache, mockExecutions *dbmocks.MockExecutionDB) {
+				mockExecutions.EXPECT().
+					GetExecutionByID(gomock.Any(), workflowID, executionID).
+					Return(&db.Execution{
+						ID:         executionID,
+						WorkflowID: workflowID,
+						Status:     "completed",
+						Input:      []byte(`{}`),
+					}, nil)
+
+				cacheKey := "workflow:" + workflowID
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+
+				// The definition was edited since the run: an edge now uses an undeclared handle
+				workflow := &models.Workflow{ID: workflowID, Name: "Test Workflow"}
+				workflow.R = workflow.R.NewStruct()
+				workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+					&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+					&models.WorkflowNode{
+						NodeID:   "condition",
+						Type:     "condition",
+						Position: []byte(`{"x":100,"y":0}`),
+						Data:     null.JSONFrom([]byte(`{"metadata":{"hasHandles":{"source":["true","false"]}}}`)),
+					},
+				}
+				workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+					&models.WorkflowEdge{EdgeID: "e1", Source: "condition", Target: "start", SourceHandle: null.StringFrom("tru")},
+				}
+
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(workflow, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "edge 'e1' uses sourceHandle 'tru'")
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		if tc.workflowID == "" {
+			tc.workflowID = workflowID
+		}
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+			mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockDB, mockCache, mockExecutions)
+
+			// Create service with mock
+			service := &Service{
+				db:         mockDB,
+				cache:      mockCache,
+				executions: mockExecutions,
+			}
+
+			// Create test request
+			req, err := http.NewRequest("POST", fmt.Sprintf("/workflows/%s/executions/%s/replay", tc.workflowID, tc.executionID), nil)
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": tc.workflowID, "execId": tc.executionID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleReplayExecution(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandlePatchNode(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	cacheKey := "workflow:" + workflowID
+	updatedAt := null.TimeFrom(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	// buildWorkflow returns a stored workflow with a condition node routed by handle
+	buildWorkflow := func() *models.Workflow {
+		workflow := &models.Workflow{ID: workflowID, Name: "Test Workflow"}
+		workflow.R = workflow.R.NewStruct()
+		workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+			&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+			&models.WorkflowNode{
+				NodeID:    "condition",
+				Type:      "condition",
+				Position:  []byte(`{"x":100,"y":0}`),
+				UpdatedAt: updatedAt,
+				Data: null.JSONFrom([]byte(`{
+					"label": "Check Condition",
+					"metadata": {
+						"hasHandles": {"source": ["true", "false"], "target": true},
+						"epsilon": 0.01,
+						"outputVariables": ["conditionMet"]
+					}
+				}`)),
+			},
+			&models.WorkflowNode{NodeID: "end", Type: "end", Position: []byte(`{"x":200,"y":0}`)},
+		}
+		workflow.R.WorkflowEdges = models.WorkflowEdgeSlice{
+			&models.WorkflowEdge{EdgeID: "e1", Source: "start", Target: "condition"},
+			&models.WorkflowEdge{EdgeID: "e2", Source: "condition", Target: "end", SourceHandle: null.StringFrom("true")},
+		}
+		return workflow
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		nodeID string
+		body   string
+
+		// Mock setup
+		setupMock func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"deep_merges_metadata": {
+			nodeID: "condition",
+			body:   `{"label": "Is it hot?", "metadata": {"epsilon": 0.5, "outputVariables": null}}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+				mockDB.EXPECT().
+					UpdateNodeData(gomock.Any(), workflowID, "condition", gomock.Any(), updatedAt, nil).
+					DoAndReturn(func(_ any, _ string, _ string, data []byte, _ null.Time, _ *db.NodeVersion) error {
+						assert.JSONEq(t, `{
+							"label": "Is it hot?",
+							"metadata": {
+								"hasHandles": {"source": ["true", "false"], "target": true},
+								"epsilon": 0.5
+							}
+						}`, string(data))
+						return nil
+					})
+				mockCache.EXPECT().
+					Delete(gomock.Any(), cacheKey).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.WorkflowNode
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "condition", response.Id)
+				require.NotNil(t, response.Data)
+				assert.Equal(t, "Is it hot?", *response.Data.Label)
+				metadata := *response.Data.Metadata
+				assert.Equal(t, 0.5, metadata["epsilon"])
+				assert.NotContains(t, metadata, "outputVariables")
+				assert.Contains(t, metadata, "hasHandles")
+			},
+		},
+
+		"cache_delete_failure_still_succeeds": {
+			nodeID: "condition",
+			body:   `{"description": "Evaluate temperature"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+				mockDB.EXPECT().
+					UpdateNodeData(gomock.Any(), workflowID, "condition", gomock.Any(), updatedAt, nil).
+					Return(nil)
+				mockCache.EXPECT().
+					Delete(gomock.Any(), cacheKey).
+					Return(errors.New("redis unavailable"))
+			},
+			expectedStatus: http.StatusOK,
+		},
+
+		"concurrent_update_conflicts": {
+			nodeID: "condition",
+			body:   `{"label": "Is it hot?"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+				mockDB.EXPECT().
+					UpdateNodeData(gomock.Any(), workflowID, "condition", gomock.Any(), updatedAt, nil).
+					Return(db.ErrNodeConflict{NodeID: "condition"})
+			},
+			expectedStatus: http.StatusConflict,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Node was modified by another request, retry the patch", response.Error)
+			},
+		},
+
+		"patch_breaks_edge_handles": {
+			nodeID: "condition",
+			body:   `{"metadata": {"hasHandles": {"source": ["yes", "no"]}}}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "edge 'e2' uses sourceHandle 'true'")
+			},
+		},
+
+		"invalid_node_data": {
+			nodeID: "condition",
+			body:   `{"label": 42}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Contains(t, response.Error, "invalid data for node 'condition'")
+			},
+		},
+
+		"unknown_node_data_field": {
+			nodeID: "condition",
+			body:   `{"lable": "typo"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+
+		"invalid_request_body": {
+			nodeID: "condition",
+			body:   `not json`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for an invalid body
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid request body", response.Error)
+			},
+		},
+
+		"node_not_found": {
+			nodeID: "missing",
+			body:   `{"label": "Ghost"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Node not found", response.Error)
+			},
+		},
+
+		"workflow_not_found": {
+			nodeID: "condition",
+			body:   `{"label": "Ghost"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrWorkflowNotFound, workflowID))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Workflow not found", response.Error)
+			},
+		},
+
+		"database_error_on_update": {
+			nodeID: "condition",
+			body:   `{"label": "Is it hot?"}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(buildWorkflow(), nil)
+				mockDB.EXPECT().
+					UpdateNodeData(gomock.Any(), workflowID, "condition", gomock.Any(), updatedAt, nil).
+					Return(errors.New("failed to update node: connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Failed to update node", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockDB, mockCache)
+
+			// Create service with mock
+			service := &Service{
+				db:    mockDB,
+				cache: mockCache,
+			}
+
+			// Create test request
+			req, err := http.NewRequest("PATCH", fmt.Sprintf("/workflows/%s/nodes/%s", workflowID, tc.nodeID), bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": workflowID, "nodeId": tc.nodeID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandlePatchNode(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleExecuteNode(t *testing.T) {
+	workflowID := "550e8400-e29b-41d4-a716-446655440000"
+	cacheKey := "workflow:" + workflowID
+
+	// buildWorkflow returns a stored workflow with a form and an email node
+	buildWorkflow := func() *models.Workflow {
+		workflow := &models.Workflow{ID: workflowID, Name: "Test Workflow"}
+		workflow.R = workflow.R.NewStruct()
+		workflow.R.WorkflowNodes = models.WorkflowNodeSlice{
+			&models.WorkflowNode{NodeID: "start", Type: "start", Position: []byte(`{"x":0,"y":0}`)},
+			&models.WorkflowNode{NodeID: "form", Type: "form", Position: []byte(`{"x":100,"y":0}`)},
+			&models.WorkflowNode{
+				NodeID:   "email",
+				Type:     "email",
+				Position: []byte(`{"x":200,"y":0}`),
+				Data: null.JSONFrom([]byte(`{
+					"label": "Send Alert",
+					"metadata": {
+						"emailTemplate": {"subject": "Weather Alert", "body": "Alert for {{city}}"}
+					}
+				}`)),
+			},
+		}
+		return workflow
+	}
+
+	// expectWorkflowLoad sets up a cache miss followed by a database load
+	expectWorkflowLoad := func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+		mockCache.EXPECT().
+			Get(gomock.Any(), cacheKey, gomock.Any()).
+			Return(cache.ErrCacheMiss{Key: cacheKey})
+		mockDB.EXPECT().
+			GetWorkflowByID(gomock.Any(), workflowID).
+			Return(buildWorkflow(), nil)
+		mockCache.EXPECT().
+			Set(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+			Return(nil)
+	}
+
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		// Input
+		nodeID string
+		body   string
+
+		// Mock setup
+		setupMock func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache)
+
+		// Expected response
+		expectedStatus int
+		checkResponse  func(t *testing.T, body []byte)
+	}{
+		"dry_run_email": {
+			nodeID:         "email",
+			body:           `{"executeVars": {"email": "user@example.com", "city": "Sydney", "conditionMet": true}, "dryRun": true}`,
+			setupMock:      expectWorkflowLoad,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionStep
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "email", response.NodeId)
+				assert.Equal(t, api.ExecutionStepStatusCompleted, response.Status)
+
+				output := *response.Output
+				assert.Equal(t, "dry_run", output["deliveryStatus"])
+				draft := output["emailDraft"].(map[string]any)
+				assert.Equal(t, "Alert for Sydney", draft["body"])
+			},
+		},
+
+		"runs_form_node_with_supplied_variables": {
+			nodeID:         "form",
+			body:           `{"executeVars": {"city": "Sydney"}}`,
+			setupMock:      expectWorkflowLoad,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionStep
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.ExecutionStepStatusCompleted, response.Status)
+				assert.Equal(t, "Sydney", (*response.Output)["city"])
+			},
+		},
+
+		"empty_body": {
+			nodeID:         "form",
+			body:           ``,
+			setupMock:      expectWorkflowLoad,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionStep
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "form", response.NodeId)
+				assert.Equal(t, api.ExecutionStepStatusCompleted, response.Status)
+			},
+		},
+
+		"unmet_condition_skips_email": {
+			nodeID:         "email",
+			body:           `{"executeVars": {"email": "user@example.com", "conditionMet": false}, "dryRun": true}`,
+			setupMock:      expectWorkflowLoad,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.ExecutionStep
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, api.ExecutionStepStatusSkipped, response.Status)
+			},
+		},
+
+		"invalid_request_body": {
+			nodeID: "form",
+			body:   `not json`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				// No DB call expected for an invalid body
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Invalid request body", response.Error)
+			},
+		},
+
+		"node_not_found": {
+			nodeID:         "missing",
+			body:           `{}`,
+			setupMock:      expectWorkflowLoad,
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Node not found", response.Error)
+			},
+		},
+
+		"workflow_not_found": {
+			nodeID: "form",
+			body:   `{}`,
+			setupMock: func(mockDB *dbmocks.MockWorkFlowDB, mockCache *cachemocks.MockCache) {
+				mockCache.EXPECT().
+					Get(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrCacheMiss{Key: cacheKey})
+				mockDB.EXPECT().
+					GetWorkflowByID(gomock.Any(), workflowID).
+					Return(nil, fmt.Errorf("%w: %s", db.ErrWorkflowNotFound, workflowID))
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, body []byte) {
+				var response api.Error
+				err := json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Workflow not found", response.Error)
+			},
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create mock controller
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Create mocks
+			mockDB := dbmocks.NewMockWorkFlowDB(ctrl)
+			mockCache := cachemocks.NewMockCache(ctrl)
+
+			// Setup expectations
+			tc.setupMock(mockDB, mockCache)
+
+			// Create service with mock
+			service := &Service{
+				db:    mockDB,
+				cache: mockCache,
+			}
+
+			// Create test request
+			req, err := http.NewRequest("POST", fmt.Sprintf("/workflows/%s/nodes/%s/execute", workflowID, tc.nodeID), bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			// Add route variables
+			req = mux.SetURLVars(req, map[string]string{"id": workflowID, "nodeId": tc.nodeID})
+
+			// Create response recorder
+			rr := httptest.NewRecorder()
+
+			// Call the handler
+			service.HandleExecuteNode(rr, req)
+
+			// Check status code
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			// Check response body
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestExecuteWorkflowStoresCancelledExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockCache := cachemocks.NewMockCache(ctrl)
+	mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+
+	workflowID := openapi_types.UUID(uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"))
+	expectCachedWorkflow(mockCache, api.Workflow{
+		Id: workflowID,
+		Nodes: &[]api.WorkflowNode{
+			{Id: "start", Type: api.WorkflowNodeTypeStart},
+			{Id: "form", Type: api.WorkflowNodeTypeForm},
+			{Id: "end", Type: api.WorkflowNodeTypeEnd},
+		},
+		Edges: &[]api.WorkflowEdge{
+			{Id: "e1", Source: "start", Target: "form"},
+			{Id: "e2", Source: "form", Target: "end"},
+		},
+	})
+
+	// The client has gone, but the insert must still get a context it can use
+	mockExecutions.EXPECT().
+		CreateExecution(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, execution *db.Execution) error {
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, string(api.WorkflowExecutionResultStatusCancelled), execution.Status)
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	service := &Service{cache: mockCache, executions: mockExecutions}
+	result, err := service.ExecuteWorkflow(ctx, workflowID.String(), api.WorkflowExecutionInput{})
+
+	require.NoError(t, err)
+	assert.Equal(t, api.WorkflowExecutionResultStatusCancelled, result.Status)
+	assert.NotNil(t, result.ExecutionId)
+}
+
+func TestRecordExecutionRedactsInput(t *testing.T) {
+	// Define test cases using table-driven tests (map format)
+	tests := map[string]struct {
+		redactInputPII bool
+
+		expectedInput string
+	}{
+		"secrets_masked": {
+			expectedInput: `{"formData":{"city":"Sydney","email":"will@example.com","apiToken":"***"},"labels":{"trigger":"cron"}}`,
+		},
+		"pii_masked_when_enabled": {
+			redactInputPII: true,
+			expectedInput:  `{"formData":{"city":"Sydney","email":"***","apiToken":"***"},"labels":{"trigger":"cron"}}`,
+		},
+	}
+
+	// Run test cases
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+			mockExecutions.EXPECT().
+				CreateExecution(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, execution *db.Execution) error {
+					assert.JSONEq(t, tc.expectedInput, string(execution.Input))
+					return nil
+				})
+
+			service := &Service{executions: mockExecutions, redactInputPII: tc.redactInputPII}
+			formData := map[string]any{"city": "Sydney", "email": "will@example.com", "apiToken": "abc123"}
+			input := api.WorkflowExecutionInput{FormData: &formData, Labels: &map[string]string{"trigger": "cron"}}
+			result := &api.WorkflowExecutionResult{
+				ExecutedAt: time.Now(),
+				Status:     api.WorkflowExecutionResultStatusCompleted,
+				Steps:      []api.ExecutionStep{},
+			}
+
+			service.recordExecution(context.Background(), "550e8400-e29b-41d4-a716-446655440000", uuid.New(), input, result)
+
+			// The caller's copy is left as sent
+			assert.Equal(t, "abc123", formData["apiToken"])
+		})
+	}
+}
+
+func TestRecordExecutionOmitsExecutionIDOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExecutions := dbmocks.NewMockExecutionDB(ctrl)
+	mockExecutions.EXPECT().
+		CreateExecution(gomock.Any(), gomock.Any()).
+		Return(errors.New("connection refused"))
+
+	service := &Service{executions: mockExecutions}
+	executionID := uuid.New()
+	result := &api.WorkflowExecutionResult{
+		ExecutedAt: time.Now(),
+		Status:     api.WorkflowExecutionResultStatusCompleted,
+		Steps:      []api.ExecutionStep{},
+	}
+
+	service.recordExecution(context.Background(), "550e8400-e29b-41d4-a716-446655440000", executionID, api.WorkflowExecutionInput{}, result)
+
+	// An ID that was never stored would only lead to a 404 when fetched
+	assert.Nil(t, result.ExecutionId)
+}
@@ -42,12 +42,23 @@ export const ExecutionResultsComponent: React.FC<ExecutionResultsProps> = ({
       greater_than: '>',
       less_than: '<',
       equals: '=',
+      not_equals: '≠',
       greater_than_or_equal: '≥',
       less_than_or_equal: '≤',
     };
     return symbols[operator as keyof typeof symbols] || '>';
   };
 
+  // Integration, condition and email steps report their message under _nodeMessage
+  const getStepMessage = (output: unknown) => {
+    if (output && typeof output === 'object') {
+      const { _nodeMessage, message } = output as Record<string, unknown>;
+      if (typeof _nodeMessage === 'string') return _nodeMessage;
+      if (typeof message === 'string') return message;
+    }
+    return 'Step completed';
+  };
+
   const getStatusIcon = (status: string) => {
     return status === 'completed' ? (
       <CheckIcon style={{ color: 'var(--green-9)' }} />
@@ -181,12 +192,7 @@ export const ExecutionResultsComponent: React.FC<ExecutionResultsProps> = ({
                       </Flex>
 
                       <Text size="2" style={{ display: 'block', marginBottom: '8px' }}>
-                        {'output' in step &&
-                        step.output &&
-                        'message' in step.output &&
-                        typeof step.output.message === 'string'
-                          ? step.output.message
-                          : 'Step completed'}
+                        {getStepMessage('output' in step ? step.output : undefined)}
                       </Text>
 
                       {step.status === 'error' && 'error' in step && step.error && (
@@ -31,6 +31,7 @@ const workflowFormSchema = z.object({
     'greater_than',
     'less_than',
     'equals',
+    'not_equals',
     'greater_than_or_equal',
     'less_than_or_equal',
   ]),
@@ -72,7 +73,8 @@ export const UserInputForm: React.FC<UserInputFormProps> = ({ onExecute, isExecu
   const operatorLabels = {
     greater_than: 'is greater than',
     less_than: 'is less than',
-    equals: 'equals exactly',
+    equals: 'equals',
+    not_equals: 'does not equal',
     greater_than_or_equal: 'is at least',
     less_than_or_equal: 'is at most',
   };
@@ -82,6 +84,7 @@ export const UserInputForm: React.FC<UserInputFormProps> = ({ onExecute, isExecu
       greater_than: '>',
       less_than: '<',
       equals: '=',
+      not_equals: '≠',
       greater_than_or_equal: '≥',
       less_than_or_equal: '≤',
     } as const;
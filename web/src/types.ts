@@ -6,6 +6,7 @@ export interface WorkflowFormData {
     | 'greater_than'
     | 'less_than'
     | 'equals'
+    | 'not_equals'
     | 'greater_than_or_equal'
     | 'less_than_or_equal';
   threshold: number;